@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *ApiServerImpl) CancelPriceChanges(ctx context.Context, in *pb.CancelPriceChangesRequest) (*pb.Response, error) {
+	priceChangeIDs := in.GetPriceChangeIds()
+
+	response := &pb.Response{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	if len(priceChangeIDs) == 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "price_change_ids is empty"
+		response.Status = 2 // VALIDATION_ERROR
+
+		return response, nil
+	}
+
+	if err := a.s.CancelPriceChanges(ctx, priceChangeIDs); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		response.Status = 1 // FAILURE
+
+		return response, err
+	}
+
+	a.log.Info("price changes cancelled",
+		zap.Int64s("priceChangeIDs", priceChangeIDs),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 
+	"github.com/kripst/krosovka/inventory_service/internal/fx"
 	"github.com/kripst/krosovka/inventory_service/internal/storage"
 	pb "github.com/kripst/krosovka/inventory_service/proto"
 	"go.uber.org/zap"
@@ -19,4 +20,16 @@ type ApiServerImpl struct {
 	pb.UnimplementedInventoryServiceServer
 	s storage.Storage
 	log *zap.Logger
-}
\ No newline at end of file
+	// rates - курсы для display_currency, nil - пересчёт недоступен
+	rates *fx.Converter
+}
+
+var _ pb.InventoryServiceServer = (*ApiServerImpl)(nil)
+
+func NewApiServerImpl(s storage.Storage, log *zap.Logger, rates *fx.Converter) *ApiServerImpl {
+	return &ApiServerImpl{
+		s:     s,
+		log:   log,
+		rates: rates,
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *OrdersServerImpl) CreateOrder(ctx context.Context, in *pb.CreateOrderRequest) (*pb.OrderResponse, error) {
+	response := &pb.OrderResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	order := &model.Order{}
+	err := order.FromGrpc(in)
+	if err == nil {
+		err = order.Validate()
+	}
+	if err != nil {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = err.Error()
+		return response, nil
+	}
+
+	if err := a.s.CreateOrder(ctx, order); err != nil {
+		response.StatusCode = int32(orderErrorStatus(err))
+		response.ErrorMessage = err.Error()
+		if response.StatusCode != http.StatusInternalServerError {
+			a.log.Warn("order rejected", zap.String("customer_id", order.Customer.ID), zap.Error(err))
+			return response, nil
+		}
+		a.log.Error("ERROR: create order", zap.Error(err))
+		return response, err
+	}
+
+	response.Order = order.ToGrpc()
+
+	a.log.Info("order created",
+		zap.Int64("order_id", order.ID),
+		zap.String("customer_id", order.Customer.ID),
+		zap.Stringer("total", order.TotalMoney()),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
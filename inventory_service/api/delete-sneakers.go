@@ -5,18 +5,19 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
 	pb "github.com/kripst/krosovka/inventory_service/proto"
 	"go.uber.org/zap"
 )
 
 
 func(a *ApiServerImpl) DeleteSneakers(ctx context.Context, in *pb.DeleteSneakersRequest) (*pb.Response, error) {
-	sneakerIDs := in.GetSneakerId()
+	sneakerIDs := in.GetSneakerIds()
 
 	response := &pb.Response{}
 	response.RequestId = in.GetRequestId()
 	response.StatusCode = http.StatusOK
-	response.SneakerIds = in.GetSneakerId()
+	response.SneakerIds = in.GetSneakerIds()
 	response.Timestamp = time.Now().String()
 
 	if err := a.s.DeleteSneakers(ctx, sneakerIDs); err != nil {
@@ -28,6 +29,10 @@ func(a *ApiServerImpl) DeleteSneakers(ctx context.Context, in *pb.DeleteSneakers
 		return response, err
 	}
 
-	a.log.Info("sneakers soft deleted", zap.Any("sneakerIDs", response.SneakerIds))
+	a.log.Info("sneakers soft deleted",
+		zap.Any("sneakerIDs", response.SneakerIds),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
 	return response, nil
 }
\ No newline at end of file
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/fx"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+// SetExchangeRates сохраняет курсы в БД и сразу применяет их на этой реплике,
+// остальные подхватят их при следующем обновлении.
+func (a *ApiServerImpl) SetExchangeRates(ctx context.Context, in *pb.SetExchangeRatesRequest) (*pb.Response, error) {
+	response := &pb.Response{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	if len(in.GetRates()) == 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "rates is empty"
+		response.Status = 2 // VALIDATION_ERROR
+		return response, nil
+	}
+
+	now := time.Now()
+	rates := make([]model.ExchangeRate, 0, len(in.GetRates()))
+	for i, pbRate := range in.GetRates() {
+		var rate model.ExchangeRate
+		err := rate.FromGrpc(pbRate)
+		if err == nil {
+			err = rate.Validate()
+		}
+		if err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = fmt.Sprintf("rates[%d]: %v", i, err)
+			response.Status = 2 // VALIDATION_ERROR
+			return response, nil
+		}
+		if rate.UpdatedAt.IsZero() {
+			rate.UpdatedAt = now
+		}
+		rate.Source = fx.SourceAdmin
+		rate.UpdatedBy = reqctx.Actor(ctx)
+		rates = append(rates, rate)
+	}
+
+	if err := a.s.SaveExchangeRates(ctx, rates); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		response.Status = 1 // FAILURE
+		return response, err
+	}
+	if a.rates != nil {
+		a.rates.Set(rates...)
+	}
+
+	a.log.Info("exchange rates updated",
+		zap.Int("count", len(rates)),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
+
+// GetExchangeRates возвращает курсы, которые сейчас использует эта реплика.
+func (a *ApiServerImpl) GetExchangeRates(ctx context.Context, in *pb.GetExchangeRatesRequest) (*pb.GetExchangeRatesResponse, error) {
+	response := &pb.GetExchangeRatesResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	if a.rates == nil {
+		return response, nil
+	}
+
+	rates := a.rates.Rates()
+	response.Rates = make([]*pb.ExchangeRate, 0, len(rates))
+	for i := range rates {
+		response.Rates = append(response.Rates, rates[i].ToGrpc())
+	}
+
+	return response, nil
+}
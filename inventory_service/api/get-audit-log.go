@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *ApiServerImpl) GetAuditLog(ctx context.Context, in *pb.GetAuditLogRequest) (*pb.GetAuditLogResponse, error) {
+	response := &pb.GetAuditLogResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	filter, err := auditFilterFromRequest(in)
+	if err != nil {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = err.Error()
+		return response, nil
+	}
+
+	pagination := model.Pagination{
+		Limit:  int(in.GetPartition()),
+		Offset: int(in.GetOffset()),
+	}
+	if pagination.Limit <= 0 {
+		pagination.Limit = defaultPageSize
+	}
+	pagination.Limit = min(pagination.Limit, maxPageSize)
+	pagination.Offset = max(pagination.Offset, 0)
+
+	entries, err := a.s.GetAuditLog(ctx, filter, pagination)
+	if err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		a.log.Error("ERROR: get audit log", zap.Error(err))
+		return response, err
+	}
+
+	response.Entries = make([]*pb.AuditEntry, 0, len(entries))
+	for i := range entries {
+		response.Entries = append(response.Entries, entries[i].ToGrpc())
+	}
+
+	return response, nil
+}
+
+// auditFilterFromRequest разбирает фильтры запроса, from/to - RFC 3339.
+func auditFilterFromRequest(in *pb.GetAuditLogRequest) (model.AuditFilter, error) {
+	filter := model.AuditFilter{
+		SneakerID: in.GetSneakerId(),
+		Article:   in.GetArticle(),
+		Actor:     in.GetActor(),
+	}
+
+	var err error
+	if in.GetFrom() != "" {
+		if filter.From, err = time.Parse(time.RFC3339, in.GetFrom()); err != nil {
+			return filter, fmt.Errorf("from: %w", err)
+		}
+	}
+	if in.GetTo() != "" {
+		if filter.To, err = time.Parse(time.RFC3339, in.GetTo()); err != nil {
+			return filter, fmt.Errorf("to: %w", err)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *OrdersServerImpl) GetOrder(ctx context.Context, in *pb.GetOrderRequest) (*pb.OrderResponse, error) {
+	response := &pb.OrderResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	if in.GetOrderId() <= 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "order_id is required"
+		return response, nil
+	}
+
+	order, err := a.s.GetOrder(ctx, in.GetOrderId())
+	if err != nil {
+		response.StatusCode = int32(orderErrorStatus(err))
+		response.ErrorMessage = err.Error()
+		if response.StatusCode != http.StatusInternalServerError {
+			return response, nil
+		}
+		a.log.Error("ERROR: get order", zap.Error(err))
+		return response, err
+	}
+
+	response.Order = order.ToGrpc()
+	return response, nil
+}
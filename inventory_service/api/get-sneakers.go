@@ -1 +1,179 @@
-package api
\ No newline at end of file
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/fx"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+func (a *ApiServerImpl) GetSneakers(ctx context.Context, in *pb.GetSneakersRequest) (*pb.GetSneakersResponse, error) {
+	response := &pb.GetSneakersResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	var displayCurrency money.Currency
+	if in.GetDisplayCurrency() != "" {
+		var err error
+		if displayCurrency, err = money.ParseCurrency(in.GetDisplayCurrency()); err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = "display_currency: " + err.Error()
+			return response, nil
+		}
+	}
+
+	// partition - размер страницы, offset - смещение от начала выборки
+	pagination := model.Pagination{
+		Limit:  int(in.GetPartition()),
+		Offset: int(in.GetOffset()),
+	}
+	if pagination.Limit <= 0 {
+		pagination.Limit = defaultPageSize
+	}
+	pagination.Limit = min(pagination.Limit, maxPageSize)
+	pagination.Offset = max(pagination.Offset, 0)
+
+	filter := model.SneakerFilters{
+		IDs:      in.GetSneakerId(),
+		Articles: in.GetArticles(),
+		Brand:    strings.TrimSpace(in.GetBrand()),
+		Name:     strings.TrimSpace(in.GetName()),
+	}
+	if in.GetUpdatedSince() != "" {
+		since, err := time.Parse(time.RFC3339Nano, in.GetUpdatedSince())
+		if err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = "updated_since: must be RFC 3339"
+			return response, nil
+		}
+		filter.UpdatedSince = &since
+	}
+	// Ключ страницы без отметки времени ничего не значит
+	if in.GetAfterId() < 0 || (in.GetAfterId() > 0 && filter.UpdatedSince == nil) {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "after_id: must be positive and used with updated_since"
+		return response, nil
+	}
+	filter.UpdatedAfterID = in.GetAfterId()
+	var err error
+	if filter.MinPrice, err = priceBound(in.GetMinPrice()); err != nil {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "min_price: " + err.Error()
+		return response, nil
+	}
+	if filter.MaxPrice, err = priceBound(in.GetMaxPrice()); err != nil {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "max_price: " + err.Error()
+		return response, nil
+	}
+	// Размер без системы неоднозначен: 9 - это и US, и UK
+	if in.GetSize() != 0 || in.GetSizeSystem() != "" {
+		system, err := sizing.ParseSystem(in.GetSizeSystem())
+		if err == nil && in.GetSize() <= 0 {
+			err = sizing.ErrUnknownSize
+		}
+		if err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = "size: " + err.Error()
+			return response, nil
+		}
+		size := sizing.New(system, float64(in.GetSize()))
+		filter.Size = &size
+	}
+
+	sneakers, err := a.s.GetSneakers(ctx, filter, pagination)
+	if err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		a.log.Error("ERROR: get sneakers", zap.Error(err))
+		return response, err
+	}
+
+	response.Sneakers = make([]*pb.Sneaker, 0, len(sneakers))
+	for i := range sneakers {
+		response.Sneakers = append(response.Sneakers, sneakers[i].ToGrpc())
+	}
+
+	if displayCurrency != "" {
+		if err := a.convertForDisplay(response, sneakers, displayCurrency); err != nil {
+			response.StatusCode = http.StatusUnprocessableEntity
+			response.ErrorMessage = err.Error()
+			response.Sneakers = nil
+			a.log.Warn("display currency conversion failed", zap.Error(err))
+			return response, nil
+		}
+	}
+	response.PageSize = int32(pagination.Limit)
+	response.Page = int32(pagination.Offset / pagination.Limit)
+
+	return response, nil
+}
+
+// priceBound - граница фильтра по цене, nil - без границы.
+func priceBound(in *pb.Money) (*money.Money, error) {
+	if in == nil {
+		return nil, nil
+	}
+	m, err := money.FromProto(in)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// convertForDisplay заполняет display_* цены и список использованных курсов.
+// Хранимые цены в ответе остаются как есть.
+func (a *ApiServerImpl) convertForDisplay(response *pb.GetSneakersResponse, sneakers []model.Sneaker, to money.Currency) error {
+	if a.rates == nil {
+		return fx.ErrNoRate
+	}
+
+	used := make(map[string]*pb.ExchangeRate)
+	convert := func(m money.Money) (*pb.Money, error) {
+		converted, rate, err := a.rates.Convert(m, to)
+		if err != nil {
+			return nil, err
+		}
+		if rate != nil {
+			used[string(rate.Base)+string(rate.Quote)] = rate.ToGrpc()
+		}
+		return converted.ToProto(), nil
+	}
+
+	for i := range sneakers {
+		price, err := convert(sneakers[i].BasePrice())
+		if err != nil {
+			return err
+		}
+		effective, err := convert(sneakers[i].EffectivePrice())
+		if err != nil {
+			return err
+		}
+		response.Sneakers[i].DisplayPrice = price
+		response.Sneakers[i].DisplayEffectivePrice = effective
+	}
+
+	keys := make([]string, 0, len(used))
+	for key := range used {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		response.DisplayRates = append(response.DisplayRates, used[key])
+	}
+
+	return nil
+}
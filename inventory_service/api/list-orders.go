@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *OrdersServerImpl) ListOrders(ctx context.Context, in *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	response := &pb.ListOrdersResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	filter := model.OrderFilter{CustomerID: strings.TrimSpace(in.GetCustomerId())}
+	if filter.CustomerID == "" {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "customer_id is required"
+		return response, nil
+	}
+	if in.GetStatus() != "" {
+		status, err := model.ParseOrderStatus(in.GetStatus())
+		if err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = err.Error()
+			return response, nil
+		}
+		filter.Status = status
+	}
+
+	pagination := model.Pagination{
+		Limit:  int(in.GetPartition()),
+		Offset: int(in.GetOffset()),
+	}
+	if pagination.Limit <= 0 {
+		pagination.Limit = defaultPageSize
+	}
+	pagination.Limit = min(pagination.Limit, maxPageSize)
+	pagination.Offset = max(pagination.Offset, 0)
+
+	orders, err := a.s.ListOrders(ctx, filter, pagination)
+	if err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		a.log.Error("ERROR: list orders", zap.Error(err))
+		return response, err
+	}
+
+	response.Orders = make([]*pb.Order, 0, len(orders))
+	for i := range orders {
+		response.Orders = append(response.Orders, orders[i].ToGrpc())
+	}
+
+	return response, nil
+}
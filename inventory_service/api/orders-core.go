@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+type OrdersServerImpl struct {
+	pb.UnimplementedOrdersServiceServer
+	s   storage.Storage
+	log *zap.Logger
+}
+
+var _ pb.OrdersServiceServer = (*OrdersServerImpl)(nil)
+
+func NewOrdersServerImpl(s storage.Storage, log *zap.Logger) *OrdersServerImpl {
+	return &OrdersServerImpl{
+		s:   s,
+		log: log,
+	}
+}
+
+// orderErrorStatus - HTTP-код ответа на ошибку хранилища заказов. Для 4xx
+// ошибка остаётся в ответе, а RPC завершается успешно.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrInvalidTransition), errors.Is(err, model.ErrOrderConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
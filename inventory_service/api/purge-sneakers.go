@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *ApiServerImpl) PurgeSneakers(ctx context.Context, in *pb.PurgeSneakersRequest) (*pb.Response, error) {
+	sneakerIDs := in.GetSneakerIds()
+
+	response := &pb.Response{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.SneakerIds = sneakerIDs
+	response.Timestamp = time.Now().String()
+
+	if len(sneakerIDs) == 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "sneaker_ids is empty"
+		response.Status = 2 // VALIDATION_ERROR
+		response.SneakerIds = nil
+
+		return response, nil
+	}
+
+	if err := a.s.PurgeSneakers(ctx, sneakerIDs); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		response.Status = 1 // FAILURE
+		response.SneakerIds = nil
+
+		return response, err
+	}
+
+	a.log.Info("sneakers purged",
+		zap.Any("sneakerIDs", response.SneakerIds),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *ApiServerImpl) RestoreSneakers(ctx context.Context, in *pb.RestoreSneakersRequest) (*pb.Response, error) {
+	sneakerIDs := in.GetSneakerIds()
+
+	response := &pb.Response{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	// Артикулы переводятся в ID среди мягко удалённых записей
+	if articles := in.GetArticles(); len(articles) > 0 {
+		ids, missing, err := a.deletedIDsByArticle(ctx, articles)
+		if err != nil {
+			response.StatusCode = http.StatusInternalServerError
+			response.ErrorMessage = err.Error()
+			response.Status = 1 // FAILURE
+
+			return response, err
+		}
+		if len(missing) > 0 {
+			response.StatusCode = http.StatusNotFound
+			response.ErrorMessage = "not found among deleted sneakers: " + strings.Join(missing, ", ")
+			response.Status = 2 // VALIDATION_ERROR
+
+			return response, nil
+		}
+		sneakerIDs = append(sneakerIDs, ids...)
+	}
+	response.SneakerIds = sneakerIDs
+
+	if len(sneakerIDs) == 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "sneaker_ids and articles are empty"
+		response.Status = 2 // VALIDATION_ERROR
+		response.SneakerIds = nil
+
+		return response, nil
+	}
+
+	if err := a.s.RestoreSneakers(ctx, sneakerIDs); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		response.Status = 1 // FAILURE
+		response.SneakerIds = nil
+
+		return response, err
+	}
+
+	a.log.Info("sneakers restored",
+		zap.Any("sneakerIDs", response.SneakerIds),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
+
+// deletedIDsByArticle находит ID мягко удалённых кроссовок и артикулы, которых среди них нет.
+func (a *ApiServerImpl) deletedIDsByArticle(ctx context.Context, articles []string) ([]int32, []string, error) {
+	sneakers, err := a.s.GetSneakers(ctx,
+		model.SneakerFilters{Articles: articles, OnlyDeleted: true},
+		model.Pagination{Limit: len(articles)},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find deleted sneakers: %w", err)
+	}
+
+	found := make(map[string]int32, len(sneakers))
+	for _, s := range sneakers {
+		found[s.Article] = s.ID
+	}
+
+	var ids []int32
+	var missing []string
+	for _, article := range articles {
+		if id, ok := found[article]; ok {
+			ids = append(ids, id)
+		} else {
+			missing = append(missing, article)
+		}
+	}
+	return ids, missing, nil
+}
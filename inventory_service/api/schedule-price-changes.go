@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *ApiServerImpl) SchedulePriceChanges(ctx context.Context, in *pb.SchedulePriceChangesRequest) (*pb.SchedulePriceChangesResponse, error) {
+	response := &pb.SchedulePriceChangesResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	if len(in.GetChanges()) == 0 {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = "changes is empty"
+		return response, nil
+	}
+
+	now := time.Now()
+	changes := make([]*model.PriceChange, 0, len(in.GetChanges()))
+	for i, pbChange := range in.GetChanges() {
+		change := &model.PriceChange{}
+		err := change.FromGrpc(pbChange)
+		if err == nil {
+			err = change.Validate(now)
+		}
+		if err != nil {
+			response.StatusCode = http.StatusBadRequest
+			response.ErrorMessage = fmt.Sprintf("changes[%d]: %v", i, err)
+			return response, nil
+		}
+		changes = append(changes, change)
+	}
+
+	if err := a.s.SchedulePriceChanges(ctx, changes); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		a.log.Error("ERROR: schedule price changes", zap.Error(err))
+		return response, err
+	}
+
+	response.Changes = make([]*pb.PriceChange, 0, len(changes))
+	for _, change := range changes {
+		response.Changes = append(response.Changes, change.ToGrpc())
+	}
+
+	a.log.Info("price changes scheduled",
+		zap.Int("count", len(changes)),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
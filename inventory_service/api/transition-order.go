@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+)
+
+func (a *OrdersServerImpl) TransitionOrder(ctx context.Context, in *pb.TransitionOrderRequest) (*pb.OrderResponse, error) {
+	response := &pb.OrderResponse{}
+	response.RequestId = in.GetRequestId()
+	response.StatusCode = http.StatusOK
+	response.Timestamp = time.Now().String()
+
+	change, err := statusChangeFromRequest(in)
+	if err != nil {
+		response.StatusCode = http.StatusBadRequest
+		response.ErrorMessage = err.Error()
+		return response, nil
+	}
+
+	order, err := a.s.TransitionOrder(ctx, change)
+	if err != nil {
+		response.StatusCode = int32(orderErrorStatus(err))
+		response.ErrorMessage = err.Error()
+		if response.StatusCode != http.StatusInternalServerError {
+			return response, nil
+		}
+		a.log.Error("ERROR: transition order", zap.Error(err))
+		return response, err
+	}
+
+	response.Order = order.ToGrpc()
+
+	a.log.Info("order status changed",
+		zap.Int64("order_id", order.ID),
+		zap.String("status", string(order.Status)),
+		zap.String("actor", reqctx.Actor(ctx)),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	)
+	return response, nil
+}
+
+func statusChangeFromRequest(in *pb.TransitionOrderRequest) (model.OrderStatusChange, error) {
+	change := model.OrderStatusChange{OrderID: in.GetOrderId(), Reason: in.GetReason()}
+	if change.OrderID <= 0 {
+		return change, fmt.Errorf("order_id is required")
+	}
+
+	var err error
+	if change.To, err = model.ParseOrderStatus(in.GetStatus()); err != nil {
+		return change, fmt.Errorf("status: %w", err)
+	}
+	if change.To == model.OrderCreated {
+		return change, fmt.Errorf("status: orders are created by CreateOrder")
+	}
+	if in.GetFromStatus() != "" {
+		if change.From, err = model.ParseOrderStatus(in.GetFromStatus()); err != nil {
+			return change, fmt.Errorf("from_status: %w", err)
+		}
+	}
+
+	return change, nil
+}
@@ -16,30 +16,33 @@ func (a *ApiServerImpl) UpdateSneakers(ctx context.Context, in *pb.UpdateSneaker
 	response.StatusCode = http.StatusOK
 	response.SneakerIds = make([]int32, 0, len(in.GetSneakers()))
 	response.Timestamp = time.Now().String()
-	
 
+	// Сначала проверяем все записи, чтобы не сохранить часть пачки
 	sneakers := make([]*model.Sneaker, 0, len(in.GetSneakers()))
-	defer func() {
-		if err := a.s.UpdateSneakers(ctx, sneakers); err != nil {
-			a.log.Error("ERROR: Update sneakers", zap.Error(err))
-		} else {
-			a.log.Info("successfully Updated sneakers", zap.Int("quantity sneakers Updated", len(sneakers)))
-		}
-	}()
-
 	for _, sneaker := range in.GetSneakers() {
 		s := &model.Sneaker{}
 		if err := s.FromGrpc(sneaker); err != nil {
 			response.ErrorMessage = err.Error()
 			response.StatusCode = http.StatusBadRequest
 			response.Status = 2 // VALIDATION_ERROR
-			a.log.Error("ERROR: bad request Update sneakers", zap.Error(err))
-			return response, err
+			a.log.Warn("bad request Update sneakers", zap.Error(err))
+			return response, nil
 		}
 		sneakers = append(sneakers, s)
-		response.SneakerIds = append(response.SneakerIds, s.ID)
+	}
 
+	if err := a.s.UpdateSneakers(ctx, sneakers); err != nil {
+		response.StatusCode = http.StatusInternalServerError
+		response.ErrorMessage = err.Error()
+		response.Status = 1 // FAILURE
+		a.log.Error("ERROR: Update sneakers", zap.Error(err))
+		return response, err
+	}
+
+	for _, s := range sneakers {
+		response.SneakerIds = append(response.SneakerIds, s.ID)
 	}
+	a.log.Info("successfully Updated sneakers", zap.Int("quantity sneakers Updated", len(sneakers)))
 
 	return response, nil
 }
\ No newline at end of file
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+)
+
+const usage = `usage:
+  inventory [-config path] [serve]
+  inventory [-config path] migrate <up | down N | goto V | force V | status>`
+
+func main() {
+	configPath := flag.String("config", "", "path to YAML config (default $"+config.ConfigPathEnv+")")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	switch args[0] {
+	case "serve":
+		os.Exit(runServe(*configPath))
+	case "migrate":
+		os.Exit(runMigrate(*configPath, args[1:]))
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n%s\n", args[0], usage)
+		os.Exit(2)
+	}
+}
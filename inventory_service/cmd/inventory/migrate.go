@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/migrate"
+)
+
+func runMigrate(configPath string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: load config:", err)
+		return 1
+	}
+
+	m, err := migrate.NewMigrator(cfg.StorageConfig.DSN())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		return 1
+	}
+	defer m.Close()
+
+	if err := execMigrate(m, args); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: migrate", args[0]+":", err)
+		return 1
+	}
+
+	if err := printStatus(m); err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: migrate status:", err)
+		return 1
+	}
+
+	return 0
+}
+
+func execMigrate(m *migrate.Migrator, args []string) error {
+	cmd := args[0]
+
+	switch cmd {
+	case "up":
+		return m.Up()
+	case "status":
+		return nil
+	case "down", "goto", "force":
+		if len(args) != 2 {
+			return fmt.Errorf("expected exactly one argument")
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid argument %q: expected non-negative integer", args[1])
+		}
+
+		switch cmd {
+		case "down":
+			return m.Down(n)
+		case "goto":
+			return m.Goto(uint(n))
+		default:
+			return m.Force(n)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand")
+	}
+}
+
+func printStatus(m *migrate.Migrator) error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version: %d (dirty: %t)\n", status.Version, status.Dirty)
+	fmt.Println("applied:", formatVersions(status.Applied))
+	fmt.Println("pending:", formatVersions(status.Pending))
+
+	return nil
+}
+
+func formatVersions(versions []uint) string {
+	if len(versions) == 0 {
+		return "-"
+	}
+
+	out := ""
+	for i, v := range versions {
+		if i > 0 {
+			out += ", "
+		}
+		out += strconv.FormatUint(uint64(v), 10)
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/kripst/krosovka/inventory_service/api"
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/fx"
+	"github.com/kripst/krosovka/inventory_service/internal/logger"
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"github.com/kripst/krosovka/inventory_service/internal/service"
+	"github.com/kripst/krosovka/inventory_service/internal/storage/postgres"
+	"github.com/kripst/krosovka/inventory_service/internal/tracing"
+	"github.com/kripst/krosovka/inventory_service/migrate"
+	"go.uber.org/zap"
+)
+
+func runServe(configPath string) int {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: load config:", err)
+		return 1
+	}
+
+	log, err := logger.New(cfg.Logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: init logger:", err)
+		return 1
+	}
+	defer log.Sync()
+
+	log.Info("config loaded", zap.Any("config", cfg.Redacted()))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Error("ERROR: init tracing", zap.Error(err))
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Warn("tracing shutdown", zap.Error(err))
+		}
+	}()
+
+	if err := migrate.RunMigrations(cfg.StorageConfig.DSN()); err != nil {
+		log.Error("ERROR: run migrations", zap.Error(err))
+		return 1
+	}
+
+	pgStorage, err := postgres.NewPostgresStorageImpl(cfg.StorageConfig, log, ctx)
+	if err != nil {
+		log.Error("ERROR: init storage", zap.Error(err))
+		return 1
+	}
+
+	m := metrics.New()
+	m.MustRegister(
+		metrics.NewPoolCollector(pgStorage),
+		metrics.NewCatalogCollector(pgStorage),
+	)
+	storage := metrics.WrapStorage(tracing.WrapStorage(pgStorage), m)
+
+	rates, err := newConverter(ctx, cfg.FX, storage)
+	if err != nil {
+		log.Error("ERROR: init exchange rates", zap.Error(err))
+		return 1
+	}
+
+	apiServer := api.NewApiServerImpl(storage, log, rates)
+	ordersServer := api.NewOrdersServerImpl(storage, log)
+	server, err := service.NewServer(cfg, log, storage, apiServer, ordersServer, m)
+	if err != nil {
+		log.Error("ERROR: init server", zap.Error(err))
+		return 1
+	}
+	server.AddBackground(fx.NewRefresher(storage, rates, log, cfg.FX.RefreshInterval).Run)
+
+	if err := server.Run(ctx); err != nil {
+		log.Error("ERROR: server stopped", zap.Error(err))
+		return 1
+	}
+
+	log.Info("server stopped")
+	return 0
+}
+
+// newConverter загружает курсы из файла и из БД; из двух побеждает более свежий.
+func newConverter(ctx context.Context, cfg config.FXConfig, store fx.RateStore) (*fx.Converter, error) {
+	converter := fx.NewConverter(cfg.MaxRateAge)
+
+	if cfg.RatesFile != "" {
+		rates, err := fx.LoadFile(cfg.RatesFile)
+		if err != nil {
+			return nil, err
+		}
+		converter.Set(rates...)
+	}
+
+	rates, err := store.GetExchangeRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	converter.Set(rates...)
+
+	return converter, nil
+}
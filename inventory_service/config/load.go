@@ -1,6 +1,22 @@
 package config
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"go.uber.org/zap/zapcore"
+)
+
+// ConfigPathEnv задаёт путь к YAML-файлу, если он не передан флагом.
+const ConfigPathEnv = "CONFIG_PATH"
+
+const redacted = "[REDACTED]"
 
 type StorageConfig struct {
 	Host    string `yaml:"host" env:"PG_HOST" env-default:"localhost"`
@@ -9,27 +25,369 @@ type StorageConfig struct {
 	Pass    string `yaml:"pass" env:"PG_PASSWORD" env-default:"password"`
 	DBName  string `yaml:"dbname" env:"PG_DBNAME" env-default:"database"`
 	SSLMode string `yaml:"sslmode" env:"PG_SSLMODE" env-default:"disable"`
-	PoolMax int   `yaml:"pool_max" env:"PG_POOL_MAX" env-default:"10"`
+	PoolMax int    `yaml:"pool_max" env:"PG_POOL_MAX" env-default:"10"`
+	PoolMin int    `yaml:"pool_min" env:"PG_POOL_MIN" env-default:"0"`
+
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env:"PG_MAX_CONN_LIFETIME" env-default:"1h"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"PG_MAX_CONN_IDLE_TIME" env-default:"30m"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"PG_HEALTH_CHECK_PERIOD" env-default:"1m"`
+	// StatementTimeout выставляется сессии как statement_timeout, 0 - без ограничения
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"PG_STATEMENT_TIMEOUT" env-default:"30s"`
+	ApplicationName  string        `yaml:"application_name" env:"PG_APPLICATION_NAME" env-default:"inventory_service"`
+
+	// Пути к сертификатам для sslmode=verify-ca / verify-full
+	SSLRootCert string `yaml:"sslrootcert" env:"PG_SSLROOTCERT"`
+	SSLCert     string `yaml:"sslcert" env:"PG_SSLCERT"`
+	SSLKey      string `yaml:"sslkey" env:"PG_SSLKEY"`
+
+	// Повторные попытки подключения при старте с экспоненциальной задержкой
+	ConnectRetries    int           `yaml:"connect_retries" env:"PG_CONNECT_RETRIES" env-default:"5"`
+	ConnectBackoff    time.Duration `yaml:"connect_backoff" env:"PG_CONNECT_BACKOFF" env-default:"1s"`
+	ConnectBackoffMax time.Duration `yaml:"connect_backoff_max" env:"PG_CONNECT_BACKOFF_MAX" env-default:"30s"`
 }
 
 func (c *StorageConfig) DSN() string {
-	return fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		c.User,
-		c.Pass,
-		c.Host,
-		c.Port,
-		c.DBName,
-		c.SSLMode,
-	)
+	params := url.Values{}
+	params.Set("sslmode", c.SSLMode)
+	if c.ApplicationName != "" {
+		params.Set("application_name", c.ApplicationName)
+	}
+	if c.SSLRootCert != "" {
+		params.Set("sslrootcert", c.SSLRootCert)
+	}
+	if c.SSLCert != "" {
+		params.Set("sslcert", c.SSLCert)
+	}
+	if c.SSLKey != "" {
+		params.Set("sslkey", c.SSLKey)
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Pass),
+		Host:     net.JoinHostPort(c.Host, c.Port),
+		Path:     c.DBName,
+		RawQuery: params.Encode(),
+	}
+	return dsn.String()
+}
+
+type GRPCConfig struct {
+	Addr       string `yaml:"addr" env:"GRPC_ADDR" env-default:":50051"`
+	Reflection bool   `yaml:"reflection" env:"GRPC_REFLECTION" env-default:"true"`
+	// HealthCheckInterval - как часто проверяется доступность БД для grpc.health.v1
+	HealthCheckInterval time.Duration `yaml:"health_check_interval" env:"GRPC_HEALTH_CHECK_INTERVAL" env-default:"5s"`
+}
+
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED" env-default:"true"`
+	Addr    string `yaml:"addr" env:"METRICS_ADDR" env-default:":9090"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"` // none | stdout | otlp
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" env-default:"inventory_service"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+type AuthConfig struct {
+	Enabled bool           `yaml:"enabled" env:"AUTH_ENABLED" env-default:"true"`
+	APIKeys []APIKeyConfig `yaml:"api_keys"`
+	JWT     JWTConfig      `yaml:"jwt"`
+}
+
+// APIKeyConfig - статический ключ клиента, передаётся в metadata x-api-key.
+type APIKeyConfig struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+	Role string `yaml:"role"` // reader | editor | admin
+	// ForwardsActor - ключ сервиса, который действует от имени своих пользователей,
+	// как бот от имени администраторов: его metadata x-actor попадает в аудит
+	ForwardsActor bool `yaml:"forwards_actor"`
+}
+
+// JWTConfig - проверка токенов из metadata authorization: Bearer <token>.
+// Достаточно задать HMAC-секрет или путь к публичному RSA-ключу (PEM).
+type JWTConfig struct {
+	HMACSecret       string `yaml:"hmac_secret" env:"AUTH_JWT_HMAC_SECRET"`
+	RSAPublicKeyPath string `yaml:"rsa_public_key_path" env:"AUTH_JWT_RSA_PUBLIC_KEY_PATH"`
+	Issuer           string `yaml:"issuer" env:"AUTH_JWT_ISSUER"`
+	Audience         string `yaml:"audience" env:"AUTH_JWT_AUDIENCE"`
+	RoleClaim        string `yaml:"role_claim" env:"AUTH_JWT_ROLE_CLAIM" env-default:"role"`
+}
+
+type RateLimitConfig struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED" env-default:"true"`
+	// Token bucket на клиента по всем методам
+	ClientRPS   float64 `yaml:"client_rps" env:"RATE_LIMIT_CLIENT_RPS" env-default:"50"`
+	ClientBurst int     `yaml:"client_burst" env:"RATE_LIMIT_CLIENT_BURST" env-default:"100"`
+	// Дополнительные лимиты на клиента для отдельных методов, ключ - имя метода (CreateSneakers)
+	Methods map[string]MethodLimitConfig `yaml:"methods"`
+	// MaxInFlight - предел одновременных RPC; 0 - pool_max * InFlightPerConn
+	MaxInFlight     int `yaml:"max_in_flight" env:"RATE_LIMIT_MAX_IN_FLIGHT" env-default:"0"`
+	InFlightPerConn int `yaml:"in_flight_per_conn" env:"RATE_LIMIT_IN_FLIGHT_PER_CONN" env-default:"2"`
+}
+
+type MethodLimitConfig struct {
+	RPS   float64 `yaml:"rps"`
+	Burst int     `yaml:"burst"`
+}
+
+// EffectiveMaxInFlight - предел одновременных RPC с учётом размера пула.
+func (c *Config) EffectiveMaxInFlight() int {
+	if c.RateLimit.MaxInFlight > 0 {
+		return c.RateLimit.MaxInFlight
+	}
+	if c.StorageConfig == nil {
+		return 0
+	}
+	return c.StorageConfig.PoolMax * c.RateLimit.InFlightPerConn
+}
+
+// PriceWorkerConfig - фоновое применение и откат запланированных цен.
+type PriceWorkerConfig struct {
+	Enabled  bool          `yaml:"enabled" env:"PRICE_WORKER_ENABLED" env-default:"true"`
+	Interval time.Duration `yaml:"interval" env:"PRICE_WORKER_INTERVAL" env-default:"30s"`
+}
+
+// FXConfig - курсы валют для справочных цен в других валютах.
+type FXConfig struct {
+	// RatesFile - YAML с курсами, загружается при старте; пусто - только курсы из БД
+	RatesFile string `yaml:"rates_file" env:"FX_RATES_FILE"`
+	// RefreshInterval - как часто перечитываются курсы, заданные через SetExchangeRates
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"FX_REFRESH_INTERVAL" env-default:"1m"`
+	// MaxRateAge - курсы старше не используются; 0 - без ограничения
+	MaxRateAge time.Duration `yaml:"max_rate_age" env:"FX_MAX_RATE_AGE" env-default:"0"`
+}
+
+type TimeoutsConfig struct {
+	// Request - дедлайн RPC по умолчанию, если клиент его не передал
+	Request  time.Duration `yaml:"request" env:"REQUEST_TIMEOUT" env-default:"10s"`
+	Shutdown time.Duration `yaml:"shutdown" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+}
+
+type LoggerConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+	Format string `yaml:"format" env:"LOG_FORMAT" env-default:"json"` // json | console
 }
 
 type Config struct {
-	StorageConfig *StorageConfig
+	StorageConfig *StorageConfig    `yaml:"storage"`
+	GRPC          GRPCConfig        `yaml:"grpc"`
+	Metrics       MetricsConfig     `yaml:"metrics"`
+	Tracing       TracingConfig     `yaml:"tracing"`
+	Auth          AuthConfig        `yaml:"auth"`
+	RateLimit     RateLimitConfig   `yaml:"rate_limit"`
+	PriceWorker   PriceWorkerConfig `yaml:"price_worker"`
+	FX            FXConfig          `yaml:"fx"`
+	Timeouts      TimeoutsConfig    `yaml:"timeouts"`
+	Logger        LoggerConfig      `yaml:"logger"`
 }
 
-func Load() (*Config, error) {
-	return &Config{
+// Load читает конфиг из YAML-файла path (или CONFIG_PATH), затем
+// переопределяет значения из окружения и подставляет значения по умолчанию.
+// Без файла конфиг собирается только из окружения.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(ConfigPathEnv)
+	}
+
+	cfg := &Config{
+		StorageConfig: &StorageConfig{},
+	}
+
+	if path != "" {
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, fmt.Errorf("failed to read config %q: %w", path, err)
+		}
+	} else if err := cleanenv.ReadEnv(cfg); err != nil {
+		return nil, fmt.Errorf("failed to read config from env: %w", err)
+	}
+
+	// cleanenv не обходит вложенные указатели, поэтому такие секции читаем отдельно
+	if err := cleanenv.ReadEnv(cfg.StorageConfig); err != nil {
+		return nil, fmt.Errorf("failed to read storage config from env: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate возвращает все найденные ошибки конфигурации разом.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.StorageConfig == nil {
+		fail("storage", "section is required")
+	} else {
+		s := c.StorageConfig
+		if s.Host == "" {
+			fail("storage.host", "must not be empty")
+		}
+		if port, err := strconv.Atoi(s.Port); err != nil || port <= 0 || port > 65535 {
+			fail("storage.port", "must be a number in 1..65535, got %q", s.Port)
+		}
+		if s.User == "" {
+			fail("storage.user", "must not be empty")
+		}
+		if s.DBName == "" {
+			fail("storage.dbname", "must not be empty")
+		}
+		switch s.SSLMode {
+		case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		default:
+			fail("storage.sslmode", "unknown mode %q", s.SSLMode)
+		}
+		if s.PoolMax <= 0 {
+			fail("storage.pool_max", "must be positive, got %d", s.PoolMax)
+		}
+		if s.PoolMin < 0 || s.PoolMin > s.PoolMax {
+			fail("storage.pool_min", "must be in 0..pool_max (%d), got %d", s.PoolMax, s.PoolMin)
+		}
+		for _, d := range []struct {
+			field string
+			value time.Duration
+		}{
+			{"storage.max_conn_lifetime", s.MaxConnLifetime},
+			{"storage.max_conn_idle_time", s.MaxConnIdleTime},
+			{"storage.health_check_period", s.HealthCheckPeriod},
+			{"storage.statement_timeout", s.StatementTimeout},
+			{"storage.connect_backoff", s.ConnectBackoff},
+			{"storage.connect_backoff_max", s.ConnectBackoffMax},
+		} {
+			if d.value < 0 {
+				fail(d.field, "must not be negative, got %s", d.value)
+			}
+		}
+		if s.ConnectRetries < 0 {
+			fail("storage.connect_retries", "must not be negative, got %d", s.ConnectRetries)
+		}
+		if (s.SSLMode == "verify-ca" || s.SSLMode == "verify-full") && s.SSLRootCert == "" {
+			fail("storage.sslrootcert", "is required for sslmode=%s", s.SSLMode)
+		}
+		if (s.SSLCert == "") != (s.SSLKey == "") {
+			fail("storage.sslcert", "sslcert and sslkey must be set together")
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(c.GRPC.Addr); err != nil {
+		fail("grpc.addr", "must be host:port, got %q", c.GRPC.Addr)
+	}
+
+	if c.GRPC.HealthCheckInterval <= 0 {
+		fail("grpc.health_check_interval", "must be positive, got %s", c.GRPC.HealthCheckInterval)
+	}
+
+	if c.PriceWorker.Enabled && c.PriceWorker.Interval <= 0 {
+		fail("price_worker.interval", "must be positive, got %s", c.PriceWorker.Interval)
+	}
+
+	if c.FX.RefreshInterval <= 0 {
+		fail("fx.refresh_interval", "must be positive, got %s", c.FX.RefreshInterval)
+	}
+	if c.FX.MaxRateAge < 0 {
+		fail("fx.max_rate_age", "must not be negative, got %s", c.FX.MaxRateAge)
+	}
 
-	}, nil
-}
\ No newline at end of file
+	if c.Metrics.Enabled {
+		if _, _, err := net.SplitHostPort(c.Metrics.Addr); err != nil {
+			fail("metrics.addr", "must be host:port, got %q", c.Metrics.Addr)
+		}
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout":
+	case "otlp":
+		if c.Tracing.Endpoint == "" {
+			fail("tracing.endpoint", "is required for otlp exporter")
+		}
+	default:
+		fail("tracing.exporter", "must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be in 0..1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Auth.Enabled {
+		if len(c.Auth.APIKeys) == 0 && c.Auth.JWT.HMACSecret == "" && c.Auth.JWT.RSAPublicKeyPath == "" {
+			fail("auth", "enabled, but neither api_keys nor jwt keys are configured")
+		}
+		for i, key := range c.Auth.APIKeys {
+			if key.Name == "" || key.Key == "" {
+				fail(fmt.Sprintf("auth.api_keys[%d]", i), "name and key are required")
+			}
+			switch key.Role {
+			case "reader", "editor", "admin":
+			default:
+				fail(fmt.Sprintf("auth.api_keys[%d].role", i), "must be reader, editor or admin, got %q", key.Role)
+			}
+		}
+	}
+
+	if c.RateLimit.Enabled {
+		if c.RateLimit.ClientRPS <= 0 || c.RateLimit.ClientBurst <= 0 {
+			fail("rate_limit.client_rps", "client_rps and client_burst must be positive")
+		}
+		for method, limit := range c.RateLimit.Methods {
+			if limit.RPS <= 0 || limit.Burst <= 0 {
+				fail("rate_limit.methods."+method, "rps and burst must be positive")
+			}
+		}
+		if c.RateLimit.MaxInFlight < 0 || c.RateLimit.InFlightPerConn <= 0 {
+			fail("rate_limit.max_in_flight", "max_in_flight must not be negative and in_flight_per_conn must be positive")
+		}
+	}
+
+	if c.Timeouts.Request <= 0 {
+		fail("timeouts.request", "must be positive, got %s", c.Timeouts.Request)
+	}
+	if c.Timeouts.Shutdown <= 0 {
+		fail("timeouts.shutdown", "must be positive, got %s", c.Timeouts.Shutdown)
+	}
+
+	if _, err := zapcore.ParseLevel(c.Logger.Level); err != nil {
+		fail("logger.level", "unknown level %q", c.Logger.Level)
+	}
+	switch c.Logger.Format {
+	case "json", "console":
+	default:
+		fail("logger.format", "must be json or console, got %q", c.Logger.Format)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Redacted возвращает копию конфига без секретов, пригодную для логирования.
+func (c *Config) Redacted() *Config {
+	out := *c
+
+	if c.StorageConfig != nil {
+		storage := *c.StorageConfig
+		storage.Pass = redact(storage.Pass)
+		out.StorageConfig = &storage
+	}
+
+	out.Auth.APIKeys = make([]APIKeyConfig, len(c.Auth.APIKeys))
+	for i, key := range c.Auth.APIKeys {
+		key.Key = redact(key.Key)
+		out.Auth.APIKeys[i] = key
+	}
+	out.Auth.JWT.HMACSecret = redact(c.Auth.JWT.HMACSecret)
+
+	return &out
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
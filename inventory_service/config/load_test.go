@@ -0,0 +1,142 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Значения из файла переопределяются окружением, пустые поля получают значения по умолчанию.
+func TestLoad_FileEnvDefaults(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, `
+storage:
+  host: db.internal
+  pass: from-file
+grpc:
+  addr: "0.0.0.0:6000"
+`)
+	t.Setenv("PG_PASSWORD", "from-env")
+	t.Setenv("AUTH_JWT_HMAC_SECRET", "test-secret")
+
+	cfg, err := config.Load(path)
+
+	require.NoError(err)
+	require.Equal("db.internal", cfg.StorageConfig.Host)
+	require.Equal("from-env", cfg.StorageConfig.Pass)
+	require.Equal("5432", cfg.StorageConfig.Port)
+	require.Equal("0.0.0.0:6000", cfg.GRPC.Addr)
+	require.Equal(10*time.Second, cfg.Timeouts.Request)
+	require.Equal("info", cfg.Logger.Level)
+}
+
+func TestLoad_PathFromEnv(t *testing.T) {
+	require := require.New(t)
+	t.Setenv(config.ConfigPathEnv, writeConfig(t, "storage:\n  dbname: inventory\n"))
+	t.Setenv("AUTH_JWT_HMAC_SECRET", "test-secret")
+
+	cfg, err := config.Load("")
+
+	require.NoError(err)
+	require.Equal("inventory", cfg.StorageConfig.DBName)
+}
+
+// Все ошибки валидации возвращаются вместе, с именем поля.
+func TestLoad_Validation(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, `
+storage:
+  port: "abc"
+  sslmode: sometimes
+logger:
+  level: loud
+`)
+
+	_, err := config.Load(path)
+
+	require.Error(err)
+	require.ErrorContains(err, "storage.port")
+	require.ErrorContains(err, "storage.sslmode")
+	require.ErrorContains(err, "logger.level")
+}
+
+func TestLoad_AuthRequiresKeys(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, `
+auth:
+  api_keys:
+    - name: bot
+      key: k
+      role: owner
+`)
+
+	_, err := config.Load(path)
+
+	require.ErrorContains(err, "auth.api_keys[0].role")
+}
+
+func TestRedacted(t *testing.T) {
+	require := require.New(t)
+	cfg := &config.Config{
+		StorageConfig: &config.StorageConfig{User: "postgres", Pass: "secret"},
+		Auth: config.AuthConfig{
+			APIKeys: []config.APIKeyConfig{{Name: "bot", Key: "api-secret", Role: "reader"}},
+			JWT:     config.JWTConfig{HMACSecret: "jwt-secret"},
+		},
+	}
+
+	out := cfg.Redacted()
+
+	require.NotContains(out.StorageConfig.Pass, "secret")
+	require.NotContains(out.Auth.APIKeys[0].Key, "secret")
+	require.NotContains(out.Auth.JWT.HMACSecret, "secret")
+	require.Equal("bot", out.Auth.APIKeys[0].Name)
+	require.Equal("secret", cfg.StorageConfig.Pass, "исходный конфиг не должен меняться")
+	require.Equal("api-secret", cfg.Auth.APIKeys[0].Key, "исходный конфиг не должен меняться")
+}
+
+func TestLoad_VerifyFullRequiresRootCert(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, "storage:\n  sslmode: verify-full\n")
+
+	_, err := config.Load(path)
+
+	require.ErrorContains(err, "storage.sslrootcert")
+}
+
+func TestStorageConfig_DSN(t *testing.T) {
+	require := require.New(t)
+	c := &config.StorageConfig{
+		Host: "db", Port: "5432", User: "app", Pass: "p@ss", DBName: "inventory",
+		SSLMode: "verify-full", SSLRootCert: "/certs/root.crt", ApplicationName: "inventory_service",
+	}
+
+	dsn := c.DSN()
+
+	require.Equal("postgres://app:p%40ss@db:5432/inventory?application_name=inventory_service&sslmode=verify-full&sslrootcert=%2Fcerts%2Froot.crt", dsn)
+}
+
+// Без явного max_in_flight предел считается от размера пула.
+func TestEffectiveMaxInFlight(t *testing.T) {
+	require := require.New(t)
+	cfg := &config.Config{
+		StorageConfig: &config.StorageConfig{PoolMax: 10},
+		RateLimit:     config.RateLimitConfig{InFlightPerConn: 2},
+	}
+
+	require.Equal(20, cfg.EffectiveMaxInFlight())
+
+	cfg.RateLimit.MaxInFlight = 5
+	require.Equal(5, cfg.EffectiveMaxInFlight())
+}
@@ -0,0 +1,182 @@
+// Package auth проверяет API-ключи и JWT и применяет ролевую модель к RPC.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	APIKeyHeader        = "x-api-key"
+	AuthorizationHeader = "authorization"
+	// ActorHeader - пользователь, от имени которого действует клиент с ForwardsActor
+	ActorHeader = "x-actor"
+)
+
+// actorPattern - допустимое значение x-actor, например tg:123456789.
+var actorPattern = regexp.MustCompile(`^[A-Za-z0-9._:@-]{1,64}$`)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+type apiKey struct {
+	name          string
+	key           []byte
+	role          Role
+	forwardsActor bool
+}
+
+type Authenticator struct {
+	apiKeys []apiKey
+
+	hmacSecret   []byte
+	rsaPublicKey *rsa.PublicKey
+	parser       *jwt.Parser
+	roleClaim    string
+}
+
+func NewAuthenticator(cfg config.AuthConfig) (*Authenticator, error) {
+	a := &Authenticator{
+		roleClaim: cfg.JWT.RoleClaim,
+	}
+
+	for _, k := range cfg.APIKeys {
+		role, ok := ParseRole(k.Role)
+		if !ok {
+			return nil, fmt.Errorf("api key %q: unknown role %q", k.Name, k.Role)
+		}
+		a.apiKeys = append(a.apiKeys, apiKey{name: k.Name, key: []byte(k.Key), role: role, forwardsActor: k.ForwardsActor})
+	}
+
+	var methods []string
+	if cfg.JWT.HMACSecret != "" {
+		a.hmacSecret = []byte(cfg.JWT.HMACSecret)
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if cfg.JWT.RSAPublicKeyPath != "" {
+		pemData, err := os.ReadFile(cfg.JWT.RSAPublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt rsa public key: %w", err)
+		}
+		a.rsaPublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt rsa public key: %w", err)
+		}
+		methods = append(methods, "RS256", "RS384", "RS512")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.JWT.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.JWT.Issuer))
+	}
+	if cfg.JWT.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWT.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+
+	return a, nil
+}
+
+// Authenticate определяет клиента по metadata запроса.
+func (a *Authenticator) Authenticate(ctx context.Context) (reqctx.Identity, Role, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(APIKeyHeader); len(values) > 0 {
+		return a.authenticateAPIKey(values[0], md.Get(ActorHeader))
+	}
+
+	if values := md.Get(AuthorizationHeader); len(values) > 0 {
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: expected bearer token", ErrInvalidCredentials)
+		}
+		return a.authenticateJWT(strings.TrimSpace(token))
+	}
+
+	return reqctx.Identity{}, RoleNone, ErrNoCredentials
+}
+
+// authenticateAPIKey проверяет ключ. x-actor учитывается только у ключей с
+// ForwardsActor, у остальных клиент мог бы приписать свои изменения кому угодно.
+func (a *Authenticator) authenticateAPIKey(key string, actor []string) (reqctx.Identity, Role, error) {
+	for _, k := range a.apiKeys {
+		if subtle.ConstantTimeCompare(k.key, []byte(key)) != 1 {
+			continue
+		}
+		identity := reqctx.Identity{Subject: k.name, Role: k.role.String(), AuthMethod: "api_key"}
+		if k.forwardsActor && len(actor) > 0 {
+			if !actorPattern.MatchString(actor[0]) {
+				return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: malformed %s", ErrInvalidCredentials, ActorHeader)
+			}
+			identity.OnBehalfOf = actor[0]
+		}
+		return identity, k.role, nil
+	}
+	return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+}
+
+func (a *Authenticator) authenticateJWT(tokenString string) (reqctx.Identity, Role, error) {
+	if a.hmacSecret == nil && a.rsaPublicKey == nil {
+		return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: jwt is not configured", ErrInvalidCredentials)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(tokenString, claims, a.key)
+	if err != nil {
+		return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	roleName, _ := claims[a.roleClaim].(string)
+	role, ok := ParseRole(roleName)
+	if !ok {
+		return reqctx.Identity{}, RoleNone, fmt.Errorf("%w: unknown role %q", ErrInvalidCredentials, roleName)
+	}
+
+	return reqctx.Identity{Subject: subject, Role: role.String(), AuthMethod: "jwt"}, role, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if a.hmacSecret != nil {
+			return a.hmacSecret, nil
+		}
+	case *jwt.SigningMethodRSA:
+		if a.rsaPublicKey != nil {
+			return a.rsaPublicKey, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// Authorize проверяет, что роли хватает для вызова fullMethod.
+func Authorize(fullMethod string, role Role) bool {
+	return role >= requiredRole(fullMethod)
+}
+
+func IsPublic(fullMethod string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/auth"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func withMD(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+}
+
+func signHMAC(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	require := require.New(t)
+	a, err := auth.NewAuthenticator(config.AuthConfig{
+		APIKeys: []config.APIKeyConfig{{Name: "tg_bot", Key: "bot-key", Role: "reader"}},
+	})
+	require.NoError(err)
+
+	identity, role, err := a.Authenticate(withMD(auth.APIKeyHeader, "bot-key"))
+	require.NoError(err)
+	require.Equal("tg_bot", identity.Subject)
+	require.Equal(auth.RoleReader, role)
+
+	_, _, err = a.Authenticate(withMD(auth.APIKeyHeader, "wrong"))
+	require.ErrorIs(err, auth.ErrInvalidCredentials)
+
+	_, _, err = a.Authenticate(context.Background())
+	require.ErrorIs(err, auth.ErrNoCredentials)
+}
+
+// x-actor принимается только от ключа с ForwardsActor и попадает в Actor.
+func TestAuthenticate_Actor(t *testing.T) {
+	require := require.New(t)
+	a, err := auth.NewAuthenticator(config.AuthConfig{
+		APIKeys: []config.APIKeyConfig{
+			{Name: "tg_bot", Key: "bot-key", Role: "editor", ForwardsActor: true},
+			{Name: "importer", Key: "import-key", Role: "editor"},
+		},
+	})
+	require.NoError(err)
+
+	identity, _, err := a.Authenticate(withMD(auth.APIKeyHeader, "bot-key", auth.ActorHeader, "tg:100"))
+	require.NoError(err)
+	require.Equal("tg:100", identity.OnBehalfOf)
+	require.Equal("tg_bot/tg:100", reqctx.Actor(reqctx.WithIdentity(context.Background(), identity)))
+
+	identity, _, err = a.Authenticate(withMD(auth.APIKeyHeader, "import-key", auth.ActorHeader, "tg:100"))
+	require.NoError(err)
+	require.Empty(identity.OnBehalfOf, "ключ без forwards_actor не выдаёт себя за пользователя")
+	require.Equal("importer", reqctx.Actor(reqctx.WithIdentity(context.Background(), identity)))
+
+	_, _, err = a.Authenticate(withMD(auth.APIKeyHeader, "bot-key", auth.ActorHeader, strings.Repeat("x", 65)))
+	require.ErrorIs(err, auth.ErrInvalidCredentials)
+}
+
+func TestAuthenticate_HMAC(t *testing.T) {
+	require := require.New(t)
+	a, err := auth.NewAuthenticator(config.AuthConfig{
+		JWT: config.JWTConfig{HMACSecret: "secret", Issuer: "krosovka", RoleClaim: "role"},
+	})
+	require.NoError(err)
+
+	token := signHMAC(t, "secret", jwt.MapClaims{
+		"sub": "manager@krosovka", "role": "editor", "iss": "krosovka",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	identity, role, err := a.Authenticate(withMD(auth.AuthorizationHeader, "Bearer "+token))
+	require.NoError(err)
+	require.Equal("manager@krosovka", identity.Subject)
+	require.Equal(auth.RoleEditor, role)
+
+	expired := signHMAC(t, "secret", jwt.MapClaims{
+		"sub": "manager@krosovka", "role": "editor", "iss": "krosovka",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	_, _, err = a.Authenticate(withMD(auth.AuthorizationHeader, "Bearer "+expired))
+	require.ErrorIs(err, auth.ErrInvalidCredentials)
+
+	forged := signHMAC(t, "other", jwt.MapClaims{
+		"sub": "manager@krosovka", "role": "admin", "iss": "krosovka",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	_, _, err = a.Authenticate(withMD(auth.AuthorizationHeader, "Bearer "+forged))
+	require.ErrorIs(err, auth.ErrInvalidCredentials)
+}
+
+func TestAuthenticate_RSA(t *testing.T) {
+	require := require.New(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	pubDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(err)
+	path := filepath.Join(t.TempDir(), "jwt.pub")
+	require.NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o600))
+
+	a, err := auth.NewAuthenticator(config.AuthConfig{
+		JWT: config.JWTConfig{RSAPublicKeyPath: path, RoleClaim: "role"},
+	})
+	require.NoError(err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "admin@krosovka", "role": "admin", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString(key)
+	require.NoError(err)
+
+	_, role, err := a.Authenticate(withMD(auth.AuthorizationHeader, "Bearer "+token))
+	require.NoError(err)
+	require.Equal(auth.RoleAdmin, role)
+}
+
+func TestAuthorize(t *testing.T) {
+	require := require.New(t)
+
+	require.True(auth.Authorize(pb.InventoryService_GetSneakers_FullMethodName, auth.RoleReader))
+	require.False(auth.Authorize(pb.InventoryService_DeleteSneakers_FullMethodName, auth.RoleReader))
+	require.True(auth.Authorize(pb.InventoryService_DeleteSneakers_FullMethodName, auth.RoleEditor))
+	require.True(auth.Authorize(pb.InventoryService_RestoreSneakers_FullMethodName, auth.RoleEditor))
+	require.False(auth.Authorize(pb.InventoryService_PurgeSneakers_FullMethodName, auth.RoleEditor))
+	require.True(auth.Authorize(pb.InventoryService_PurgeSneakers_FullMethodName, auth.RoleAdmin))
+	require.False(auth.Authorize("/inventoryservice.InventoryService/Unknown", auth.RoleEditor))
+	require.True(auth.IsPublic("/grpc.health.v1.Health/Check"))
+}
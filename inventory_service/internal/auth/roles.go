@@ -0,0 +1,73 @@
+package auth
+
+import (
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleReader
+	RoleEditor
+	RoleAdmin
+)
+
+func ParseRole(s string) (Role, bool) {
+	switch s {
+	case "reader":
+		return RoleReader, true
+	case "editor":
+		return RoleEditor, true
+	case "admin":
+		return RoleAdmin, true
+	default:
+		return RoleNone, false
+	}
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleReader:
+		return "reader"
+	case RoleEditor:
+		return "editor"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// methodRoles - минимальная роль для вызова метода. Методы, которых нет
+// в списке, доступны только admin.
+var methodRoles = map[string]Role{
+	pb.InventoryService_GetSneakers_FullMethodName:          RoleReader,
+	pb.InventoryService_CreateSneakers_FullMethodName:       RoleEditor,
+	pb.InventoryService_UpdateSneakers_FullMethodName:       RoleEditor,
+	pb.InventoryService_DeleteSneakers_FullMethodName:       RoleEditor,
+	pb.InventoryService_RestoreSneakers_FullMethodName:      RoleEditor,
+	pb.InventoryService_GetAuditLog_FullMethodName:          RoleEditor,
+	pb.InventoryService_SchedulePriceChanges_FullMethodName: RoleEditor,
+	pb.InventoryService_CancelPriceChanges_FullMethodName:   RoleEditor,
+	pb.InventoryService_GetPriceHistory_FullMethodName:      RoleReader,
+	pb.InventoryService_GetExchangeRates_FullMethodName:     RoleReader,
+	pb.OrdersService_CreateOrder_FullMethodName:             RoleEditor,
+	pb.OrdersService_GetOrder_FullMethodName:                RoleEditor,
+	pb.OrdersService_ListOrders_FullMethodName:              RoleEditor,
+	pb.OrdersService_TransitionOrder_FullMethodName:         RoleEditor,
+}
+
+// publicServices не требуют аутентификации: пробы Kubernetes и grpcurl.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+func requiredRole(fullMethod string) Role {
+	if role, ok := methodRoles[fullMethod]; ok {
+		return role
+	}
+	return RoleAdmin
+}
@@ -0,0 +1,115 @@
+package fx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+)
+
+var (
+	ErrNoRate    = errors.New("no exchange rate")
+	ErrStaleRate = errors.New("exchange rate is stale")
+)
+
+type pair struct {
+	base, quote money.Currency
+}
+
+// Converter хранит последние известные курсы и пересчитывает суммы для показа.
+// Хранимые цены не меняются: результат конвертации - справочная цена.
+type Converter struct {
+	mu     sync.RWMutex
+	rates  map[pair]model.ExchangeRate
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+// NewConverter создаёт пустой конвертер. maxAge > 0 запрещает курсы старше maxAge.
+func NewConverter(maxAge time.Duration) *Converter {
+	return &Converter{
+		rates:  make(map[pair]model.ExchangeRate),
+		maxAge: maxAge,
+		now:    time.Now,
+	}
+}
+
+// Set добавляет курсы. Для каждой пары остаётся курс с более поздним UpdatedAt,
+// поэтому порядок загрузки из файла и из БД не важен.
+func (c *Converter) Set(rates ...model.ExchangeRate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rate := range rates {
+		key := pair{rate.Base, rate.Quote}
+		if current, ok := c.rates[key]; ok && current.UpdatedAt.After(rate.UpdatedAt) {
+			continue
+		}
+		c.rates[key] = rate
+	}
+}
+
+// Rates возвращает все курсы, отсортированные по паре валют.
+func (c *Converter) Rates() []model.ExchangeRate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rates := make([]model.ExchangeRate, 0, len(c.rates))
+	for _, rate := range c.rates {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Base != rates[j].Base {
+			return rates[i].Base < rates[j].Base
+		}
+		return rates[i].Quote < rates[j].Quote
+	})
+	return rates
+}
+
+// Convert переводит m в валюту to.
+//
+// Правила:
+//   - та же валюта возвращается без изменений;
+//   - используется прямой курс m.Currency->to или обратный to->m.Currency
+//     (делением, без промежуточного округления), из двух - более свежий;
+//   - кросс-курсы через третью валюту не строятся;
+//   - результат округляется до минимальной единицы валюты to, половина - от нуля.
+//
+// Возвращается и курс, по которому выполнен пересчёт.
+func (c *Converter) Convert(m money.Money, to money.Currency) (money.Money, *model.ExchangeRate, error) {
+	if m.Currency == to {
+		return m, nil, nil
+	}
+
+	c.mu.RLock()
+	direct, hasDirect := c.rates[pair{m.Currency, to}]
+	inverse, hasInverse := c.rates[pair{to, m.Currency}]
+	c.mu.RUnlock()
+
+	var (
+		rate   model.ExchangeRate
+		amount = m.Amount
+	)
+	switch {
+	case hasDirect && (!hasInverse || !inverse.UpdatedAt.After(direct.UpdatedAt)):
+		rate = direct
+		amount = amount.Mul(rate.Rate)
+	case hasInverse:
+		rate = inverse
+		amount = amount.Div(rate.Rate)
+	default:
+		return money.Money{}, nil, fmt.Errorf("%w %s->%s", ErrNoRate, m.Currency, to)
+	}
+
+	if c.maxAge > 0 && c.now().Sub(rate.UpdatedAt) > c.maxAge {
+		return money.Money{}, nil, fmt.Errorf("%w: %s->%s updated at %s",
+			ErrStaleRate, rate.Base, rate.Quote, rate.UpdatedAt.Format(time.RFC3339))
+	}
+
+	return money.New(amount, to).Round(), &rate, nil
+}
@@ -0,0 +1,122 @@
+package fx_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/fx"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+var june = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func rate(base, quote money.Currency, value string, updatedAt time.Time) model.ExchangeRate {
+	return model.ExchangeRate{Base: base, Quote: quote, Rate: decimal.RequireFromString(value), UpdatedAt: updatedAt}
+}
+
+func TestConvert(t *testing.T) {
+	converter := fx.NewConverter(0)
+	converter.Set(
+		rate(money.RUB, money.USD, "0.0110", june),
+		rate(money.EUR, money.RUB, "98", june),
+	)
+
+	tests := []struct {
+		name string
+		from money.Money
+		to   money.Currency
+		want string
+	}{
+		{"прямой курс", money.MustParse("4999.99", "RUB"), money.USD, "55.00 USD"},
+		{"обратный курс делением", money.MustParse("4999.99", "RUB"), money.EUR, "51.02 EUR"},
+		{"та же валюта", money.MustParse("4999.99", "RUB"), money.RUB, "4999.99 RUB"},
+		{"половина округляется от нуля", money.MustParse("0.49", "RUB"), money.EUR, "0.01 EUR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := converter.Convert(tt.from, tt.to)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+// Из прямого и обратного курса используется более свежий, старый курс не затирает новый.
+func TestConvert_NewestRateWins(t *testing.T) {
+	require := require.New(t)
+
+	converter := fx.NewConverter(0)
+	converter.Set(rate(money.RUB, money.USD, "0.0110", june))
+	converter.Set(rate(money.USD, money.RUB, "80", june.Add(time.Hour)))
+	converter.Set(rate(money.USD, money.RUB, "100", june))
+
+	got, used, err := converter.Convert(money.MustParse("800", "RUB"), money.USD)
+	require.NoError(err)
+	require.Equal("10.00 USD", got.String())
+	require.Equal(money.USD, used.Base)
+	require.Len(converter.Rates(), 2)
+}
+
+func TestConvert_Errors(t *testing.T) {
+	require := require.New(t)
+
+	converter := fx.NewConverter(24 * time.Hour)
+	converter.Set(rate(money.RUB, money.USD, "0.0110", time.Now().Add(-48*time.Hour)))
+
+	_, _, err := converter.Convert(money.MustParse("100", "RUB"), money.USD)
+	require.True(errors.Is(err, fx.ErrStaleRate), err)
+
+	_, _, err = converter.Convert(money.MustParse("100", "RUB"), money.KZT)
+	require.True(errors.Is(err, fx.ErrNoRate), err)
+}
+
+func TestLoadFile(t *testing.T) {
+	require := require.New(t)
+
+	// --- Arrange ---
+	path := filepath.Join(t.TempDir(), "rates.yaml")
+	require.NoError(os.WriteFile(path, []byte(`
+base: RUB
+updated_at: 2025-06-01T09:00:00Z
+rates:
+  USD: "0.0110"
+  KZT: 5.65
+`), 0o600))
+
+	// --- Act ---
+	rates, err := fx.LoadFile(path)
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Len(rates, 2)
+	converter := fx.NewConverter(0)
+	converter.Set(rates...)
+	got, used, err := converter.Convert(money.MustParse("1000", "RUB"), money.KZT)
+	require.NoError(err)
+	require.Equal("5650.00 KZT", got.String())
+	require.Equal(fx.SourceFile, used.Source)
+	require.True(used.UpdatedAt.Equal(june))
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"нет даты":        "base: RUB\nrates:\n  USD: \"0.011\"\n",
+		"неверная валюта": "base: rub\nupdated_at: 2025-06-01T09:00:00Z\n",
+		"нулевой курс":    "base: RUB\nupdated_at: 2025-06-01T09:00:00Z\nrates:\n  USD: \"0\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rates.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			_, err := fx.LoadFile(path)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package fx
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceFile и SourceAdmin - откуда пришёл курс.
+const (
+	SourceFile  = "file"
+	SourceAdmin = "admin"
+)
+
+// ratesFile - формат файла курсов:
+//
+//	base: RUB
+//	updated_at: 2025-06-01T09:00:00Z
+//	rates:
+//	  USD: "0.0110"   # 1 RUB = 0.0110 USD
+//	  EUR: "0.0102"
+//
+// Курсы лучше писать строками, чтобы YAML не превращал их в float.
+type ratesFile struct {
+	Base      string            `yaml:"base"`
+	UpdatedAt time.Time         `yaml:"updated_at"`
+	Rates     map[string]string `yaml:"rates"`
+}
+
+// LoadFile читает курсы из YAML-файла path.
+func LoadFile(path string) ([]model.ExchangeRate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var file ratesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file %q: %w", path, err)
+	}
+
+	base, err := money.ParseCurrency(file.Base)
+	if err != nil {
+		return nil, fmt.Errorf("rates file %q: base: %w", path, err)
+	}
+	if file.UpdatedAt.IsZero() {
+		return nil, fmt.Errorf("rates file %q: updated_at is required", path)
+	}
+
+	rates := make([]model.ExchangeRate, 0, len(file.Rates))
+	for code, value := range file.Rates {
+		quote, err := money.ParseCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("rates file %q: %w", path, err)
+		}
+		amount, err := decimal.NewFromString(value)
+		if err != nil {
+			return nil, fmt.Errorf("rates file %q: rate %s: %w", path, code, err)
+		}
+
+		rate := model.ExchangeRate{
+			Base:      base,
+			Quote:     quote,
+			Rate:      amount,
+			Source:    SourceFile,
+			UpdatedBy: path,
+			UpdatedAt: file.UpdatedAt,
+		}
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("rates file %q: %w", path, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
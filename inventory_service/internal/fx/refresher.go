@@ -0,0 +1,60 @@
+package fx
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"go.uber.org/zap"
+)
+
+// RateStore - хранилище курсов, заданных через админский RPC.
+type RateStore interface {
+	GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error)
+}
+
+// Refresher подтягивает в конвертер курсы из БД, чтобы изменение через
+// одну реплику сервиса дошло до остальных.
+type Refresher struct {
+	store     RateStore
+	converter *Converter
+	log       *zap.Logger
+	interval  time.Duration
+}
+
+func NewRefresher(store RateStore, converter *Converter, log *zap.Logger, interval time.Duration) *Refresher {
+	return &Refresher{
+		store:     store,
+		converter: converter,
+		log:       log,
+		interval:  interval,
+	}
+}
+
+// Refresh загружает курсы из БД один раз.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	rates, err := r.store.GetExchangeRates(ctx)
+	if err != nil {
+		return err
+	}
+	r.converter.Set(rates...)
+	return nil
+}
+
+// Run обновляет курсы каждые interval до отмены ctx.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error("ERROR: refresh exchange rates", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/kripst/krosovka/inventory_service/internal/auth"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func UnaryAuth(a *auth.Authenticator, log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, a, log, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuth(a *auth.Authenticator, log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, log, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, wrapStream(ss, ctx))
+	}
+}
+
+// authenticate кладёт в контекст reqctx.Identity или возвращает
+// Unauthenticated / PermissionDenied.
+func authenticate(ctx context.Context, a *auth.Authenticator, log *zap.Logger, method string) (context.Context, error) {
+	if auth.IsPublic(method) {
+		return ctx, nil
+	}
+
+	identity, role, err := a.Authenticate(ctx)
+	if err != nil {
+		log.Warn("unauthenticated request",
+			zap.String("method", method),
+			zap.String("request_id", reqctx.RequestID(ctx)),
+			zap.Error(err),
+		)
+		return ctx, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if !auth.Authorize(method, role) {
+		log.Warn("permission denied",
+			zap.String("method", method),
+			zap.String("request_id", reqctx.RequestID(ctx)),
+			zap.String("actor", identity.Subject),
+			zap.String("role", identity.Role),
+		)
+		return ctx, status.Errorf(codes.PermissionDenied, "role %s is not allowed to call %s", identity.Role, method)
+	}
+
+	return reqctx.WithIdentity(ctx, identity), nil
+}
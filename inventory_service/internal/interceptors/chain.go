@@ -0,0 +1,59 @@
+// Package interceptors содержит gRPC-перехватчики inventory_service.
+package interceptors
+
+import (
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/auth"
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"github.com/kripst/krosovka/inventory_service/internal/ratelimit"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	Log            *zap.Logger
+	RequestTimeout time.Duration
+	// Metrics может быть nil, тогда RPC-метрики не собираются
+	Metrics *metrics.Metrics
+	// Auth может быть nil, тогда аутентификация выключена
+	Auth *auth.Authenticator
+	// RateLimit может быть nil, тогда лимиты не применяются
+	RateLimit *ratelimit.Limiter
+}
+
+// ServerOptions собирает цепочку перехватчиков в порядке:
+// request ID -> метрики -> аутентификация -> access log -> recovery -> лимиты -> дедлайн по умолчанию -> обработчик.
+// Recovery стоит внутри метрик и логирования, чтобы паника попала в них с кодом Internal.
+// Аутентификация стоит до логирования, чтобы в access log попадал actor,
+// а лимиты - после неё, чтобы считать запросы по клиенту.
+func ServerOptions(cfg Config) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{UnaryRequestID()}
+	stream := []grpc.StreamServerInterceptor{StreamRequestID()}
+
+	if cfg.Metrics != nil {
+		unary = append(unary, UnaryMetrics(cfg.Metrics))
+		stream = append(stream, StreamMetrics(cfg.Metrics))
+	}
+
+	if cfg.Auth != nil {
+		unary = append(unary, UnaryAuth(cfg.Auth, cfg.Log))
+		stream = append(stream, StreamAuth(cfg.Auth, cfg.Log))
+	}
+
+	unary = append(unary, UnaryLogging(cfg.Log), UnaryRecovery(cfg.Log))
+	stream = append(stream, StreamLogging(cfg.Log), StreamRecovery(cfg.Log))
+
+	if cfg.RateLimit != nil {
+		unary = append(unary, UnaryRateLimit(cfg.RateLimit, cfg.Log))
+		stream = append(stream, StreamRateLimit(cfg.RateLimit, cfg.Log))
+	}
+
+	unary = append(unary, UnaryDeadline(cfg.RequestTimeout))
+	stream = append(stream, StreamDeadline(cfg.RequestTimeout))
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
@@ -0,0 +1,115 @@
+package interceptors_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kripst/krosovka/inventory_service/internal/interceptors"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: pb.InventoryService_GetSneakers_FullMethodName}
+
+// Паника в обработчике возвращается клиенту как codes.Internal.
+func TestUnaryRecovery(t *testing.T) {
+	require := require.New(t)
+	handler := func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	}
+
+	_, err := interceptors.UnaryRecovery(zap.NewNop())(context.Background(), nil, info, handler)
+
+	require.Equal(codes.Internal, status.Code(err))
+}
+
+func TestUnaryRequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  any
+		want string
+	}{
+		{
+			name: "из metadata",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs(interceptors.RequestIDHeader, "abc")),
+			req:  &pb.GetSneakersRequest{RequestId: 42},
+			want: "abc",
+		},
+		{
+			name: "из поля request_id",
+			ctx:  context.Background(),
+			req:  &pb.GetSneakersRequest{RequestId: 42},
+			want: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := func(ctx context.Context, req any) (any, error) {
+				got = reqctx.RequestID(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptors.UnaryRequestID()(tt.ctx, tt.req, info, handler)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Слишком длинный или с посторонними символами ID из metadata заменяется новым UUID.
+func TestUnaryRequestID_Invalid(t *testing.T) {
+	for name, requestID := range map[string]string{
+		"длиннее 64 символов": strings.Repeat("a", 65),
+		"посторонние символы": "abc\ndef",
+	} {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(interceptors.RequestIDHeader, requestID))
+			var got string
+			handler := func(ctx context.Context, req any) (any, error) {
+				got = reqctx.RequestID(ctx)
+				return nil, nil
+			}
+
+			_, err := interceptors.UnaryRequestID()(ctx, &pb.GetSneakersRequest{}, info, handler)
+
+			require.NoError(err)
+			_, err = uuid.Parse(got)
+			require.NoError(err, "got %q", got)
+		})
+	}
+}
+
+// Без дедлайна от клиента выставляется дедлайн по умолчанию, клиентский не трогается.
+func TestUnaryDeadline(t *testing.T) {
+	require := require.New(t)
+	var deadline time.Time
+	handler := func(ctx context.Context, req any) (any, error) {
+		deadline, _ = ctx.Deadline()
+		return nil, nil
+	}
+	deadlineInterceptor := interceptors.UnaryDeadline(time.Minute)
+
+	_, err := deadlineInterceptor(context.Background(), nil, info, handler)
+	require.NoError(err)
+	require.WithinDuration(time.Now().Add(time.Minute), deadline, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	_, err = deadlineInterceptor(ctx, nil, info, handler)
+	require.NoError(err)
+	require.WithinDuration(time.Now().Add(time.Hour), deadline, time.Second)
+}
@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLogging пишет access log для каждого RPC.
+func UnaryLogging(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logAccess(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamLogging(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logAccess(ss.Context(), log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func logAccess(ctx context.Context, log *zap.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.Duration("duration", time.Since(start)),
+		zap.String("code", code.String()),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+	}
+	if identity, ok := reqctx.IdentityFrom(ctx); ok {
+		fields = append(fields, zap.String("actor", identity.Subject), zap.String("role", identity.Role))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	log.Log(levelFor(code), "grpc request", fields...)
+}
+
+func levelFor(code codes.Code) zapcore.Level {
+	switch code {
+	case codes.OK:
+		return zapcore.InfoLevel
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.WarnLevel
+	}
+}
@@ -0,0 +1,34 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func UnaryMetrics(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPC(m, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func StreamMetrics(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRPC(m, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func observeRPC(m *metrics.Metrics, method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.RPCRequests.WithLabelValues(method, code).Inc()
+	m.RPCDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,90 @@
+package interceptors
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/auth"
+	"github.com/kripst/krosovka/inventory_service/internal/ratelimit"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterHeader - через сколько секунд клиенту стоит повторить запрос.
+const RetryAfterHeader = "retry-after"
+
+func UnaryRateLimit(l *ratelimit.Limiter, log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		release, err := admit(ctx, l, log, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+func StreamRateLimit(l *ratelimit.Limiter, log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := admit(ss.Context(), l, log, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, ss)
+	}
+}
+
+func admit(ctx context.Context, l *ratelimit.Limiter, log *zap.Logger, method string) (func(), error) {
+	if auth.IsPublic(method) {
+		return func() {}, nil
+	}
+
+	client := clientKey(ctx)
+	if ok, retryAfter := l.Allow(client, method); !ok {
+		log.Warn("rate limit exceeded",
+			zap.String("method", method),
+			zap.String("client", client),
+			zap.Duration("retry_after", retryAfter),
+		)
+		return nil, resourceExhausted(ctx, retryAfter, "rate limit exceeded for %s", client)
+	}
+
+	release, ok := l.Acquire()
+	if !ok {
+		log.Warn("too many in-flight requests", zap.String("method", method), zap.String("client", client))
+		return nil, resourceExhausted(ctx, ratelimit.InFlightRetryAfter, "server is overloaded")
+	}
+
+	return release, nil
+}
+
+// clientKey - аутентифицированный клиент, без аутентификации - адрес пира.
+// Пользователи из x-actor расходуют лимит своего клиента.
+func clientKey(ctx context.Context) string {
+	if identity, ok := reqctx.IdentityFrom(ctx); ok && identity.Subject != "" {
+		return identity.Subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+	return "anonymous"
+}
+
+func resourceExhausted(ctx context.Context, retryAfter time.Duration, format string, args ...any) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(RetryAfterHeader, strconv.Itoa(max(seconds, 1))))
+	return status.Errorf(codes.ResourceExhausted, format, args...)
+}
@@ -0,0 +1,45 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryRecovery превращает панику в обработчике в codes.Internal,
+// чтобы одна ошибка не роняла весь процесс.
+func UnaryRecovery(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ctx, log, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecovery(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), log, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, log *zap.Logger, method string, r any) error {
+	log.Error("ERROR: panic in grpc handler",
+		zap.String("method", method),
+		zap.String("request_id", reqctx.RequestID(ctx)),
+		zap.Any("panic", r),
+		zap.StackSkip("stack", 2),
+	)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,71 @@
+package interceptors
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader - ключ metadata с correlation ID.
+const RequestIDHeader = "x-request-id"
+
+// requestIDPattern - какой correlation ID принимается от клиента. Длина ограничена
+// столбцами request_id в sneakers_audit и order_transitions, остальные заменяются новым UUID.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// requestIDGetter реализуют все запросы InventoryService.
+type requestIDGetter interface {
+	GetRequestId() int32
+}
+
+// UnaryRequestID берёт correlation ID из metadata, затем из поля request_id,
+// иначе генерирует новый. ID кладётся в контекст и возвращается в заголовке ответа.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := requestIDFromMetadata(ctx)
+		if requestID == "" {
+			if r, ok := req.(requestIDGetter); ok && r.GetRequestId() != 0 {
+				requestID = strconv.Itoa(int(r.GetRequestId()))
+			}
+		}
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
+		return handler(reqctx.WithRequestID(ctx, requestID), req)
+	}
+}
+
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		requestID := requestIDFromMetadata(ctx)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		_ = ss.SetHeader(metadata.Pairs(RequestIDHeader, requestID))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request_id", requestID))
+		return handler(srv, wrapStream(ss, reqctx.WithRequestID(ctx, requestID)))
+	}
+}
+
+func requestIDFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(RequestIDHeader); len(values) > 0 && requestIDPattern.MatchString(values[0]) {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func New(cfg config.LoggerConfig) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+
+	zapConfig := zap.NewProductionConfig()
+	if cfg.Format == "console" {
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+	zapConfig.Level = zap.NewAtomicLevelAt(level)
+
+	return zapConfig.Build()
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const catalogScrapeTimeout = 5 * time.Second
+
+type SneakerCounter interface {
+	CountSneakers(ctx context.Context) (model.SneakerCounts, error)
+}
+
+// CatalogCollector отдаёт бизнес-метрики каталога, запрашивая БД при каждом scrape.
+type CatalogCollector struct {
+	counter SneakerCounter
+
+	sneakers    *prometheus.Desc
+	scrapeError *prometheus.Desc
+}
+
+func NewCatalogCollector(counter SneakerCounter) *CatalogCollector {
+	return &CatalogCollector{
+		counter: counter,
+		sneakers: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "catalog", "sneakers"),
+			"Количество кроссовок в каталоге по состоянию (live, deleted).",
+			[]string{"state"}, nil,
+		),
+		scrapeError: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "catalog", "scrape_error"),
+			"1, если последний подсчёт каталога завершился ошибкой.",
+			nil, nil,
+		),
+	}
+}
+
+func (c *CatalogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.sneakers
+	ch <- c.scrapeError
+}
+
+func (c *CatalogCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), catalogScrapeTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountSneakers(ctx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.scrapeError, prometheus.GaugeValue, 1)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeError, prometheus.GaugeValue, 0)
+	ch <- prometheus.MustNewConstMetric(c.sneakers, prometheus.GaugeValue, float64(counts.Live), "live")
+	ch <- prometheus.MustNewConstMetric(c.sneakers, prometheus.GaugeValue, float64(counts.Deleted), "deleted")
+}
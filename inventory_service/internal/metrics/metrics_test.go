@@ -0,0 +1,52 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStorage struct {
+	storage.Storage
+	err error
+}
+
+func (f *fakeStorage) GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) ([]model.Sneaker, error) {
+	return nil, f.err
+}
+
+func (f *fakeStorage) CountSneakers(ctx context.Context) (model.SneakerCounts, error) {
+	return model.SneakerCounts{Live: 3, Deleted: 1}, nil
+}
+
+// Обёртка хранилища пишет длительность с результатом вызова.
+func TestWrapStorage(t *testing.T) {
+	require := require.New(t)
+	m := metrics.New()
+
+	_, _ = metrics.WrapStorage(&fakeStorage{}, m).GetSneakers(context.Background(), model.SneakerFilters{}, model.Pagination{})
+	_, _ = metrics.WrapStorage(&fakeStorage{err: errors.New("db down")}, m).GetSneakers(context.Background(), model.SneakerFilters{}, model.Pagination{})
+
+	require.Equal(2, testutil.CollectAndCount(m.StorageDuration, "inventory_storage_operation_duration_seconds"))
+}
+
+func TestCatalogCollector(t *testing.T) {
+	require := require.New(t)
+	collector := metrics.NewCatalogCollector(&fakeStorage{})
+
+	expected := `
+# HELP inventory_catalog_sneakers Количество кроссовок в каталоге по состоянию (live, deleted).
+# TYPE inventory_catalog_sneakers gauge
+inventory_catalog_sneakers{state="deleted"} 1
+inventory_catalog_sneakers{state="live"} 3
+`
+
+	require.NoError(testutil.CollectAndCompare(collector, strings.NewReader(expected), "inventory_catalog_sneakers"))
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolCollector снимает pgxpool.Stat в момент scrape.
+type PoolCollector struct {
+	pool PoolStater
+
+	acquiredConns    *prometheus.Desc
+	idleConns        *prometheus.Desc
+	totalConns       *prometheus.Desc
+	maxConns         *prometheus.Desc
+	acquireCount     *prometheus.Desc
+	emptyAcquire     *prometheus.Desc
+	canceledAcquire  *prometheus.Desc
+	acquireDuration  *prometheus.Desc
+	emptyAcquireWait *prometheus.Desc
+}
+
+func NewPoolCollector(pool PoolStater) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:             pool,
+		acquiredConns:    desc("acquired_conns", "Соединения, захваченные в данный момент."),
+		idleConns:        desc("idle_conns", "Свободные соединения."),
+		totalConns:       desc("total_conns", "Все соединения пула."),
+		maxConns:         desc("max_conns", "Максимальный размер пула."),
+		acquireCount:     desc("acquire_total", "Успешные захваты соединения."),
+		emptyAcquire:     desc("empty_acquire_total", "Захваты, которым пришлось ждать свободное соединение."),
+		canceledAcquire:  desc("canceled_acquire_total", "Захваты, отменённые контекстом."),
+		acquireDuration:  desc("acquire_duration_seconds_total", "Суммарное время захвата соединений."),
+		emptyAcquireWait: desc("empty_acquire_wait_seconds_total", "Суммарное время ожидания свободного соединения."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireWait, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+}
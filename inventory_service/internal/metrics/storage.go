@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+)
+
+// instrumentedStorage замеряет время каждого метода обёрнутого хранилища.
+type instrumentedStorage struct {
+	next storage.Storage
+	m    *Metrics
+}
+
+func WrapStorage(next storage.Storage, m *Metrics) storage.Storage {
+	return &instrumentedStorage{next: next, m: m}
+}
+
+func (s *instrumentedStorage) observe(method string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	s.m.StorageDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (s *instrumentedStorage) CreateSneakers(ctx context.Context, sneakers []*model.Sneaker) (err error) {
+	defer func(start time.Time) { s.observe("CreateSneakers", start, err) }(time.Now())
+	return s.next.CreateSneakers(ctx, sneakers)
+}
+
+func (s *instrumentedStorage) UpdateSneakers(ctx context.Context, sneakers []*model.Sneaker) (err error) {
+	defer func(start time.Time) { s.observe("UpdateSneakers", start, err) }(time.Now())
+	return s.next.UpdateSneakers(ctx, sneakers)
+}
+
+func (s *instrumentedStorage) DeleteSneakers(ctx context.Context, sneakerIDs []int32) (err error) {
+	defer func(start time.Time) { s.observe("DeleteSneakers", start, err) }(time.Now())
+	return s.next.DeleteSneakers(ctx, sneakerIDs)
+}
+
+func (s *instrumentedStorage) RestoreSneakers(ctx context.Context, sneakerIDs []int32) (err error) {
+	defer func(start time.Time) { s.observe("RestoreSneakers", start, err) }(time.Now())
+	return s.next.RestoreSneakers(ctx, sneakerIDs)
+}
+
+func (s *instrumentedStorage) PurgeSneakers(ctx context.Context, sneakerIDs []int32) (err error) {
+	defer func(start time.Time) { s.observe("PurgeSneakers", start, err) }(time.Now())
+	return s.next.PurgeSneakers(ctx, sneakerIDs)
+}
+
+func (s *instrumentedStorage) GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) (sneakers []model.Sneaker, err error) {
+	defer func(start time.Time) { s.observe("GetSneakers", start, err) }(time.Now())
+	return s.next.GetSneakers(ctx, filter, pagination)
+}
+
+func (s *instrumentedStorage) GetAuditLog(ctx context.Context, filter model.AuditFilter, pagination model.Pagination) (entries []model.AuditEntry, err error) {
+	defer func(start time.Time) { s.observe("GetAuditLog", start, err) }(time.Now())
+	return s.next.GetAuditLog(ctx, filter, pagination)
+}
+
+func (s *instrumentedStorage) SchedulePriceChanges(ctx context.Context, changes []*model.PriceChange) (err error) {
+	defer func(start time.Time) { s.observe("SchedulePriceChanges", start, err) }(time.Now())
+	return s.next.SchedulePriceChanges(ctx, changes)
+}
+
+func (s *instrumentedStorage) CancelPriceChanges(ctx context.Context, priceChangeIDs []int64) (err error) {
+	defer func(start time.Time) { s.observe("CancelPriceChanges", start, err) }(time.Now())
+	return s.next.CancelPriceChanges(ctx, priceChangeIDs)
+}
+
+func (s *instrumentedStorage) ApplyPriceChanges(ctx context.Context, now time.Time) (run model.PriceChangeRun, err error) {
+	defer func(start time.Time) { s.observe("ApplyPriceChanges", start, err) }(time.Now())
+	return s.next.ApplyPriceChanges(ctx, now)
+}
+
+func (s *instrumentedStorage) GetPriceHistory(ctx context.Context, filter model.PriceHistoryFilter, pagination model.Pagination) (entries []model.PriceHistoryEntry, err error) {
+	defer func(start time.Time) { s.observe("GetPriceHistory", start, err) }(time.Now())
+	return s.next.GetPriceHistory(ctx, filter, pagination)
+}
+
+func (s *instrumentedStorage) SaveExchangeRates(ctx context.Context, rates []model.ExchangeRate) (err error) {
+	defer func(start time.Time) { s.observe("SaveExchangeRates", start, err) }(time.Now())
+	return s.next.SaveExchangeRates(ctx, rates)
+}
+
+func (s *instrumentedStorage) GetExchangeRates(ctx context.Context) (rates []model.ExchangeRate, err error) {
+	defer func(start time.Time) { s.observe("GetExchangeRates", start, err) }(time.Now())
+	return s.next.GetExchangeRates(ctx)
+}
+
+func (s *instrumentedStorage) CreateOrder(ctx context.Context, order *model.Order) (err error) {
+	defer func(start time.Time) { s.observe("CreateOrder", start, err) }(time.Now())
+	return s.next.CreateOrder(ctx, order)
+}
+
+func (s *instrumentedStorage) GetOrder(ctx context.Context, orderID int64) (order model.Order, err error) {
+	defer func(start time.Time) { s.observe("GetOrder", start, err) }(time.Now())
+	return s.next.GetOrder(ctx, orderID)
+}
+
+func (s *instrumentedStorage) ListOrders(ctx context.Context, filter model.OrderFilter, pagination model.Pagination) (orders []model.Order, err error) {
+	defer func(start time.Time) { s.observe("ListOrders", start, err) }(time.Now())
+	return s.next.ListOrders(ctx, filter, pagination)
+}
+
+func (s *instrumentedStorage) TransitionOrder(ctx context.Context, change model.OrderStatusChange) (order model.Order, err error) {
+	defer func(start time.Time) { s.observe("TransitionOrder", start, err) }(time.Now())
+	return s.next.TransitionOrder(ctx, change)
+}
+
+func (s *instrumentedStorage) Ping(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("Ping", start, err) }(time.Now())
+	return s.next.Ping(ctx)
+}
+
+func (s *instrumentedStorage) Close() error {
+	return s.next.Close()
+}
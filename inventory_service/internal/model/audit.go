@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditEntry - одна запись журнала изменений каталога.
+// Before и After - снимки строки sneakers в JSON, nil если строки не было.
+type AuditEntry struct {
+	ID        int64           `json:"id" db:"id"`
+	SneakerID int32           `json:"sneaker_id" db:"sneaker_id"`
+	Article   string          `json:"article" db:"article"`
+	Action    AuditAction     `json:"action" db:"action"`
+	Actor     string          `json:"actor" db:"actor"`
+	RequestID string          `json:"request_id" db:"request_id"`
+	Before    json.RawMessage `json:"before,omitempty" db:"snapshot_before"`
+	After     json.RawMessage `json:"after,omitempty" db:"snapshot_after"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter - условия выборки журнала, нулевые значения не фильтруют.
+type AuditFilter struct {
+	SneakerID int32
+	Article   string
+	Actor     string
+	From      time.Time
+	To        time.Time
+}
+
+func (e *AuditEntry) ToGrpc() *pb.AuditEntry {
+	return &pb.AuditEntry{
+		AuditId:    e.ID,
+		SneakerId:  e.SneakerID,
+		Article:    e.Article,
+		Action:     string(e.Action),
+		Actor:      e.Actor,
+		RequestId:  e.RequestID,
+		BeforeJson: string(e.Before),
+		AfterJson:  string(e.After),
+		CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+)
+
+// SneakerFilters - условия выборки кроссовок, нулевые значения не фильтруют.
+// Фильтр по цене оставляет только товары в валюте MinPrice/MaxPrice.
+// Фильтр по размеру находит равные размеры в любой системе по таблицам брендов.
+type SneakerFilters struct {
+	IDs      []int32
+	Articles []string
+	Brand    string
+	Name     string
+	MinPrice *money.Money
+	MaxPrice *money.Money
+	Size     *sizing.Size
+	// UpdatedSince - только изменённые с этого момента, выборка идёт по порядку изменений
+	UpdatedSince *time.Time
+	// UpdatedAfterID - вместе с UpdatedSince продолжает выборку после строки
+	// (UpdatedSince, UpdatedAfterID): страницы изменений без OFFSET не теряют строк
+	UpdatedAfterID int32
+	// OnlyDeleted - только мягко удалённые записи, по умолчанию они не возвращаются
+	OnlyDeleted bool
+}
+
+type Pagination struct {
+	Limit  int
+	Offset int
+}
+
+// SneakerCounts - размер каталога с учётом мягкого удаления.
+type SneakerCounts struct {
+	Live    int64
+	Deleted int64
+}
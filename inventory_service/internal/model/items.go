@@ -3,8 +3,11 @@ package model
 import (
 	"time"
 
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
 	pb "github.com/kripst/krosovka/inventory_service/proto"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 type Sneaker struct {
@@ -12,13 +15,20 @@ type Sneaker struct {
 	Article            string    `json:"article" db:"article"`
 	SneakerName        string    `json:"sneaker_name" db:"sneaker_name"`
 	SneakerDescription string    `json:"sneaker_description,omitempty" db:"sneaker_description"`
-	Price              float64   `json:"price" db:"price"`
+	Price              decimal.Decimal `json:"price" db:"price"`
+	Currency           money.Currency  `json:"currency" db:"currency"`
 	Size               float64   `json:"size" db:"size"`
+	SizeSystem         sizing.System `json:"size_system" db:"size_system"`
+	// Picture - ссылка на фото или file_id Telegram из sneakers_pictures
+	Picture            string    `json:"picture,omitempty" db:"picture"`
 	Brand              string    `json:"brand" db:"brand"`
 	ProductionAddress  string    `json:"production_address,omitempty" db:"production_address"`
 	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
-	DeletedAt          time.Time `json:"deleted_at" db:"deleted_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// ScheduledPrice - цена запланированного изменения, действующая сейчас
+	ScheduledPrice     *decimal.Decimal `json:"scheduled_price,omitempty" db:"scheduled_price"`
+	ScheduledEndsAt    *time.Time `json:"scheduled_ends_at,omitempty" db:"scheduled_ends_at"`
 }
 
 func (s *Sneaker) FromGrpc(in *pb.Sneaker) error {
@@ -33,10 +43,75 @@ func (s *Sneaker) FromGrpc(in *pb.Sneaker) error {
     s.Article = in.GetArticle()
     s.SneakerName = in.GetSneakerName()
     s.SneakerDescription = in.GetSneakerDescription()
-    s.Price = in.GetPrice()
-    s.Size = float64(in.GetSize())
+    price, err := money.FromProto(in.GetPrice())
+    if err != nil {
+        return errors.Wrap(err, "price")
+    }
+    if price.HasSubunitRemainder() {
+        return errors.Errorf("price %s is more precise than %s allows", price.Amount, price.Currency)
+    }
+    s.Price = price.Amount
+    s.Currency = price.Currency
     s.Brand = in.GetBrand()
+    system, err := sizing.ParseSystem(in.GetSizeSystem())
+    if err != nil {
+        return errors.Wrap(err, "size_system")
+    }
+    size := sizing.New(system, float64(in.GetSize()))
+    if err := sizing.ChartFor(s.Brand).Validate(size); err != nil {
+        return errors.Wrap(err, "size")
+    }
+    s.Size = size.Value
+    s.SizeSystem = size.System
     s.ProductionAddress = in.GetProductionAddress()
+    s.Picture = in.GetPicture()
 
     return nil
-}
\ No newline at end of file
+}
+
+// SizeValue - размер товара в его системе.
+func (s *Sneaker) SizeValue() sizing.Size {
+	return sizing.New(s.SizeSystem, s.Size)
+}
+
+// BasePrice - базовая цена товара без запланированных изменений.
+func (s *Sneaker) BasePrice() money.Money {
+	return money.New(s.Price, s.Currency)
+}
+
+// EffectivePrice - цена, по которой товар продаётся сейчас.
+// Запланированная цена всегда в валюте товара.
+func (s *Sneaker) EffectivePrice() money.Money {
+	if s.ScheduledPrice != nil {
+		return money.New(*s.ScheduledPrice, s.Currency)
+	}
+	return s.BasePrice()
+}
+
+func (s *Sneaker) ToGrpc() *pb.Sneaker {
+	out := &pb.Sneaker{
+		SneakerId:          s.ID,
+		Article:            s.Article,
+		SneakerName:        s.SneakerName,
+		SneakerDescription: s.SneakerDescription,
+		Price:              s.BasePrice().ToProto(),
+		Size:               float32(s.Size),
+		SizeSystem:         string(s.SizeSystem),
+		Brand:              s.Brand,
+		ProductionAddress:  s.ProductionAddress,
+		CreatedAt:          s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          s.UpdatedAt.Format(time.RFC3339Nano),
+		EffectivePrice:     s.EffectivePrice().ToProto(),
+		Picture:            s.Picture,
+	}
+	if s.ScheduledEndsAt != nil {
+		out.EffectivePriceEndsAt = s.ScheduledEndsAt.Format(time.RFC3339)
+	}
+	// Размеры, которых нет в таблице бренда, отдаются без эквивалентов
+	if sizes, err := sizing.ChartFor(s.Brand).Equivalents(s.SizeValue()); err == nil {
+		for _, size := range sizes {
+			out.SizeEquivalents = append(out.SizeEquivalents, &pb.SneakerSize{System: string(size.System), Value: float32(size.Value)})
+		}
+	}
+	return out
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MaxOrderLines - сколько разных артикулов может быть в одном заказе
+	MaxOrderLines = 50
+	// MaxLineQuantity - сколько пар одного артикула можно заказать
+	MaxLineQuantity = 100
+)
+
+var (
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrInvalidTransition - переход запрещён из текущего статуса заказа
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	// ErrOrderConflict - заказ расходится с каталогом: товара нет, другой размер или цена
+	ErrOrderConflict = errors.New("order conflicts with the catalog")
+)
+
+type OrderStatus string
+
+const (
+	OrderCreated   OrderStatus = "created"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+	OrderReturned  OrderStatus = "returned"
+)
+
+// orderTransitions - из какого статуса в какие можно перейти.
+// Отменить можно до отправки, вернуть - отправленный или доставленный заказ.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderCreated:   {OrderPaid, OrderCancelled},
+	OrderPaid:      {OrderShipped, OrderCancelled},
+	OrderShipped:   {OrderDelivered, OrderReturned},
+	OrderDelivered: {OrderReturned},
+	OrderCancelled: nil,
+	OrderReturned:  nil,
+}
+
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	status := OrderStatus(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := orderTransitions[status]; !ok {
+		return "", fmt.Errorf("unknown order status %q", s)
+	}
+	return status, nil
+}
+
+// CanTransition сообщает, разрешён ли переход s -> to.
+func (s OrderStatus) CanTransition(to OrderStatus) bool {
+	for _, next := range orderTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Final - из статуса больше нет переходов.
+func (s OrderStatus) Final() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+// CheckTransition проверяет переход s -> to с понятной ошибкой.
+func (s OrderStatus) CheckTransition(to OrderStatus) error {
+	if !s.CanTransition(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, s, to)
+	}
+	return nil
+}
+
+// Customer - покупатель. ID задаёт клиент сервиса, по нему ищутся заказы.
+type Customer struct {
+	ID      string `json:"customer_id" db:"customer_id"`
+	Name    string `json:"customer_name" db:"customer_name"`
+	Phone   string `json:"customer_phone" db:"customer_phone"`
+	Address string `json:"customer_address" db:"customer_address"`
+}
+
+// OrderLine - артикул в размере по цене на момент заказа.
+type OrderLine struct {
+	SneakerID   int32           `json:"sneaker_id" db:"sneaker_id"`
+	Article     string          `json:"article" db:"article"`
+	SneakerName string          `json:"sneaker_name" db:"sneaker_name"`
+	Size        float64         `json:"size" db:"size"`
+	SizeSystem  sizing.System   `json:"size_system" db:"size_system"`
+	Quantity    int32           `json:"quantity" db:"quantity"`
+	Price       decimal.Decimal `json:"price" db:"price"`
+	// ExpectedPrice - цена, которую видел покупатель; nil - согласен на текущую
+	ExpectedPrice *money.Money `json:"-" db:"-"`
+}
+
+// Total - стоимость позиции.
+func (l *OrderLine) Total() decimal.Decimal {
+	return l.Price.Mul(decimal.NewFromInt32(l.Quantity))
+}
+
+// SameSize сравнивает размер с точностью колонки DECIMAL(3, 1), см. sizing.New.
+func (l *OrderLine) SameSize(size float64, system sizing.System) bool {
+	return sizing.New(l.SizeSystem, l.Size) == sizing.New(system, size)
+}
+
+// OrderTransition - запись истории статусов. From пуст у записи о создании.
+type OrderTransition struct {
+	From      OrderStatus `json:"from_status" db:"from_status"`
+	To        OrderStatus `json:"to_status" db:"to_status"`
+	Reason    string      `json:"reason" db:"reason"`
+	Actor     string      `json:"actor" db:"actor"`
+	RequestID string      `json:"request_id" db:"request_id"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
+type Order struct {
+	ID        int64           `json:"id" db:"id"`
+	Customer  Customer        `json:"customer" db:"-"`
+	Lines     []OrderLine     `json:"lines" db:"-"`
+	Total     decimal.Decimal `json:"total" db:"total"`
+	Currency  money.Currency  `json:"currency" db:"currency"`
+	Status    OrderStatus     `json:"status" db:"status"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+	// History заполняется только GetOrder, от старых записей к новым
+	History []OrderTransition `json:"history,omitempty" db:"-"`
+}
+
+// TotalMoney - сумма заказа в его валюте.
+func (o *Order) TotalMoney() money.Money {
+	return money.New(o.Total, o.Currency)
+}
+
+// OrderFilter - выборка заказов покупателя, пустой Status не фильтрует.
+type OrderFilter struct {
+	CustomerID string
+	Status     OrderStatus
+}
+
+// OrderStatusChange - запрос перехода заказа в статус To.
+type OrderStatusChange struct {
+	OrderID int64
+	To      OrderStatus
+	// From - ожидаемый текущий статус, пустой - любой, из которого переход разрешён
+	From   OrderStatus
+	Reason string
+}
+
+// FromGrpc разбирает запрос создания. Цены, названия и ID товаров заполняет хранилище.
+func (o *Order) FromGrpc(in *pb.CreateOrderRequest) error {
+	if o == nil {
+		return errors.New("nil struct")
+	}
+	if in == nil {
+		return errors.New("nil request")
+	}
+
+	o.Customer = Customer{
+		ID:      strings.TrimSpace(in.GetCustomer().GetCustomerId()),
+		Name:    strings.TrimSpace(in.GetCustomer().GetName()),
+		Phone:   strings.TrimSpace(in.GetCustomer().GetPhone()),
+		Address: strings.TrimSpace(in.GetCustomer().GetAddress()),
+	}
+
+	o.Lines = make([]OrderLine, 0, len(in.GetLines()))
+	for i, pbLine := range in.GetLines() {
+		system, err := sizing.ParseSystem(pbLine.GetSizeSystem())
+		if err != nil {
+			return fmt.Errorf("lines[%d].size_system: %w", i, err)
+		}
+		size := sizing.New(system, float64(pbLine.GetSize()))
+		line := OrderLine{
+			Article:    strings.TrimSpace(pbLine.GetArticle()),
+			Size:       size.Value,
+			SizeSystem: size.System,
+			Quantity:   pbLine.GetQuantity(),
+		}
+		if pbLine.GetPrice() != nil {
+			price, err := money.FromProto(pbLine.GetPrice())
+			if err != nil {
+				return fmt.Errorf("lines[%d].price: %w", i, err)
+			}
+			line.ExpectedPrice = &price
+		}
+		o.Lines = append(o.Lines, line)
+	}
+
+	return nil
+}
+
+// Validate проверяет заказ перед созданием.
+func (o *Order) Validate() error {
+	if o.Customer.ID == "" {
+		return errors.New("customer.customer_id is required")
+	}
+	if len(o.Customer.ID) > 64 {
+		return errors.New("customer.customer_id is longer than 64 characters")
+	}
+	if o.Customer.Name == "" {
+		return errors.New("customer.name is required")
+	}
+	if len(o.Customer.Name) > 255 {
+		return errors.New("customer.name is longer than 255 characters")
+	}
+	if len(o.Customer.Phone) > 32 {
+		return errors.New("customer.phone is longer than 32 characters")
+	}
+	if len(o.Lines) == 0 {
+		return errors.New("lines is empty")
+	}
+	if len(o.Lines) > MaxOrderLines {
+		return fmt.Errorf("at most %d lines per order, got %d", MaxOrderLines, len(o.Lines))
+	}
+
+	seen := make(map[string]bool, len(o.Lines))
+	var currency money.Currency
+	for i, line := range o.Lines {
+		if line.Article == "" {
+			return fmt.Errorf("lines[%d]: article is required", i)
+		}
+		if seen[line.Article] {
+			return fmt.Errorf("lines[%d]: article %s is repeated, use quantity", i, line.Article)
+		}
+		seen[line.Article] = true
+		if line.Size <= 0 {
+			return fmt.Errorf("lines[%d]: size must be positive", i)
+		}
+		if line.Quantity <= 0 || line.Quantity > MaxLineQuantity {
+			return fmt.Errorf("lines[%d]: quantity must be in [1, %d], got %d", i, MaxLineQuantity, line.Quantity)
+		}
+		if line.ExpectedPrice == nil {
+			continue
+		}
+		if !line.ExpectedPrice.IsPositive() {
+			return fmt.Errorf("lines[%d]: price must be positive, got %s", i, line.ExpectedPrice)
+		}
+		if currency != "" && line.ExpectedPrice.Currency != currency {
+			return fmt.Errorf("lines[%d]: %w: all lines must be in %s", i, money.ErrCurrencyMismatch, currency)
+		}
+		currency = line.ExpectedPrice.Currency
+	}
+	return nil
+}
+
+// ToGrpc - заказ для ответа, история добавляется, если загружена.
+func (o *Order) ToGrpc() *pb.Order {
+	out := &pb.Order{
+		OrderId: o.ID,
+		Customer: &pb.Customer{
+			CustomerId: o.Customer.ID,
+			Name:       o.Customer.Name,
+			Phone:      o.Customer.Phone,
+			Address:    o.Customer.Address,
+		},
+		Total:     o.TotalMoney().ToProto(),
+		Status:    string(o.Status),
+		CreatedAt: o.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: o.UpdatedAt.Format(time.RFC3339),
+	}
+
+	out.Lines = make([]*pb.OrderLine, 0, len(o.Lines))
+	for _, line := range o.Lines {
+		out.Lines = append(out.Lines, &pb.OrderLine{
+			Article:     line.Article,
+			Size:        float32(line.Size),
+			SizeSystem:  string(line.SizeSystem),
+			Quantity:    line.Quantity,
+			Price:       money.New(line.Price, o.Currency).ToProto(),
+			SneakerId:   line.SneakerID,
+			SneakerName: line.SneakerName,
+		})
+	}
+
+	for _, entry := range o.History {
+		out.History = append(out.History, &pb.OrderTransition{
+			FromStatus: string(entry.From),
+			ToStatus:   string(entry.To),
+			Reason:     entry.Reason,
+			Actor:      entry.Actor,
+			CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return out
+}
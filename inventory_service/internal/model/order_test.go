@@ -0,0 +1,121 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// Заказ проходит created -> paid -> shipped -> delivered, отменяется до отправки
+// и возвращается после неё; из cancelled и returned переходов нет.
+func TestOrderStatus_CanTransition(t *testing.T) {
+	tests := []struct {
+		from, to model.OrderStatus
+		want     bool
+	}{
+		{model.OrderCreated, model.OrderPaid, true},
+		{model.OrderPaid, model.OrderShipped, true},
+		{model.OrderShipped, model.OrderDelivered, true},
+		{model.OrderCreated, model.OrderCancelled, true},
+		{model.OrderPaid, model.OrderCancelled, true},
+		{model.OrderShipped, model.OrderReturned, true},
+		{model.OrderDelivered, model.OrderReturned, true},
+		{model.OrderCreated, model.OrderShipped, false},
+		{model.OrderCreated, model.OrderDelivered, false},
+		{model.OrderPaid, model.OrderCreated, false},
+		{model.OrderShipped, model.OrderCancelled, false},
+		{model.OrderDelivered, model.OrderCancelled, false},
+		{model.OrderCancelled, model.OrderPaid, false},
+		{model.OrderReturned, model.OrderShipped, false},
+		{model.OrderPaid, model.OrderPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			require.Equal(t, tt.want, tt.from.CanTransition(tt.to))
+			if tt.want {
+				require.NoError(t, tt.from.CheckTransition(tt.to))
+			} else {
+				require.ErrorIs(t, tt.from.CheckTransition(tt.to), model.ErrInvalidTransition)
+			}
+		})
+	}
+
+	require.True(t, model.OrderCancelled.Final())
+	require.True(t, model.OrderReturned.Final())
+	require.False(t, model.OrderDelivered.Final())
+}
+
+func TestParseOrderStatus(t *testing.T) {
+	require := require.New(t)
+
+	status, err := model.ParseOrderStatus(" Paid ")
+	require.NoError(err)
+	require.Equal(model.OrderPaid, status)
+
+	_, err = model.ParseOrderStatus("refunded")
+	require.Error(err)
+}
+
+func TestOrder_Validate(t *testing.T) {
+	customer := model.Customer{ID: "tg:7", Name: "Иван"}
+	line := func(article string, quantity int32) model.OrderLine {
+		return model.OrderLine{Article: article, Size: 42.5, SizeSystem: "EU", Quantity: quantity}
+	}
+	priced := func(article, amount, currency string) model.OrderLine {
+		l := line(article, 1)
+		price := money.MustParse(amount, currency)
+		l.ExpectedPrice = &price
+		return l
+	}
+
+	tests := []struct {
+		name    string
+		order   model.Order
+		wantErr bool
+	}{
+		{"две позиции", model.Order{Customer: customer, Lines: []model.OrderLine{line("A", 1), line("B", 2)}}, false},
+		{"с ценами", model.Order{Customer: customer, Lines: []model.OrderLine{priced("A", "9990.50", "RUB"), priced("B", "100", "RUB")}}, false},
+		{"без покупателя", model.Order{Customer: model.Customer{Name: "Иван"}, Lines: []model.OrderLine{line("A", 1)}}, true},
+		{"без имени", model.Order{Customer: model.Customer{ID: "tg:7"}, Lines: []model.OrderLine{line("A", 1)}}, true},
+		{"без позиций", model.Order{Customer: customer}, true},
+		{"повтор артикула", model.Order{Customer: customer, Lines: []model.OrderLine{line("A", 1), line("A", 1)}}, true},
+		{"нулевое количество", model.Order{Customer: customer, Lines: []model.OrderLine{line("A", 0)}}, true},
+		{"слишком много пар", model.Order{Customer: customer, Lines: []model.OrderLine{line("A", model.MaxLineQuantity+1)}}, true},
+		{"разные валюты", model.Order{Customer: customer, Lines: []model.OrderLine{priced("A", "100", "RUB"), priced("B", "10", "USD")}}, true},
+		{"нулевая цена", model.Order{Customer: customer, Lines: []model.OrderLine{priced("A", "0", "RUB")}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.order.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+// Размер из proto нормализуется до десятых и сравнивается с размером товара.
+func TestOrder_FromGrpc(t *testing.T) {
+	require := require.New(t)
+	order := &model.Order{}
+
+	err := order.FromGrpc(&pb.CreateOrderRequest{
+		Customer: &pb.Customer{CustomerId: " tg:7 ", Name: "Иван"},
+		Lines: []*pb.OrderLine{{Article: "ART-001", Size: 42.7, SizeSystem: "eu", Quantity: 1,
+			Price: &pb.Money{CurrencyCode: "RUB", Units: 9990}}},
+	})
+
+	require.NoError(err)
+	require.Equal("tg:7", order.Customer.ID)
+	require.Len(order.Lines, 1)
+	require.True(order.Lines[0].SameSize(42.7, "EU"))
+	require.False(order.Lines[0].SameSize(42.7, "UK"))
+	require.Equal("9990.00 RUB", order.Lines[0].ExpectedPrice.String())
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+type PriceChangeStatus string
+
+const (
+	PriceChangeScheduled PriceChangeStatus = "scheduled"
+	PriceChangeActive    PriceChangeStatus = "active"
+	PriceChangeCompleted PriceChangeStatus = "completed"
+	PriceChangeCancelled PriceChangeStatus = "cancelled"
+	// PriceChangeExpired - период закончился раньше, чем воркер успел применить цену
+	PriceChangeExpired PriceChangeStatus = "expired"
+)
+
+// PriceChange - запланированная цена на период [StartsAt, EndsAt).
+// Задаётся либо фиксированная цена Price в валюте товара, либо скидка
+// DiscountPercent от базовой.
+type PriceChange struct {
+	ID              int64             `json:"id" db:"id"`
+	SneakerID       int32             `json:"sneaker_id" db:"sneaker_id"`
+	Price           *money.Money      `json:"price,omitempty" db:"-"`
+	DiscountPercent *float64          `json:"discount_percent,omitempty" db:"discount_percent"`
+	StartsAt        time.Time         `json:"starts_at" db:"starts_at"`
+	EndsAt          time.Time         `json:"ends_at" db:"ends_at"`
+	Status          PriceChangeStatus `json:"status" db:"status"`
+	CreatedBy       string            `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+}
+
+func (c *PriceChange) FromGrpc(in *pb.PriceChange) error {
+	if c == nil {
+		return errors.New("nil struct")
+	}
+	if in == nil {
+		return errors.New("nil request")
+	}
+
+	c.SneakerID = in.GetSneakerId()
+	if in.GetPrice() != nil {
+		price, err := money.FromProto(in.GetPrice())
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+		c.Price = &price
+	}
+	if in.GetDiscountPercent() != 0 {
+		percent := in.GetDiscountPercent()
+		c.DiscountPercent = &percent
+	}
+
+	var err error
+	if c.StartsAt, err = time.Parse(time.RFC3339, in.GetStartsAt()); err != nil {
+		return fmt.Errorf("starts_at: %w", err)
+	}
+	if c.EndsAt, err = time.Parse(time.RFC3339, in.GetEndsAt()); err != nil {
+		return fmt.Errorf("ends_at: %w", err)
+	}
+
+	return nil
+}
+
+// Validate проверяет изменение перед планированием, now - текущее время.
+func (c *PriceChange) Validate(now time.Time) error {
+	if c.SneakerID <= 0 {
+		return errors.New("sneaker_id is required")
+	}
+	if (c.Price == nil) == (c.DiscountPercent == nil) {
+		return errors.New("exactly one of price and discount_percent must be set")
+	}
+	if c.Price != nil && !c.Price.IsPositive() {
+		return fmt.Errorf("price must be positive, got %s", c.Price)
+	}
+	if c.Price != nil && c.Price.HasSubunitRemainder() {
+		return fmt.Errorf("price %s is more precise than %s allows", c.Price.Amount, c.Price.Currency)
+	}
+	if c.DiscountPercent != nil && (*c.DiscountPercent <= 0 || *c.DiscountPercent >= 100) {
+		return fmt.Errorf("discount_percent must be in (0, 100), got %v", *c.DiscountPercent)
+	}
+	if !c.EndsAt.After(c.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	if !c.EndsAt.After(now) {
+		return errors.New("ends_at is in the past")
+	}
+	return nil
+}
+
+func (c *PriceChange) ToGrpc() *pb.PriceChange {
+	out := &pb.PriceChange{
+		PriceChangeId: c.ID,
+		SneakerId:     c.SneakerID,
+		StartsAt:      c.StartsAt.Format(time.RFC3339),
+		EndsAt:        c.EndsAt.Format(time.RFC3339),
+		Status:        string(c.Status),
+		CreatedBy:     c.CreatedBy,
+		CreatedAt:     c.CreatedAt.Format(time.RFC3339),
+	}
+	if c.Price != nil {
+		out.Price = c.Price.ToProto()
+	}
+	if c.DiscountPercent != nil {
+		out.DiscountPercent = *c.DiscountPercent
+	}
+	return out
+}
+
+// PriceHistoryEntry - точка истории цены товара.
+type PriceHistoryEntry struct {
+	ID             int64           `json:"id" db:"id"`
+	SneakerID      int32           `json:"sneaker_id" db:"sneaker_id"`
+	Price          decimal.Decimal `json:"price" db:"price"`
+	EffectivePrice decimal.Decimal `json:"effective_price" db:"effective_price"`
+	Currency       money.Currency  `json:"currency" db:"currency"`
+	PriceChangeID  *int64          `json:"price_change_id,omitempty" db:"price_change_id"`
+	ChangedAt      time.Time       `json:"changed_at" db:"changed_at"`
+}
+
+func (e *PriceHistoryEntry) ToGrpc() *pb.PriceHistoryEntry {
+	out := &pb.PriceHistoryEntry{
+		Id:             e.ID,
+		SneakerId:      e.SneakerID,
+		Price:          money.New(e.Price, e.Currency).ToProto(),
+		EffectivePrice: money.New(e.EffectivePrice, e.Currency).ToProto(),
+		ChangedAt:      e.ChangedAt.Format(time.RFC3339),
+	}
+	if e.PriceChangeID != nil {
+		out.PriceChangeId = *e.PriceChangeID
+	}
+	return out
+}
+
+type PriceHistoryFilter struct {
+	SneakerID int32
+	From      time.Time
+	To        time.Time
+}
+
+// PriceChangeRun - итог одного прохода воркера цен.
+type PriceChangeRun struct {
+	Applied  int64
+	Reverted int64
+	Expired  int64
+}
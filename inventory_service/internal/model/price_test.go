@@ -0,0 +1,87 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceChange_Validate(t *testing.T) {
+	now := time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC)
+	friday := time.Date(2025, 6, 13, 0, 0, 0, 0, time.UTC)
+	sunday := time.Date(2025, 6, 15, 23, 59, 0, 0, time.UTC)
+	price, zero := money.MustParse("4999", "RUB"), money.MustParse("0", "RUB")
+	percent := 20.0
+
+	tests := []struct {
+		name    string
+		change  model.PriceChange
+		wantErr bool
+	}{
+		{"скидка на выходные", model.PriceChange{SneakerID: 1, DiscountPercent: &percent, StartsAt: friday, EndsAt: sunday}, false},
+		{"фиксированная цена", model.PriceChange{SneakerID: 1, Price: &price, StartsAt: friday, EndsAt: sunday}, false},
+		{"цена и скидка одновременно", model.PriceChange{SneakerID: 1, Price: &price, DiscountPercent: &percent, StartsAt: friday, EndsAt: sunday}, true},
+		{"ни цены, ни скидки", model.PriceChange{SneakerID: 1, StartsAt: friday, EndsAt: sunday}, true},
+		{"нулевая цена", model.PriceChange{SneakerID: 1, Price: &zero, StartsAt: friday, EndsAt: sunday}, true},
+		{"конец раньше начала", model.PriceChange{SneakerID: 1, Price: &price, StartsAt: sunday, EndsAt: friday}, true},
+		{"период в прошлом", model.PriceChange{SneakerID: 1, Price: &price, StartsAt: now.Add(-2 * time.Hour), EndsAt: now.Add(-time.Hour)}, true},
+		{"точнее копейки", model.PriceChange{SneakerID: 1, Price: ptr(money.MustParse("4999.999", "RUB")), StartsAt: friday, EndsAt: sunday}, true},
+		{"без товара", model.PriceChange{Price: &price, StartsAt: friday, EndsAt: sunday}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change.Validate(now)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSneaker_ToGrpcEffectivePrice(t *testing.T) {
+	require := require.New(t)
+
+	// --- Arrange ---
+	salePrice := decimal.RequireFromString("7999.20")
+	endsAt := time.Date(2025, 6, 15, 23, 59, 0, 0, time.UTC)
+	base := decimal.RequireFromString("9999")
+	sneaker := model.Sneaker{ID: 1, Price: base, Currency: money.RUB, ScheduledPrice: &salePrice, ScheduledEndsAt: &endsAt}
+
+	// --- Act ---
+	onSale := sneaker.ToGrpc()
+	regular := (&model.Sneaker{ID: 2, Price: base, Currency: money.RUB}).ToGrpc()
+
+	// --- Assert ---
+	require.Equal(int64(9999), onSale.GetPrice().GetUnits())
+	require.Equal("RUB", onSale.GetPrice().GetCurrencyCode())
+	require.Equal(int64(7999), onSale.GetEffectivePrice().GetUnits())
+	require.Equal(int32(200_000_000), onSale.GetEffectivePrice().GetNanos())
+	require.Equal("2025-06-15T23:59:00Z", onSale.GetEffectivePriceEndsAt())
+	require.Equal(int64(9999), regular.GetEffectivePrice().GetUnits())
+	require.Empty(regular.GetEffectivePriceEndsAt())
+}
+
+// Сумма дробнее копейки отклоняется, а не округляется колонкой NUMERIC(10,2).
+func TestSneaker_FromGrpcRejectsSubunitPrice(t *testing.T) {
+	require := require.New(t)
+
+	var s model.Sneaker
+	err := s.FromGrpc(&pb.Sneaker{Size: 42, SizeSystem: "EU", Price: &pb.Money{CurrencyCode: "RUB", Units: 10, Nanos: 1_000_000}})
+	require.Error(err)
+
+	err = s.FromGrpc(&pb.Sneaker{Size: 42, SizeSystem: "EU", Price: &pb.Money{CurrencyCode: "RUB", Units: 10, Nanos: 990_000_000}})
+	require.NoError(err)
+	require.Equal("10.99 RUB", s.BasePrice().String())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
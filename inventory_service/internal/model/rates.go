@@ -0,0 +1,75 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+// rateScale - число знаков после запятой в колонке NUMERIC(20, 10)
+const rateScale = 10
+
+// ExchangeRate - курс: 1 единица Base стоит Rate единиц Quote.
+type ExchangeRate struct {
+	Base      money.Currency  `json:"base_currency" db:"base_currency"`
+	Quote     money.Currency  `json:"quote_currency" db:"quote_currency"`
+	Rate      decimal.Decimal `json:"rate" db:"rate"`
+	Source    string          `json:"source" db:"source"` // file | admin
+	UpdatedBy string          `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+func (r *ExchangeRate) FromGrpc(in *pb.ExchangeRate) error {
+	if r == nil {
+		return errors.New("nil struct")
+	}
+	if in == nil {
+		return errors.New("nil request")
+	}
+
+	var err error
+	if r.Base, err = money.ParseCurrency(in.GetBaseCurrency()); err != nil {
+		return fmt.Errorf("base_currency: %w", err)
+	}
+	if r.Quote, err = money.ParseCurrency(in.GetQuoteCurrency()); err != nil {
+		return fmt.Errorf("quote_currency: %w", err)
+	}
+	if r.Rate, err = decimal.NewFromString(in.GetRate()); err != nil {
+		return fmt.Errorf("rate: %w", err)
+	}
+	if in.GetUpdatedAt() != "" {
+		if r.UpdatedAt, err = time.Parse(time.RFC3339, in.GetUpdatedAt()); err != nil {
+			return fmt.Errorf("updated_at: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ExchangeRate) Validate() error {
+	if r.Base == r.Quote {
+		return fmt.Errorf("base and quote currency are both %s", r.Base)
+	}
+	if !r.Rate.IsPositive() {
+		return fmt.Errorf("rate %s->%s must be positive, got %s", r.Base, r.Quote, r.Rate)
+	}
+	if !r.Rate.Equal(r.Rate.Round(rateScale)) {
+		return fmt.Errorf("rate %s->%s has more than %d fractional digits", r.Base, r.Quote, rateScale)
+	}
+	return nil
+}
+
+func (r *ExchangeRate) ToGrpc() *pb.ExchangeRate {
+	return &pb.ExchangeRate{
+		BaseCurrency:  string(r.Base),
+		QuoteCurrency: string(r.Quote),
+		Rate:          r.Rate.String(),
+		Source:        r.Source,
+		UpdatedBy:     r.UpdatedBy,
+		UpdatedAt:     r.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,147 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrCurrencyMismatch - операция над суммами в разных валютах. Суммы
+// в разных валютах сравниваются только после явной конвертации.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Currency - код валюты ISO 4217 (RUB, USD).
+type Currency string
+
+const (
+	RUB Currency = "RUB"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	KZT Currency = "KZT"
+)
+
+// minorUnits - число знаков после запятой для валют, где их не два.
+var minorUnits = map[Currency]int32{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+// ParseCurrency проверяет, что code похож на код ISO 4217: три заглавные латинские буквы.
+func ParseCurrency(code string) (Currency, error) {
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q", code)
+		}
+	}
+	return Currency(code), nil
+}
+
+// MinorUnits - сколько знаков после запятой у валюты (копейки, центы).
+func (c Currency) MinorUnits() int32 {
+	if units, ok := minorUnits[c]; ok {
+		return units
+	}
+	return 2
+}
+
+// Money - сумма в валюте. Amount хранится в десятичном виде без потерь,
+// как NUMERIC в PostgreSQL.
+type Money struct {
+	Amount   decimal.Decimal
+	Currency Currency
+}
+
+func New(amount decimal.Decimal, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse разбирает сумму из строки ("4999.99") и код валюты.
+func Parse(amount, currency string) (Money, error) {
+	c, err := ParseCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	d, err := decimal.NewFromString(amount)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+	return New(d, c), nil
+}
+
+// MustParse - Parse для констант в тестах и конфиге по умолчанию.
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount.IsPositive()
+}
+
+// Cmp сравнивает суммы одной валюты: -1, 0 или 1.
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return m.Amount.Cmp(other.Amount), nil
+}
+
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount.Equal(other.Amount)
+}
+
+// Round округляет до минимальной единицы валюты, половина - от нуля
+// (4999.995 RUB -> 5000.00 RUB).
+func (m Money) Round() Money {
+	return New(m.Amount.Round(m.Currency.MinorUnits()), m.Currency)
+}
+
+// HasSubunitRemainder - есть ли знаки дробнее минимальной единицы валюты.
+func (m Money) HasSubunitRemainder() bool {
+	return !m.Amount.Equal(m.Amount.Round(m.Currency.MinorUnits()))
+}
+
+// String - "4999.99 RUB" с числом знаков по валюте.
+func (m Money) String() string {
+	return m.Amount.StringFixed(m.Currency.MinorUnits()) + " " + string(m.Currency)
+}
+
+const nanosPerUnit = 1_000_000_000
+
+var nanosFactor = decimal.New(1, 9)
+
+// FromUnitsNanos собирает сумму из целой части и миллиардных долей, как в google.type.Money.
+// nanos в диапазоне [-999999999, 999999999] и того же знака, что units.
+func FromUnitsNanos(units int64, nanos int32, currency Currency) (Money, error) {
+	if nanos <= -nanosPerUnit || nanos >= nanosPerUnit {
+		return Money{}, fmt.Errorf("nanos %d out of range", nanos)
+	}
+	if (units > 0 && nanos < 0) || (units < 0 && nanos > 0) {
+		return Money{}, fmt.Errorf("units %d and nanos %d have different signs", units, nanos)
+	}
+	amount := decimal.NewFromInt(units).Add(decimal.New(int64(nanos), -9))
+	return New(amount, currency), nil
+}
+
+// UnitsNanos раскладывает сумму на целую часть и миллиардные доли.
+// Знаки дробнее 1e-9 округляются половиной от нуля. Суммы из NUMERIC(10,2)
+// заведомо помещаются в int64.
+func (m Money) UnitsNanos() (int64, int32) {
+	amount := m.Amount.Round(9)
+	units := amount.Truncate(0)
+	nanos := amount.Sub(units).Mul(nanosFactor)
+	return units.IntPart(), int32(nanos.IntPart())
+}
@@ -0,0 +1,82 @@
+package money_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	tests := []struct {
+		amount string
+		units  int64
+		nanos  int32
+	}{
+		{"4999.99", 4999, 990_000_000},
+		{"0.01", 0, 10_000_000},
+		{"-12.5", -12, -500_000_000},
+		{"100", 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			require := require.New(t)
+			m := money.MustParse(tt.amount, "RUB")
+
+			out := m.ToProto()
+			require.Equal(tt.units, out.GetUnits())
+			require.Equal(tt.nanos, out.GetNanos())
+			require.Equal("RUB", out.GetCurrencyCode())
+
+			back, err := money.FromProto(out)
+			require.NoError(err)
+			require.True(back.Equal(m), "%s != %s", back, m)
+		})
+	}
+}
+
+func TestFromProto_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *pb.Money
+	}{
+		{"нет суммы", nil},
+		{"нет валюты", &pb.Money{Units: 10}},
+		{"валюта в нижнем регистре", &pb.Money{CurrencyCode: "rub", Units: 10}},
+		{"nanos вне диапазона", &pb.Money{CurrencyCode: "RUB", Units: 1, Nanos: 1_000_000_000}},
+		{"разные знаки", &pb.Money{CurrencyCode: "RUB", Units: 1, Nanos: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := money.FromProto(tt.in)
+			require.Error(t, err)
+		})
+	}
+}
+
+// Суммы в разных валютах не сравниваются без явной конвертации.
+func TestCmp_CurrencyMismatch(t *testing.T) {
+	require := require.New(t)
+
+	cmp, err := money.MustParse("100", "RUB").Cmp(money.MustParse("99.99", "RUB"))
+	require.NoError(err)
+	require.Equal(1, cmp)
+
+	_, err = money.MustParse("100", "RUB").Cmp(money.MustParse("1", "USD"))
+	require.True(errors.Is(err, money.ErrCurrencyMismatch))
+	require.False(money.MustParse("1", "RUB").Equal(money.MustParse("1", "USD")))
+}
+
+func TestRound(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal("5000.00 RUB", money.MustParse("4999.995", "RUB").Round().String())
+	require.Equal("-1.01 USD", money.MustParse("-1.005", "USD").Round().String())
+	require.Equal("1235 JPY", money.MustParse("1234.5", "JPY").Round().String())
+	require.True(money.MustParse("10.001", "RUB").HasSubunitRemainder())
+	require.False(money.MustParse("10.10", "RUB").HasSubunitRemainder())
+}
@@ -0,0 +1,29 @@
+package money
+
+import (
+	"errors"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+// FromProto переводит pb.Money в Money. Код валюты обязателен.
+func FromProto(in *pb.Money) (Money, error) {
+	if in == nil {
+		return Money{}, errors.New("money is not set")
+	}
+	currency, err := ParseCurrency(in.GetCurrencyCode())
+	if err != nil {
+		return Money{}, err
+	}
+	return FromUnitsNanos(in.GetUnits(), in.GetNanos(), currency)
+}
+
+// ToProto переводит сумму в pb.Money по правилам UnitsNanos.
+func (m Money) ToProto() *pb.Money {
+	units, nanos := m.UnitsNanos()
+	return &pb.Money{
+		CurrencyCode: string(m.Currency),
+		Units:        units,
+		Nanos:        nanos,
+	}
+}
@@ -0,0 +1,66 @@
+package pricing
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"go.uber.org/zap"
+)
+
+// Applier - часть хранилища, которая нужна воркеру.
+type Applier interface {
+	ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error)
+}
+
+// Worker периодически применяет наступившие изменения цен и откатывает
+// закончившиеся. Точность переключения цены - interval.
+type Worker struct {
+	s        Applier
+	log      *zap.Logger
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewWorker(s Applier, log *zap.Logger, interval time.Duration) *Worker {
+	return &Worker{
+		s:        s,
+		log:      log,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Run делает первый проход сразу и дальше каждые interval до отмены ctx.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) tick(ctx context.Context) {
+	run, err := w.s.ApplyPriceChanges(ctx, w.now())
+	if err != nil {
+		if ctx.Err() == nil {
+			w.log.Error("ERROR: apply price changes", zap.Error(err))
+		}
+		return
+	}
+
+	if run != (model.PriceChangeRun{}) {
+		w.log.Info("price changes processed",
+			zap.Int64("applied", run.Applied),
+			zap.Int64("reverted", run.Reverted),
+			zap.Int64("expired", run.Expired),
+		)
+	}
+}
@@ -0,0 +1,50 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/pricing"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeApplier struct {
+	calls atomic.Int32
+	err   error
+}
+
+func (f *fakeApplier) ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error) {
+	f.calls.Add(1)
+	return model.PriceChangeRun{Applied: 1}, f.err
+}
+
+// Первый проход выполняется сразу, затем по тикеру, ошибки не останавливают воркер.
+func TestWorker_RunsUntilCancelled(t *testing.T) {
+	require := require.New(t)
+
+	// --- Arrange ---
+	applier := &fakeApplier{err: errors.New("db down")}
+	worker := pricing.NewWorker(applier, zap.NewNop(), 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// --- Act ---
+	done := make(chan struct{})
+	go func() {
+		worker.Run(ctx)
+		close(done)
+	}()
+
+	// --- Assert ---
+	require.Eventually(func() bool { return applier.calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("воркер не остановился после отмены контекста")
+	}
+}
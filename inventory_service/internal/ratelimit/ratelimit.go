@@ -0,0 +1,117 @@
+// Package ratelimit ограничивает частоту запросов клиентов и число одновременных RPC.
+package ratelimit
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"golang.org/x/time/rate"
+)
+
+// idleTTL - через сколько неактивный клиент вытесняется из памяти.
+const idleTTL = 10 * time.Minute
+
+// InFlightRetryAfter - подсказка клиенту при превышении предела одновременных RPC.
+const InFlightRetryAfter = time.Second
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type Limiter struct {
+	cfg config.RateLimitConfig
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	lastGC  time.Time
+
+	inFlight chan struct{}
+}
+
+// New создаёт лимитер; maxInFlight <= 0 отключает предел одновременных RPC.
+func New(cfg config.RateLimitConfig, maxInFlight int) *Limiter {
+	l := &Limiter{
+		cfg:     cfg,
+		buckets: make(map[string]*bucket),
+		lastGC:  time.Now(),
+	}
+	if maxInFlight > 0 {
+		l.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// Allow проверяет лимит клиента и лимит метода для клиента. При отказе
+// возвращает, через сколько стоит повторить запрос.
+func (l *Limiter) Allow(client, fullMethod string) (bool, time.Duration) {
+	now := time.Now()
+	method := path.Base(fullMethod)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gc(now)
+
+	limiters := []*rate.Limiter{
+		l.bucket("client:"+client, l.cfg.ClientRPS, l.cfg.ClientBurst, now),
+	}
+	if limit, ok := l.cfg.Methods[method]; ok {
+		limiters = append(limiters, l.bucket("method:"+client+":"+method, limit.RPS, limit.Burst, now))
+	}
+
+	// Резервируем токены во всех корзинах и откатываем, если хоть одна пуста,
+	// чтобы отказ по методу не тратил общий лимит клиента.
+	reservations := make([]*rate.Reservation, 0, len(limiters))
+	var wait time.Duration
+	for _, limiter := range limiters {
+		r := limiter.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		wait = max(wait, r.DelayFrom(now))
+	}
+	if wait == 0 {
+		return true, 0
+	}
+
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+	return false, wait
+}
+
+// Acquire занимает слот одновременного RPC. release нужно вызвать по завершении.
+func (l *Limiter) Acquire() (release func(), ok bool) {
+	if l.inFlight == nil {
+		return func() {}, true
+	}
+
+	select {
+	case l.inFlight <- struct{}{}:
+		return func() { <-l.inFlight }, true
+	default:
+		return nil, false
+	}
+}
+
+func (l *Limiter) bucket(key string, rps float64, burst int, now time.Time) *rate.Limiter {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(rps), burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
+
+func (l *Limiter) gc(now time.Time) {
+	if now.Sub(l.lastGC) < idleTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastGC = now
+}
@@ -0,0 +1,60 @@
+package ratelimit_test
+
+import (
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/ratelimit"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllow_ClientBucket(t *testing.T) {
+	require := require.New(t)
+	l := ratelimit.New(config.RateLimitConfig{ClientRPS: 1, ClientBurst: 2}, 0)
+
+	ok1, _ := l.Allow("import-script", pb.InventoryService_GetSneakers_FullMethodName)
+	ok2, _ := l.Allow("import-script", pb.InventoryService_GetSneakers_FullMethodName)
+	ok3, retryAfter := l.Allow("import-script", pb.InventoryService_GetSneakers_FullMethodName)
+	okOther, _ := l.Allow("tg_bot", pb.InventoryService_GetSneakers_FullMethodName)
+
+	require.True(ok1)
+	require.True(ok2)
+	require.False(ok3, "burst исчерпан")
+	require.Greater(retryAfter.Seconds(), 0.0)
+	require.True(okOther, "лимит одного клиента не влияет на другого")
+}
+
+// Отказ по лимиту метода не расходует общий лимит клиента.
+func TestAllow_MethodBucket(t *testing.T) {
+	require := require.New(t)
+	l := ratelimit.New(config.RateLimitConfig{
+		ClientRPS:   1,
+		ClientBurst: 3,
+		Methods:     map[string]config.MethodLimitConfig{"CreateSneakers": {RPS: 1, Burst: 1}},
+	}, 0)
+
+	okCreate, _ := l.Allow("import-script", pb.InventoryService_CreateSneakers_FullMethodName)
+	okCreateAgain, _ := l.Allow("import-script", pb.InventoryService_CreateSneakers_FullMethodName)
+	okGet1, _ := l.Allow("import-script", pb.InventoryService_GetSneakers_FullMethodName)
+	okGet2, _ := l.Allow("import-script", pb.InventoryService_GetSneakers_FullMethodName)
+
+	require.True(okCreate)
+	require.False(okCreateAgain)
+	require.True(okGet1)
+	require.True(okGet2)
+}
+
+func TestAcquire(t *testing.T) {
+	require := require.New(t)
+	l := ratelimit.New(config.RateLimitConfig{ClientRPS: 1, ClientBurst: 1}, 1)
+
+	release, ok := l.Acquire()
+	require.True(ok)
+	_, ok = l.Acquire()
+	require.False(ok, "предел одновременных RPC исчерпан")
+
+	release()
+	_, ok = l.Acquire()
+	require.True(ok)
+}
@@ -0,0 +1,52 @@
+// Package reqctx хранит сквозные значения запроса в context.Context.
+package reqctx
+
+import "context"
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	identityKey
+)
+
+// Identity - аутентифицированный клиент, выполняющий запрос.
+type Identity struct {
+	Subject    string
+	Role       string
+	AuthMethod string // api_key | jwt
+	// OnBehalfOf - пользователь клиента из metadata x-actor, если ключу это разрешено
+	OnBehalfOf string
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID возвращает correlation ID запроса или пустую строку.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(Identity)
+	return identity, ok
+}
+
+// Actor - кто выполняет запрос, для логов и аудита: клиент, а если он действует
+// от имени пользователя - "<клиент>/<пользователь>".
+func Actor(ctx context.Context) string {
+	identity, ok := IdentityFrom(ctx)
+	switch {
+	case !ok:
+		return ""
+	case identity.OnBehalfOf != "":
+		return identity.Subject + "/" + identity.OnBehalfOf
+	}
+	return identity.Subject
+}
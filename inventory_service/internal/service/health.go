@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.uber.org/zap"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthServices - "" означает состояние сервера целиком.
+var healthServices = []string{"", pb.InventoryService_ServiceDesc.ServiceName, pb.OrdersService_ServiceDesc.ServiceName}
+
+// watchHealth периодически пингует хранилище и выставляет статус
+// grpc.health.v1 до отмены ctx.
+func (s *Server) watchHealth(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.GRPC.HealthCheckInterval)
+	defer ticker.Stop()
+
+	serving := false
+	for {
+		pingCtx, cancel := context.WithTimeout(ctx, s.cfg.GRPC.HealthCheckInterval)
+		err := s.storage.Ping(pingCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil && serving:
+			s.log.Warn("storage ping failed, marking NOT_SERVING", zap.Error(err))
+		case err == nil && !serving:
+			s.log.Info("storage is available, marking SERVING")
+		}
+		serving = err == nil
+		s.setServingStatus(serving)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) setServingStatus(serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+
+	for _, service := range healthServices {
+		s.health.SetServingStatus(service, status)
+	}
+}
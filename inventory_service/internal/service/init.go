@@ -1,11 +1,221 @@
 package service
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/auth"
+	"github.com/kripst/krosovka/inventory_service/internal/interceptors"
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"github.com/kripst/krosovka/inventory_service/internal/pricing"
+	"github.com/kripst/krosovka/inventory_service/internal/ratelimit"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
 type Server struct {
-	//TODO add interfaces
-	Storage string
-	
+	cfg     *config.Config
+	log     *zap.Logger
+	storage storage.Storage
+	grpc    *grpc.Server
+	health  *health.Server
+	metrics *metrics.Metrics
+	// background - дополнительные фоновые задачи, см. AddBackground
+	background []func(ctx context.Context)
+}
+
+// NewServer собирает gRPC-сервер с сервисами каталога и заказов. m может быть
+// nil, если метрики не нужны.
+func NewServer(cfg *config.Config, log *zap.Logger, s storage.Storage, api pb.InventoryServiceServer, orders pb.OrdersServiceServer, m *metrics.Metrics) (*Server, error) {
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		var err error
+		authenticator, err = auth.NewAuthenticator(cfg.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to init auth: %w", err)
+		}
+	} else {
+		log.Warn("authentication is disabled, every client has admin access")
+	}
+
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		// Предел одновременных RPC считается от размера пула, чтобы массовый
+		// импорт не занимал все соединения и не вытеснял остальных клиентов
+		limiter = ratelimit.New(cfg.RateLimit, cfg.EffectiveMaxInFlight())
+	}
+
+	opts := interceptors.ServerOptions(interceptors.Config{
+		Log:            log,
+		RequestTimeout: cfg.Timeouts.Request,
+		Metrics:        m,
+		Auth:           authenticator,
+		RateLimit:      limiter,
+	})
+	opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	grpcServer := grpc.NewServer(opts...)
+	pb.RegisterInventoryServiceServer(grpcServer, api)
+	pb.RegisterOrdersServiceServer(grpcServer, orders)
+
+	// До первой успешной проверки БД сервер не готов принимать трафик
+	healthServer := health.NewServer()
+	for _, service := range healthServices {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	if cfg.GRPC.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	return &Server{
+		cfg:     cfg,
+		log:     log,
+		storage: s,
+		grpc:    grpcServer,
+		health:  healthServer,
+		metrics: m,
+	}, nil
+}
+
+// AddBackground регистрирует фоновую задачу, которая работает, пока сервер
+// обслуживает запросы. run должна вернуться после отмены ctx; хранилище
+// закрывается только после этого. Вызывать до Run/Serve.
+func (s *Server) AddBackground(run func(ctx context.Context)) {
+	s.background = append(s.background, run)
+}
+
+// Run слушает cfg.GRPC.Addr до отмены ctx.
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.cfg.GRPC.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen %s: %w", s.cfg.GRPC.Addr, err)
+	}
+
+	return s.Serve(ctx, lis)
+}
+
+// Serve обслуживает lis до отмены ctx, затем дожидается завершения текущих RPC
+// (не дольше cfg.Timeouts.Shutdown) и только после этого закрывает хранилище.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.grpc.Serve(lis)
+	}()
+	s.log.Info("grpc server started", zap.String("addr", lis.Addr().String()))
+
+	stopMetrics, err := s.serveMetrics()
+	if err != nil {
+		s.grpc.Stop()
+		return errors.Join(err, s.storage.Close())
+	}
+	defer stopMetrics()
+
+	// Фоновые задачи останавливаются до закрытия хранилища
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	var background sync.WaitGroup
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		s.watchHealth(backgroundCtx)
+	}()
+	if s.cfg.PriceWorker.Enabled {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			pricing.NewWorker(s.storage, s.log, s.cfg.PriceWorker.Interval).Run(backgroundCtx)
+		}()
+	}
+	for _, run := range s.background {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			run(backgroundCtx)
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
+		stopBackground()
+		background.Wait()
+		return errors.Join(fmt.Errorf("grpc serve: %w", err), s.storage.Close())
+	case <-ctx.Done():
+	}
+
+	// Сначала снимаем готовность, чтобы балансировщик перестал слать трафик
+	stopBackground()
+	background.Wait()
+	s.health.Shutdown()
+
+	s.log.Info("shutting down grpc server", zap.Duration("deadline", s.cfg.Timeouts.Shutdown))
+	s.gracefulStop()
+
+	if err := s.storage.Close(); err != nil {
+		return fmt.Errorf("failed to close storage: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) gracefulStop() {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(s.cfg.Timeouts.Shutdown)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		s.log.Info("grpc server stopped gracefully")
+	case <-timer.C:
+		s.log.Warn("graceful stop deadline exceeded, cancelling in-flight RPCs")
+		s.grpc.Stop()
+		<-stopped
+	}
 }
 
-func NewServer() {
-	
-}
\ No newline at end of file
+// serveMetrics поднимает HTTP /metrics рядом с gRPC и возвращает функцию остановки.
+func (s *Server) serveMetrics() (func(), error) {
+	if s.metrics == nil || !s.cfg.Metrics.Enabled {
+		return func() {}, nil
+	}
+
+	lis, err := net.Listen("tcp", s.cfg.Metrics.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen metrics %s: %w", s.cfg.Metrics.Addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.metrics.Handler())
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := httpServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.log.Error("ERROR: metrics server", zap.Error(err))
+		}
+	}()
+	s.log.Info("metrics server started", zap.String("addr", lis.Addr().String()))
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			s.log.Warn("metrics server shutdown", zap.Error(err))
+		}
+	}, nil
+}
@@ -0,0 +1,285 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/api"
+	"github.com/kripst/krosovka/inventory_service/config"
+	"github.com/kripst/krosovka/inventory_service/internal/metrics"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/service"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type fakeStorage struct {
+	sneakers []model.Sneaker
+	pingErr  atomic.Pointer[error]
+	closed   chan struct{}
+
+	mu     sync.Mutex
+	orders map[int64]model.Order
+}
+
+func newFakeStorage(sneakers ...model.Sneaker) *fakeStorage {
+	return &fakeStorage{sneakers: sneakers, closed: make(chan struct{}), orders: make(map[int64]model.Order)}
+}
+
+func (f *fakeStorage) CreateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
+	return nil
+}
+
+func (f *fakeStorage) UpdateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
+	return nil
+}
+
+func (f *fakeStorage) DeleteSneakers(ctx context.Context, sneakerIDs []int32) error {
+	return nil
+}
+
+func (f *fakeStorage) RestoreSneakers(ctx context.Context, sneakerIDs []int32) error {
+	return nil
+}
+
+func (f *fakeStorage) PurgeSneakers(ctx context.Context, sneakerIDs []int32) error {
+	return nil
+}
+
+func (f *fakeStorage) GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) ([]model.Sneaker, error) {
+	return f.sneakers, nil
+}
+
+func (f *fakeStorage) GetAuditLog(ctx context.Context, filter model.AuditFilter, pagination model.Pagination) ([]model.AuditEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeStorage) SchedulePriceChanges(ctx context.Context, changes []*model.PriceChange) error {
+	return nil
+}
+
+func (f *fakeStorage) CancelPriceChanges(ctx context.Context, priceChangeIDs []int64) error {
+	return nil
+}
+
+func (f *fakeStorage) ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error) {
+	return model.PriceChangeRun{}, nil
+}
+
+func (f *fakeStorage) GetPriceHistory(ctx context.Context, filter model.PriceHistoryFilter, pagination model.Pagination) ([]model.PriceHistoryEntry, error) {
+	return nil, nil
+}
+
+func (f *fakeStorage) SaveExchangeRates(ctx context.Context, rates []model.ExchangeRate) error {
+	return nil
+}
+
+func (f *fakeStorage) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	return nil, nil
+}
+
+// CreateOrder принимает цены покупателя как есть, сверку с каталогом делает только PostgreSQL.
+func (f *fakeStorage) CreateOrder(ctx context.Context, order *model.Order) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order.ID = int64(len(f.orders) + 1)
+	order.Status = model.OrderCreated
+	order.Total = decimal.Zero
+	for i := range order.Lines {
+		order.Lines[i].Price = order.Lines[i].ExpectedPrice.Amount
+		order.Currency = order.Lines[i].ExpectedPrice.Currency
+		order.Total = order.Total.Add(order.Lines[i].Total())
+	}
+	f.orders[order.ID] = *order
+	return nil
+}
+
+func (f *fakeStorage) GetOrder(ctx context.Context, orderID int64) (model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[orderID]
+	if !ok {
+		return model.Order{}, model.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (f *fakeStorage) ListOrders(ctx context.Context, filter model.OrderFilter, pagination model.Pagination) ([]model.Order, error) {
+	return nil, nil
+}
+
+func (f *fakeStorage) TransitionOrder(ctx context.Context, change model.OrderStatusChange) (model.Order, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	order, ok := f.orders[change.OrderID]
+	if !ok {
+		return model.Order{}, model.ErrOrderNotFound
+	}
+	if err := order.Status.CheckTransition(change.To); err != nil {
+		return model.Order{}, err
+	}
+	order.Status = change.To
+	f.orders[order.ID] = order
+	return order, nil
+}
+
+func (f *fakeStorage) Ping(ctx context.Context) error {
+	if err := f.pingErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func (f *fakeStorage) Close() error {
+	close(f.closed)
+	return nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		StorageConfig: &config.StorageConfig{},
+		GRPC:          config.GRPCConfig{Addr: "127.0.0.1:0", HealthCheckInterval: 10 * time.Millisecond},
+		Timeouts:      config.TimeoutsConfig{Request: time.Second, Shutdown: time.Second},
+	}
+}
+
+// startServer поднимает сервер на случайном порту и возвращает клиента к нему.
+func startServer(t *testing.T, storage *fakeStorage) (*grpc.ClientConn, context.CancelFunc, <-chan error) {
+	t.Helper()
+	log := zap.NewNop()
+	server, err := service.NewServer(testConfig(), log, storage,
+		api.NewApiServerImpl(storage, log, nil), api.NewOrdersServerImpl(storage, log), metrics.New())
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, lis)
+	}()
+	t.Cleanup(cancel)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, cancel, done
+}
+
+// Сервер отвечает на RPC, а после отмены контекста останавливается и закрывает хранилище.
+func TestServer_ServeAndShutdown(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	storage := newFakeStorage(model.Sneaker{ID: 1, Article: "ART-001", SneakerName: "Runner Pro", Price: decimal.NewFromInt(150), Currency: money.RUB, Brand: "Nike"})
+	conn, cancel, done := startServer(t, storage)
+	client := pb.NewInventoryServiceClient(conn)
+
+	// --- Act ---
+	resp, err := client.GetSneakers(context.Background(), &pb.GetSneakersRequest{RequestId: 7})
+	cancel()
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal(int32(7), resp.GetRequestId())
+	require.Len(resp.GetSneakers(), 1)
+	require.Equal("ART-001", resp.GetSneakers()[0].GetArticle())
+
+	select {
+	case err := <-done:
+		require.NoError(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+
+	select {
+	case <-storage.closed:
+	default:
+		t.Fatal("storage was not closed")
+	}
+}
+
+// Статус health следует за доступностью хранилища.
+func TestServer_HealthFollowsStorage(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	storage := newFakeStorage()
+	conn, _, _ := startServer(t, storage)
+	health := healthpb.NewHealthClient(conn)
+	service := pb.InventoryService_ServiceDesc.ServiceName
+
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN
+		}
+		return resp.GetStatus()
+	}
+
+	// --- Act & Assert ---
+	require.Eventually(func() bool {
+		return status() == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	pingErr := errors.New("connection refused")
+	storage.pingErr.Store(&pingErr)
+	require.Eventually(func() bool {
+		return status() == healthpb.HealthCheckResponse_NOT_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// Сервис заказов зарегистрирован рядом с каталогом и переводит ошибки хранилища в коды ответа.
+func TestServer_Orders(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	storage := newFakeStorage()
+	conn, _, _ := startServer(t, storage)
+	client := pb.NewOrdersServiceClient(conn)
+	ctx := context.Background()
+	customer := &pb.Customer{CustomerId: "tg:7", Name: "Иван", Phone: "+79991234567"}
+	line := &pb.OrderLine{Article: "ART-001", Size: 42.5, SizeSystem: "EU", Quantity: 2,
+		Price: &pb.Money{CurrencyCode: "RUB", Units: 9990, Nanos: 500_000_000}}
+
+	// --- Act ---
+	invalid, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{Customer: customer})
+	require.NoError(err)
+	created, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{RequestId: 3, Customer: customer, Lines: []*pb.OrderLine{line}})
+	require.NoError(err)
+	orderID := created.GetOrder().GetOrderId()
+	skipped, err := client.TransitionOrder(ctx, &pb.TransitionOrderRequest{OrderId: orderID, Status: "shipped"})
+	require.NoError(err)
+	paid, err := client.TransitionOrder(ctx, &pb.TransitionOrderRequest{OrderId: orderID, Status: "paid", Reason: "invoice 1"})
+	require.NoError(err)
+	missing, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: 999})
+	require.NoError(err)
+
+	// --- Assert ---
+	require.Equal(int32(http.StatusBadRequest), invalid.GetStatusCode())
+	require.Equal("lines is empty", invalid.GetErrorMessage())
+
+	require.Equal(int32(http.StatusOK), created.GetStatusCode(), created.GetErrorMessage())
+	require.Equal(int32(3), created.GetRequestId())
+	require.Equal("created", created.GetOrder().GetStatus())
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 19981}, created.GetOrder().GetTotal())
+
+	require.Equal(int32(http.StatusConflict), skipped.GetStatusCode())
+	require.Contains(skipped.GetErrorMessage(), "created -> shipped")
+
+	require.Equal(int32(http.StatusOK), paid.GetStatusCode(), paid.GetErrorMessage())
+	require.Equal("paid", paid.GetOrder().GetStatus())
+
+	require.Equal(int32(http.StatusNotFound), missing.GetStatusCode())
+}
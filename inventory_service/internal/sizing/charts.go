@@ -0,0 +1,83 @@
+package sizing
+
+// Таблицы по официальным размерным сеткам брендов, колонки: EU, US_M, US_W, UK, CM.
+// Дробные EU у adidas (42 2/3) хранятся с точностью колонки DECIMAL(3,1).
+
+var nikeChart = &Chart{
+	Name:   "nike",
+	Brands: []string{"nike", "jordan"},
+	rows: []row{
+		{35.5, 3.5, 5, 3, 22.5},
+		{36, 4, 5.5, 3.5, 23},
+		{36.5, 4.5, 6, 4, 23.5},
+		{37.5, 5, 6.5, 4.5, 23.5},
+		{38, 5.5, 7, 5, 24},
+		{38.5, 6, 7.5, 5.5, 24},
+		{39, 6.5, 8, 6, 24.5},
+		{40, 7, 8.5, 6, 25},
+		{40.5, 7.5, 9, 6.5, 25.5},
+		{41, 8, 9.5, 7, 26},
+		{42, 8.5, 10, 7.5, 26.5},
+		{42.5, 9, 10.5, 8, 27},
+		{43, 9.5, 11, 8.5, 27.5},
+		{44, 10, 11.5, 9, 28},
+		{44.5, 10.5, 12, 9.5, 28.5},
+		{45, 11, 12.5, 10, 29},
+		{45.5, 11.5, 13, 10.5, 29.5},
+		{46, 12, 13.5, 11, 30},
+		{47, 12.5, 14, 11.5, 30.5},
+		{47.5, 13, 14.5, 12, 31},
+		{48.5, 14, 15.5, 13, 32},
+		{49.5, 15, 16.5, 14, 33},
+	},
+}
+
+var adidasChart = &Chart{
+	Name:   "adidas",
+	Brands: []string{"adidas"},
+	rows: []row{
+		{36.7, 4.5, 5.5, 4, 22.9},
+		{37.3, 5, 6, 4.5, 23.3},
+		{38, 5.5, 6.5, 5, 23.8},
+		{38.7, 6, 7, 5.5, 24.2},
+		{39.3, 6.5, 7.5, 6, 24.6},
+		{40, 7, 8, 6.5, 25.1},
+		{40.7, 7.5, 8.5, 7, 25.5},
+		{41.3, 8, 9, 7.5, 25.9},
+		{42, 8.5, 9.5, 8, 26.4},
+		{42.7, 9, 10, 8.5, 26.8},
+		{43.3, 9.5, 10.5, 9, 27.2},
+		{44, 10, 11, 9.5, 27.7},
+		{44.7, 10.5, 11.5, 10, 28.1},
+		{45.3, 11, 12, 10.5, 28.5},
+		{46, 11.5, 12.5, 11, 29},
+		{46.7, 12, 13, 11.5, 29.4},
+		{47.3, 12.5, 13.5, 12, 29.8},
+		{48, 13, 14, 12.5, 30.3},
+		{48.7, 13.5, 14.5, 13, 30.7},
+	},
+}
+
+// brandCharts - бренды со своими сетками
+var brandCharts = []*Chart{nikeChart, adidasChart}
+
+// genericChart - усреднённая сетка для брендов без своей таблицы
+var genericChart = &Chart{
+	Name: "generic",
+	rows: []row{
+		{35, 3.5, 5, 2.5, 22},
+		{36, 4.5, 6, 3.5, 22.5},
+		{37, 5, 6.5, 4, 23.5},
+		{38, 6, 7.5, 5, 24},
+		{39, 6.5, 8, 6, 25},
+		{40, 7, 8.5, 6.5, 25.5},
+		{41, 8, 9.5, 7, 26},
+		{42, 8.5, 10, 8, 27},
+		{43, 9.5, 11, 9, 27.5},
+		{44, 10, 11.5, 9.5, 28},
+		{45, 11, 12.5, 10.5, 29},
+		{46, 12, 13.5, 11, 30},
+		{47, 13, 14.5, 12, 30.5},
+		{48, 14, 15.5, 13, 31.5},
+	},
+}
@@ -0,0 +1,180 @@
+package sizing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	// ErrUnknownSize - размера нет в таблице бренда для этой системы.
+	ErrUnknownSize = errors.New("size is not in the size chart")
+	// ErrAmbiguousSize - размер без системы или совпадающий с несколькими
+	// строками таблицы (UK 6 у Nike - это и US 6.5, и US 7).
+	ErrAmbiguousSize = errors.New("size is ambiguous")
+)
+
+// System - система размеров.
+type System string
+
+const (
+	EU      System = "EU"
+	USMen   System = "US_M"
+	USWomen System = "US_W"
+	UK      System = "UK"
+	CM      System = "CM"
+)
+
+// Systems - все системы в порядке колонок таблиц.
+var Systems = []System{EU, USMen, USWomen, UK, CM}
+
+func ParseSystem(s string) (System, error) {
+	if s == "" {
+		return "", fmt.Errorf("%w: size system is required", ErrAmbiguousSize)
+	}
+	for _, system := range Systems {
+		if strings.EqualFold(s, string(system)) {
+			return system, nil
+		}
+	}
+	return "", fmt.Errorf("unknown size system %q", s)
+}
+
+// Size - размер в конкретной системе.
+type Size struct {
+	System System
+	Value  float64
+}
+
+// New нормализует value до десятых, как в колонке DECIMAL(3,1).
+// Значения из proto приходят float32, и 42.7 без округления не равно 42.7 из таблицы.
+func New(system System, value float64) Size {
+	return Size{System: system, Value: math.Round(value*10) / 10}
+}
+
+func (s Size) String() string {
+	return fmt.Sprintf("%s %g", s.System, s.Value)
+}
+
+func (s Size) column() int {
+	for i, system := range Systems {
+		if system == s.System {
+			return i
+		}
+	}
+	return -1
+}
+
+// row - одна строка таблицы, значения в порядке Systems.
+type row [5]float64
+
+// Chart - таблица соответствия размеров бренда.
+type Chart struct {
+	Name string
+	// Brands - бренды (в нижнем регистре), к которым применяется таблица;
+	// пусто у общей таблицы для остальных брендов
+	Brands []string
+	rows   []row
+}
+
+func (c *Chart) matches(size Size) ([]row, error) {
+	column := size.column()
+	if column < 0 {
+		return nil, fmt.Errorf("unknown size system %q", size.System)
+	}
+
+	var found []row
+	for _, r := range c.rows {
+		if math.Abs(r[column]-size.Value) < 0.05 {
+			found = append(found, r)
+		}
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%w: %s for %s", ErrUnknownSize, size, c.Name)
+	}
+	return found, nil
+}
+
+// Validate проверяет, что размер однозначно определяет строку таблицы.
+func (c *Chart) Validate(size Size) error {
+	found, err := c.matches(size)
+	if err != nil {
+		return err
+	}
+	if len(found) > 1 {
+		return fmt.Errorf("%w: %s matches %d rows of the %s chart", ErrAmbiguousSize, size, len(found), c.Name)
+	}
+	return nil
+}
+
+// Convert переводит размер в систему to.
+func (c *Chart) Convert(size Size, to System) (Size, error) {
+	if err := c.Validate(size); err != nil {
+		return Size{}, err
+	}
+	found, _ := c.matches(size)
+	return New(to, found[0][Size{System: to}.column()]), nil
+}
+
+// Equivalents - размер во всех системах, включая исходную.
+func (c *Chart) Equivalents(size Size) ([]Size, error) {
+	if err := c.Validate(size); err != nil {
+		return nil, err
+	}
+	found, _ := c.matches(size)
+	sizes := make([]Size, 0, len(Systems))
+	for i, system := range Systems {
+		sizes = append(sizes, New(system, found[0][i]))
+	}
+	return sizes, nil
+}
+
+// Matching - все размеры, которые могут означать size, для фильтров.
+// В отличие от Equivalents неоднозначный размер не ошибка: подходят все его строки.
+func (c *Chart) Matching(size Size) []Size {
+	found, err := c.matches(size)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[Size]bool)
+	var sizes []Size
+	for _, r := range found {
+		for i, system := range Systems {
+			s := New(system, r[i])
+			if !seen[s] {
+				seen[s] = true
+				sizes = append(sizes, s)
+			}
+		}
+	}
+	return sizes
+}
+
+// ChartFor возвращает таблицу бренда или общую, если своей у бренда нет.
+func ChartFor(brand string) *Chart {
+	brand = strings.ToLower(strings.TrimSpace(brand))
+	for _, chart := range brandCharts {
+		for _, b := range chart.Brands {
+			if b == brand {
+				return chart
+			}
+		}
+	}
+	return genericChart
+}
+
+// Charts - все таблицы, общая последней.
+func Charts() []*Chart {
+	return append(append([]*Chart{}, brandCharts...), genericChart)
+}
+
+// KnownBrands - бренды со своими таблицами, в нижнем регистре.
+func KnownBrands() []string {
+	var brands []string
+	for _, chart := range brandCharts {
+		brands = append(brands, chart.Brands...)
+	}
+	return brands
+}
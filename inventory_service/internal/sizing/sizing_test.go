@@ -0,0 +1,71 @@
+package sizing_test
+
+import (
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+	"github.com/stretchr/testify/require"
+)
+
+// Один и тот же EU 42 у разных брендов - разные US размеры.
+func TestConvert_BrandAware(t *testing.T) {
+	tests := []struct {
+		brand string
+		from  sizing.Size
+		to    sizing.System
+		want  float64
+	}{
+		{"Nike", sizing.New(sizing.EU, 42.5), sizing.USMen, 9},
+		{"Jordan", sizing.New(sizing.USMen, 9), sizing.CM, 27},
+		{"Nike", sizing.New(sizing.USWomen, 10.5), sizing.EU, 42.5},
+		{"adidas", sizing.New(sizing.UK, 8.5), sizing.EU, 42.7},
+		{"Adidas", sizing.New(sizing.EU, float64(float32(42.7))), sizing.USMen, 9},
+		{"Puma", sizing.New(sizing.EU, 42), sizing.UK, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.brand+" "+tt.from.String(), func(t *testing.T) {
+			require := require.New(t)
+
+			got, err := sizing.ChartFor(tt.brand).Convert(tt.from, tt.to)
+
+			require.NoError(err)
+			require.Equal(sizing.New(tt.to, tt.want), got)
+		})
+	}
+}
+
+// Размер, совпадающий с несколькими строками таблицы, отклоняется, а в фильтре подходит под все.
+func TestValidate_Ambiguous(t *testing.T) {
+	require := require.New(t)
+	nike := sizing.ChartFor("nike")
+
+	err := nike.Validate(sizing.New(sizing.UK, 6))
+	require.ErrorIs(err, sizing.ErrAmbiguousSize)
+
+	matching := nike.Matching(sizing.New(sizing.UK, 6))
+	require.Contains(matching, sizing.New(sizing.USMen, 6.5))
+	require.Contains(matching, sizing.New(sizing.USMen, 7))
+}
+
+func TestValidate_Unknown(t *testing.T) {
+	require := require.New(t)
+
+	err := sizing.ChartFor("adidas").Validate(sizing.New(sizing.EU, 42.5))
+	require.ErrorIs(err, sizing.ErrUnknownSize)
+	require.Nil(sizing.ChartFor("adidas").Matching(sizing.New(sizing.EU, 42.5)))
+}
+
+func TestParseSystem(t *testing.T) {
+	require := require.New(t)
+
+	_, err := sizing.ParseSystem("")
+	require.ErrorIs(err, sizing.ErrAmbiguousSize)
+
+	_, err = sizing.ParseSystem("JP")
+	require.Error(err)
+
+	system, err := sizing.ParseSystem("us_m")
+	require.NoError(err)
+	require.Equal(sizing.USMen, system)
+}
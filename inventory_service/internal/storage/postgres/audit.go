@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+)
+
+// insertAudit дописывает записи журнала в транзакции изменения, actor и
+// request_id берутся из контекста запроса.
+func (s *PostgresStorageImpl) insertAudit(ctx context.Context, tx pgx.Tx, entries []*model.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		SneakersAuditTable,
+		AuditSneakerID,
+		AuditArticle,
+		AuditAction,
+		AuditActor,
+		AuditRequestID,
+		AuditSnapshotBefore,
+		AuditSnapshotAfter,
+	)
+
+	actor := reqctx.Actor(ctx)
+	requestID := reqctx.RequestID(ctx)
+
+	batch := &pgx.Batch{}
+	for _, entry := range entries {
+		entry.Actor = actor
+		entry.RequestID = requestID
+		batch.Queue(query,
+			entry.SneakerID,
+			entry.Article,
+			entry.Action,
+			entry.Actor,
+			entry.RequestID,
+			entry.Before,
+			entry.After,
+		)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLog возвращает журнал изменений от новых записей к старым.
+func (s *PostgresStorageImpl) GetAuditLog(ctx context.Context, filter model.AuditFilter, pagination model.Pagination) ([]model.AuditEntry, error) {
+	queryBuilder := s.sq.Select(
+		AuditID,
+		AuditSneakerID,
+		AuditArticle,
+		AuditAction,
+		AuditActor,
+		AuditRequestID,
+		AuditSnapshotBefore,
+		AuditSnapshotAfter,
+		AuditCreatedAt,
+	).From(SneakersAuditTable)
+
+	if filter.SneakerID != 0 {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{AuditSneakerID: filter.SneakerID})
+	}
+	if filter.Article != "" {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{AuditArticle: filter.Article})
+	}
+	if filter.Actor != "" {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{AuditActor: filter.Actor})
+	}
+	if !filter.From.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.GtOrEq{AuditCreatedAt: filter.From})
+	}
+	if !filter.To.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.Lt{AuditCreatedAt: filter.To})
+	}
+
+	queryBuilder = queryBuilder.OrderBy(AuditCreatedAt+" DESC", AuditID+" DESC")
+	if pagination.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(uint64(pagination.Limit))
+	}
+	if pagination.Offset > 0 {
+		queryBuilder = queryBuilder.Offset(uint64(pagination.Offset))
+	}
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditEntry])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	return entries, nil
+}
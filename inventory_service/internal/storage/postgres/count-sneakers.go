@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+)
+
+// CountSneakers считает живые и мягко удалённые кроссовки одним запросом.
+func (s *PostgresStorageImpl) CountSneakers(ctx context.Context) (model.SneakerCounts, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			COUNT(*) FILTER (WHERE %s IS NULL),
+			COUNT(*) FILTER (WHERE %s IS NOT NULL)
+		FROM %s`,
+		SneakersDeletedAt,
+		SneakersDeletedAt,
+		SneakersTable,
+	)
+
+	var counts model.SneakerCounts
+	if err := s.pool.QueryRow(ctx, query).Scan(&counts.Live, &counts.Deleted); err != nil {
+		return model.SneakerCounts{}, fmt.Errorf("failed to count sneakers: %w", err)
+	}
+
+	return counts, nil
+}
+
+// Stat отдаёт статистику пула соединений для метрик.
+func (s *PostgresStorageImpl) Stat() *pgxpool.Stat {
+	return s.pool.Stat()
+}
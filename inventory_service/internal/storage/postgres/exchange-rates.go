@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+)
+
+// SaveExchangeRates сохраняет курсы. Курс не перезаписывается более старым,
+// если два администратора задали его одновременно.
+func (s *PostgresStorageImpl) SaveExchangeRates(ctx context.Context, rates []model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (%[2]s, %[3]s, %[4]s, %[5]s, %[6]s, %[7]s)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (%[2]s, %[3]s) DO UPDATE SET
+			%[4]s = EXCLUDED.%[4]s,
+			%[5]s = EXCLUDED.%[5]s,
+			%[6]s = EXCLUDED.%[6]s,
+			%[7]s = EXCLUDED.%[7]s
+		WHERE EXCLUDED.%[7]s >= %[1]s.%[7]s`,
+		ExchangeRatesTable,
+		ExchangeRateBase,
+		ExchangeRateQuote,
+		ExchangeRateRate,
+		ExchangeRateSource,
+		ExchangeRateUpdatedBy,
+		ExchangeRateUpdatedAt,
+	)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(query,
+			string(rate.Base),
+			string(rate.Quote),
+			rate.Rate,
+			rate.Source,
+			rate.UpdatedBy,
+			rate.UpdatedAt,
+		)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PostgresStorageImpl) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	sql, args, err := s.sq.Select(
+		ExchangeRateBase,
+		ExchangeRateQuote,
+		ExchangeRateRate,
+		ExchangeRateSource,
+		ExchangeRateUpdatedBy,
+		ExchangeRateUpdatedAt,
+	).From(ExchangeRatesTable).OrderBy(ExchangeRateBase, ExchangeRateQuote).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+
+	rates, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.ExchangeRate])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	return rates, nil
+}
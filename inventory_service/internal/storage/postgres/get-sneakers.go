@@ -7,34 +7,65 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+	"github.com/kripst/krosovka/inventory_service/internal/tracing"
 )
 
-type SneakerFilters struct {
-	Brand    string
-	Name     string
-	MinPrice float64
-	MaxPrice float64
-	Size     float32
-}
-
-type Pagination struct {
-	Limit  int
-	Offset int
+// sneakerColumns - колонки для сканирования в model.Sneaker,
+// NULL в необязательных текстовых полях заменяется пустой строкой.
+var sneakerColumns = []string{
+	SneakersID,
+	SneakersArticle,
+	SneakersName,
+	"COALESCE(" + SneakersDescription + ", '') AS " + SneakersDescription,
+	SneakersPrice,
+	SneakersCurrency,
+	SneakersSize,
+	SneakersSizeSystem,
+	SneakersBrand,
+	"COALESCE(" + SneakersProductionAddress + ", '') AS " + SneakersProductionAddress,
+	SneakersCreatedAt,
+	SneakersUpdatedAt,
+	SneakersDeletedAt,
+	SneakersScheduledPrice,
+	SneakersScheduledEndsAt,
+	"COALESCE((SELECT p." + PicturesData + " FROM " + PicturesTable + " p WHERE p." + PicturesArticle + " = " +
+		SneakersTable + "." + SneakersArticle + " AND p." + PicturesDeletedAt + " IS NULL), '') AS " + SneakersPicture,
 }
 
 // GetSneakers получает кроссовки с фильтрацией и пагинацией.
-func (r *PostgresStorageImpl) GetSneakers(ctx context.Context, filter SneakerFilters, pagination Pagination) ([]model.Sneaker, error) {
-	// Начинаем строить запрос, выбирая все поля
-	queryBuilder := r.sq.Select("*").From(SneakersTable)
+func (r *PostgresStorageImpl) GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) ([]model.Sneaker, error) {
+	// Отдельный спан, чтобы время сборки запроса было видно отдельно от БД
+	_, buildSpan := tracing.Tracer().Start(ctx, "squirrel.build GetSneakers")
+
+	// Начинаем строить запрос, мягко удалённые записи не возвращаем, если их не просили отдельно
+	queryBuilder := r.sq.Select(sneakerColumns...).From(SneakersTable)
+	if filter.OnlyDeleted {
+		queryBuilder = queryBuilder.Where(squirrel.NotEq{SneakersDeletedAt: nil})
+	} else {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{SneakersDeletedAt: nil})
+	}
 
 	// Последовательно применяем фильтры с помощью вспомогательных методов
+	queryBuilder = r.applyIDsFilter(queryBuilder, filter.IDs)
+	queryBuilder = r.applyArticlesFilter(queryBuilder, filter.Articles)
 	queryBuilder = r.applyBrandFilter(queryBuilder, filter.Brand)
 	queryBuilder = r.applyNameFilter(queryBuilder, filter.Name)
 	queryBuilder = r.applyPriceFilter(queryBuilder, filter.MinPrice, filter.MaxPrice)
 	queryBuilder = r.applySizeFilter(queryBuilder, filter.Size)
 
-	// Добавляем сортировку для стабильной пагинации
-	queryBuilder = queryBuilder.OrderBy(SneakersCreatedAt + "DESC")
+	// Добавляем сортировку для стабильной пагинации; изменения читаются от старых к новым
+	if filter.UpdatedSince != nil {
+		if filter.UpdatedAfterID > 0 {
+			queryBuilder = queryBuilder.Where("("+SneakersUpdatedAt+", "+SneakersID+") > (?, ?)", *filter.UpdatedSince, filter.UpdatedAfterID)
+		} else {
+			queryBuilder = queryBuilder.Where(squirrel.GtOrEq{SneakersUpdatedAt: *filter.UpdatedSince})
+		}
+		queryBuilder = queryBuilder.OrderBy(SneakersUpdatedAt, SneakersID)
+	} else {
+		queryBuilder = queryBuilder.OrderBy(SneakersCreatedAt+" DESC", SneakersID)
+	}
 
 	// Применяем пагинацию
 	if pagination.Limit > 0 {
@@ -46,6 +77,7 @@ func (r *PostgresStorageImpl) GetSneakers(ctx context.Context, filter SneakerFil
 
 	// Генерируем финальный SQL и слайс аргументов
 	sql, args, err := queryBuilder.ToSql()
+	buildSpan.End()
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
 	}
@@ -69,9 +101,24 @@ func (r *PostgresStorageImpl) GetSneakers(ctx context.Context, filter SneakerFil
 
 // --- Вспомогательные методы для фильтрации ---
 
+func (r *PostgresStorageImpl) applyIDsFilter(builder squirrel.SelectBuilder, ids []int32) squirrel.SelectBuilder {
+	if len(ids) > 0 {
+		return builder.Where(squirrel.Eq{SneakersID: ids})
+	}
+	return builder
+}
+
+func (r *PostgresStorageImpl) applyArticlesFilter(builder squirrel.SelectBuilder, articles []string) squirrel.SelectBuilder {
+	if len(articles) > 0 {
+		return builder.Where(squirrel.Eq{SneakersArticle: articles})
+	}
+	return builder
+}
+
 func (r *PostgresStorageImpl) applyBrandFilter(builder squirrel.SelectBuilder, brand string) squirrel.SelectBuilder {
 	if brand != "" {
-		return builder.Where(squirrel.Eq{SneakersBrand: brand})
+		// Бренд вводят как угодно: "nike", "Nike", "NIKE"
+		return builder.Where("LOWER("+SneakersBrand+") = LOWER(?)", brand)
 	}
 	return builder
 }
@@ -84,19 +131,53 @@ func (r *PostgresStorageImpl) applyNameFilter(builder squirrel.SelectBuilder, na
 	return builder
 }
 
-func (r *PostgresStorageImpl) applyPriceFilter(builder squirrel.SelectBuilder, minPrice, maxPrice float64) squirrel.SelectBuilder {
-	if minPrice > 0 {
-		builder = builder.Where(squirrel.GtOrEq{SneakersPrice: minPrice})
+// effectivePrice - цена с учётом действующего запланированного изменения
+var effectivePrice = "COALESCE(" + SneakersScheduledPrice + ", " + SneakersPrice + ")"
+
+// applyPriceFilter фильтрует по цене, которую покупатель платит сейчас.
+// Суммы сравниваются только с товарами той же валюты.
+func (r *PostgresStorageImpl) applyPriceFilter(builder squirrel.SelectBuilder, minPrice, maxPrice *money.Money) squirrel.SelectBuilder {
+	if minPrice != nil {
+		builder = builder.Where(squirrel.Eq{SneakersCurrency: string(minPrice.Currency)})
+		builder = builder.Where(effectivePrice+" >= ?", minPrice.Amount)
 	}
-	if maxPrice > 0 {
-		builder = builder.Where(squirrel.LtOrEq{SneakersPrice: maxPrice})
+	if maxPrice != nil {
+		builder = builder.Where(squirrel.Eq{SneakersCurrency: string(maxPrice.Currency)})
+		builder = builder.Where(effectivePrice+" <= ?", maxPrice.Amount)
 	}
 	return builder
 }
 
-func (r *PostgresStorageImpl) applySizeFilter(builder squirrel.SelectBuilder, size float32) squirrel.SelectBuilder {
-	if size > 0 {
-		return builder.Where(squirrel.Eq{SneakersSize: size})
+// applySizeFilter ищет размер во всех системах: для каждой таблицы размеров
+// размер переводится в равные ему и сравнивается только с брендами этой таблицы.
+// Неоднозначный размер (UK 6 у Nike) подходит под все свои строки.
+func (r *PostgresStorageImpl) applySizeFilter(builder squirrel.SelectBuilder, size *sizing.Size) squirrel.SelectBuilder {
+	if size == nil {
+		return builder
 	}
-	return builder
+
+	brand := "LOWER(" + SneakersBrand + ")"
+	byChart := squirrel.Or{}
+	for _, chart := range sizing.Charts() {
+		sizes := squirrel.Or{}
+		for _, s := range chart.Matching(*size) {
+			sizes = append(sizes, squirrel.Eq{SneakersSizeSystem: string(s.System), SneakersSize: s.Value})
+		}
+		if len(sizes) == 0 {
+			continue
+		}
+
+		// У общей таблицы нет своих брендов, она для всех остальных
+		var brands squirrel.Sqlizer = squirrel.Eq{brand: chart.Brands}
+		if len(chart.Brands) == 0 {
+			brands = squirrel.NotEq{brand: sizing.KnownBrands()}
+		}
+		byChart = append(byChart, squirrel.And{brands, sizes})
+	}
+
+	if len(byChart) == 0 {
+		// Размера нет ни в одной таблице
+		return builder.Where("FALSE")
+	}
+	return builder.Where(byChart)
 }
\ No newline at end of file
@@ -0,0 +1,416 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"github.com/shopspring/decimal"
+)
+
+// orderColumns - колонки заказа в порядке scanOrder.
+var orderColumns = []string{
+	OrderID,
+	OrderCustomerID,
+	OrderCustomerName,
+	OrderCustomerPhone,
+	OrderCustomerAddress,
+	OrderTotal,
+	OrderCurrency,
+	OrderStatus,
+	OrderCreatedAt,
+	OrderUpdatedAt,
+}
+
+// CreateOrder сохраняет заказ по текущим ценам каталога и заполняет ID, Status,
+// Total, Currency, даты и снимки товаров в позициях. Строки товаров блокируются
+// до конца транзакции, чтобы цена не поменялась между проверкой и записью.
+func (s *PostgresStorageImpl) CreateOrder(ctx context.Context, order *model.Order) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context canceled before starting transaction: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.snapshotOrderLines(ctx, tx, order); err != nil {
+		return err
+	}
+
+	insertOrder := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s, %s, %s)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING %s, %s, %s, %s`,
+		OrdersTable,
+		OrderCustomerID,
+		OrderCustomerName,
+		OrderCustomerPhone,
+		OrderCustomerAddress,
+		OrderTotal,
+		OrderCurrency,
+		OrderID, OrderStatus, OrderCreatedAt, OrderUpdatedAt,
+	)
+	err = tx.QueryRow(ctx, insertOrder,
+		order.Customer.ID,
+		order.Customer.Name,
+		order.Customer.Phone,
+		order.Customer.Address,
+		order.Total,
+		order.Currency,
+	).Scan(&order.ID, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert order: %w", err)
+	}
+
+	insertLine := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		OrderLinesTable,
+		OrderLineOrderID,
+		OrderLineNo,
+		OrderLineSneakerID,
+		OrderLineArticle,
+		OrderLineSneakerName,
+		OrderLineSize,
+		OrderLineSizeSystem,
+		OrderLineQuantity,
+		OrderLinePrice,
+	)
+	batch := &pgx.Batch{}
+	for i, line := range order.Lines {
+		batch.Queue(insertLine,
+			order.ID,
+			i+1,
+			line.SneakerID,
+			line.Article,
+			line.SneakerName,
+			line.Size,
+			line.SizeSystem,
+			line.Quantity,
+			line.Price,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert order lines: %w", err)
+	}
+
+	if err := s.insertOrderTransition(ctx, tx, order.ID, "", order.Status, ""); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotOrderLines сверяет позиции с каталогом и переносит в них ID, название
+// и действующую цену товара. Расхождения возвращаются как model.ErrOrderConflict.
+func (s *PostgresStorageImpl) snapshotOrderLines(ctx context.Context, tx pgx.Tx, order *model.Order) error {
+	articles := make([]string, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		articles = append(articles, line.Article)
+	}
+
+	lockQuery := fmt.Sprintf(`
+		SELECT %s, %s, %s, %s, %s, %s, %s, %s
+		FROM %s
+		WHERE %s = ANY($1) AND %s IS NULL
+		FOR SHARE`,
+		SneakersID,
+		SneakersArticle,
+		SneakersName,
+		SneakersSize,
+		SneakersSizeSystem,
+		SneakersPrice,
+		SneakersCurrency,
+		SneakersScheduledPrice,
+		SneakersTable,
+		SneakersArticle, SneakersDeletedAt,
+	)
+	rows, err := tx.Query(ctx, lockQuery, articles)
+	if err != nil {
+		return fmt.Errorf("failed to lock order sneakers: %w", err)
+	}
+	sneakers, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[model.Sneaker])
+	if err != nil {
+		return fmt.Errorf("failed to lock order sneakers: %w", err)
+	}
+
+	byArticle := make(map[string]model.Sneaker, len(sneakers))
+	for _, sneaker := range sneakers {
+		byArticle[sneaker.Article] = sneaker
+	}
+
+	order.Currency = ""
+	order.Total = decimal.Zero
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		sneaker, ok := byArticle[line.Article]
+		if !ok {
+			return fmt.Errorf("%w: article %s is not for sale", model.ErrOrderConflict, line.Article)
+		}
+		if !line.SameSize(sneaker.Size, sneaker.SizeSystem) {
+			return fmt.Errorf("%w: article %s is size %s", model.ErrOrderConflict, line.Article, sneaker.SizeValue())
+		}
+
+		price := sneaker.EffectivePrice()
+		if order.Currency == "" {
+			order.Currency = price.Currency
+		}
+		if price.Currency != order.Currency {
+			return fmt.Errorf("%w: article %s is priced in %s, order in %s", model.ErrOrderConflict, line.Article, price.Currency, order.Currency)
+		}
+		if line.ExpectedPrice != nil && !line.ExpectedPrice.Equal(price) {
+			return fmt.Errorf("%w: price of %s is %s, not %s", model.ErrOrderConflict, line.Article, price, line.ExpectedPrice)
+		}
+
+		line.SneakerID = sneaker.ID
+		line.SneakerName = sneaker.SneakerName
+		line.Price = price.Amount
+		order.Total = order.Total.Add(line.Total())
+	}
+
+	return nil
+}
+
+// insertOrderTransition дописывает историю статусов, from пуст при создании заказа.
+func (s *PostgresStorageImpl) insertOrderTransition(ctx context.Context, tx pgx.Tx, orderID int64, from, to model.OrderStatus, reason string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s, %s, %s)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)`,
+		OrderTransitionsTable,
+		OrderTransitionOrderID,
+		OrderTransitionFrom,
+		OrderTransitionTo,
+		OrderTransitionReason,
+		OrderTransitionActor,
+		OrderTransitionRequestID,
+	)
+
+	_, err := tx.Exec(ctx, query, orderID, string(from), string(to), reason, reqctx.Actor(ctx), reqctx.RequestID(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to write order history: %w", err)
+	}
+	return nil
+}
+
+// GetOrder возвращает заказ с позициями и историей статусов.
+func (s *PostgresStorageImpl) GetOrder(ctx context.Context, orderID int64) (model.Order, error) {
+	sql, args, err := s.sq.Select(orderColumns...).
+		From(OrdersTable).
+		Where(squirrel.Eq{OrderID: orderID}).
+		ToSql()
+	if err != nil {
+		return model.Order{}, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	order, err := scanOrder(s.pool.QueryRow(ctx, sql, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Order{}, fmt.Errorf("%w: %d", model.ErrOrderNotFound, orderID)
+	}
+	if err != nil {
+		return model.Order{}, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+
+	orders := []model.Order{order}
+	if err := s.loadOrderLines(ctx, orders); err != nil {
+		return model.Order{}, err
+	}
+	order = orders[0]
+
+	historySQL, historyArgs, err := s.sq.Select(
+		"COALESCE("+OrderTransitionFrom+", '') AS "+OrderTransitionFrom,
+		OrderTransitionTo,
+		OrderTransitionReason,
+		OrderTransitionActor,
+		OrderTransitionRequestID,
+		OrderTransitionCreatedAt,
+	).From(OrderTransitionsTable).
+		Where(squirrel.Eq{OrderTransitionOrderID: orderID}).
+		OrderBy(OrderTransitionID).
+		ToSql()
+	if err != nil {
+		return model.Order{}, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+	rows, err := s.pool.Query(ctx, historySQL, historyArgs...)
+	if err != nil {
+		return model.Order{}, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+	order.History, err = pgx.CollectRows(rows, pgx.RowToStructByName[model.OrderTransition])
+	if err != nil {
+		return model.Order{}, fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	return order, nil
+}
+
+// ListOrders возвращает заказы покупателя с позициями, от новых к старым.
+func (s *PostgresStorageImpl) ListOrders(ctx context.Context, filter model.OrderFilter, pagination model.Pagination) ([]model.Order, error) {
+	queryBuilder := s.sq.Select(orderColumns...).
+		From(OrdersTable).
+		Where(squirrel.Eq{OrderCustomerID: filter.CustomerID})
+	if filter.Status != "" {
+		queryBuilder = queryBuilder.Where(squirrel.Eq{OrderStatus: string(filter.Status)})
+	}
+
+	queryBuilder = queryBuilder.OrderBy(OrderCreatedAt+" DESC", OrderID+" DESC")
+	if pagination.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(uint64(pagination.Limit))
+	}
+	if pagination.Offset > 0 {
+		queryBuilder = queryBuilder.Offset(uint64(pagination.Offset))
+	}
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (model.Order, error) {
+		return scanOrder(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	if err := s.loadOrderLines(ctx, orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// loadOrderLines дочитывает позиции заказов одним запросом.
+func (s *PostgresStorageImpl) loadOrderLines(ctx context.Context, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	index := make(map[int64]int, len(orders))
+	ids := make([]int64, 0, len(orders))
+	for i, order := range orders {
+		index[order.ID] = i
+		ids = append(ids, order.ID)
+	}
+
+	sql, args, err := s.sq.Select(
+		OrderLineOrderID,
+		OrderLineSneakerID,
+		OrderLineArticle,
+		OrderLineSneakerName,
+		OrderLineSize,
+		OrderLineSizeSystem,
+		OrderLineQuantity,
+		OrderLinePrice,
+	).From(OrderLinesTable).
+		Where(squirrel.Eq{OrderLineOrderID: ids}).
+		OrderBy(OrderLineOrderID, OrderLineNo).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID int64
+			line    model.OrderLine
+		)
+		err := rows.Scan(&orderID, &line.SneakerID, &line.Article, &line.SneakerName,
+			&line.Size, &line.SizeSystem, &line.Quantity, &line.Price)
+		if err != nil {
+			return fmt.Errorf("ошибка при сканировании результатов: %w", err)
+		}
+		i := index[orderID]
+		orders[i].Lines = append(orders[i].Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	return nil
+}
+
+// TransitionOrder переводит заказ в change.To, если переход разрешён из текущего
+// статуса (и текущий статус равен change.From, если он задан), и пишет историю.
+func (s *PostgresStorageImpl) TransitionOrder(ctx context.Context, change model.OrderStatusChange) (model.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Order{}, fmt.Errorf("context canceled before starting transaction: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return model.Order{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем заказ, чтобы два одновременных перехода не прошли проверку оба
+	lockQuery := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 FOR UPDATE`,
+		OrderStatus, OrdersTable, OrderID)
+
+	var current model.OrderStatus
+	err = tx.QueryRow(ctx, lockQuery, change.OrderID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Order{}, fmt.Errorf("%w: %d", model.ErrOrderNotFound, change.OrderID)
+	}
+	if err != nil {
+		return model.Order{}, fmt.Errorf("failed to lock order %d: %w", change.OrderID, err)
+	}
+
+	if change.From != "" && change.From != current {
+		return model.Order{}, fmt.Errorf("%w: order %d is %s, not %s", model.ErrInvalidTransition, change.OrderID, current, change.From)
+	}
+	if err := current.CheckTransition(change.To); err != nil {
+		return model.Order{}, fmt.Errorf("order %d: %w", change.OrderID, err)
+	}
+
+	updateQuery := fmt.Sprintf(`UPDATE %s SET %s = $2, %s = CURRENT_TIMESTAMP WHERE %s = $1`,
+		OrdersTable, OrderStatus, OrderUpdatedAt, OrderID)
+	if _, err := tx.Exec(ctx, updateQuery, change.OrderID, string(change.To)); err != nil {
+		return model.Order{}, fmt.Errorf("failed to update order %d: %w", change.OrderID, err)
+	}
+
+	if err := s.insertOrderTransition(ctx, tx, change.OrderID, current, change.To, change.Reason); err != nil {
+		return model.Order{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Order{}, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return s.GetOrder(ctx, change.OrderID)
+}
+
+func scanOrder(row pgx.Row) (model.Order, error) {
+	var order model.Order
+	err := row.Scan(
+		&order.ID,
+		&order.Customer.ID,
+		&order.Customer.Name,
+		&order.Customer.Phone,
+		&order.Customer.Address,
+		&order.Total,
+		&order.Currency,
+		&order.Status,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	return order, err
+}
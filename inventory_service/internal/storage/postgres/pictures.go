@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+)
+
+// savePictures сохраняет фото кроссовок в той же транзакции, что и сами записи.
+// Пустой Picture оставляет текущее фото без изменений.
+func (s *PostgresStorageImpl) savePictures(ctx context.Context, tx pgx.Tx, sneakers []*model.Sneaker) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s) VALUES ($1, $2)
+		ON CONFLICT (%s) DO UPDATE
+		SET %s = EXCLUDED.%s, %s = NULL`,
+		PicturesTable, PicturesArticle, PicturesData,
+		PicturesArticle,
+		PicturesData, PicturesData, PicturesDeletedAt,
+	)
+
+	batch := &pgx.Batch{}
+	for _, sneaker := range sneakers {
+		if sneaker.Picture != "" {
+			batch.Queue(query, sneaker.Article, sneaker.Picture)
+		}
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("save pictures: %w", err)
+	}
+	return nil
+}
@@ -9,10 +9,120 @@ const (
 	SneakersName              = "sneaker_name"
 	SneakersDescription       = "sneaker_description"
 	SneakersPrice             = "price"
+	SneakersCurrency          = "currency"
 	SneakersSize              = "size"
+	SneakersSizeSystem        = "size_system"
 	SneakersBrand             = "brand"
 	SneakersProductionAddress = "production_address"
 	SneakersCreatedAt         = "created_at"
 	SneakersUpdatedAt         = "updated_at"
 	SneakersDeletedAt         = "deleted_at"
+	SneakersScheduledPrice    = "scheduled_price"
+	SneakersScheduledEndsAt   = "scheduled_ends_at"
+	SneakersPriceChangeID     = "price_change_id"
+)
+
+const (
+	PicturesTable = "sneakers_pictures"
+
+	PicturesArticle   = "sneaker_article"
+	PicturesData      = "picture_data"
+	PicturesDeletedAt = "deleted_at"
+
+	// SneakersPicture - вычисляемая колонка выборки кроссовок
+	SneakersPicture = "picture"
+)
+
+const (
+	SneakersAuditTable = "sneakers_audit"
+
+	AuditID             = "id"
+	AuditSneakerID      = "sneaker_id"
+	AuditArticle        = "article"
+	AuditAction         = "action"
+	AuditActor          = "actor"
+	AuditRequestID      = "request_id"
+	AuditSnapshotBefore = "snapshot_before"
+	AuditSnapshotAfter  = "snapshot_after"
+	AuditCreatedAt      = "created_at"
+)
+
+const (
+	PriceChangesTable = "price_changes"
+
+	PriceChangeID              = "id"
+	PriceChangeSneakerID       = "sneaker_id"
+	PriceChangePrice           = "price"
+	PriceChangeDiscountPercent = "discount_percent"
+	PriceChangeCurrency        = "currency"
+	PriceChangeStartsAt        = "starts_at"
+	PriceChangeEndsAt          = "ends_at"
+	PriceChangeStatus          = "status"
+	PriceChangeCreatedBy       = "created_by"
+	PriceChangeCreatedAt       = "created_at"
+)
+
+const (
+	PriceHistoryTable = "price_history"
+
+	PriceHistoryID             = "id"
+	PriceHistorySneakerID      = "sneaker_id"
+	PriceHistoryPrice          = "price"
+	PriceHistoryEffectivePrice = "effective_price"
+	PriceHistoryCurrency       = "currency"
+	PriceHistoryPriceChangeID  = "price_change_id"
+	PriceHistoryChangedAt      = "changed_at"
+)
+
+const (
+	ExchangeRatesTable = "exchange_rates"
+
+	ExchangeRateBase      = "base_currency"
+	ExchangeRateQuote     = "quote_currency"
+	ExchangeRateRate      = "rate"
+	ExchangeRateSource    = "source"
+	ExchangeRateUpdatedBy = "updated_by"
+	ExchangeRateUpdatedAt = "updated_at"
+)
+
+const (
+	OrdersTable = "orders"
+
+	OrderID              = "id"
+	OrderCustomerID      = "customer_id"
+	OrderCustomerName    = "customer_name"
+	OrderCustomerPhone   = "customer_phone"
+	OrderCustomerAddress = "customer_address"
+	OrderTotal           = "total"
+	OrderCurrency        = "currency"
+	OrderStatus          = "status"
+	OrderCreatedAt       = "created_at"
+	OrderUpdatedAt       = "updated_at"
+)
+
+const (
+	OrderLinesTable = "order_lines"
+
+	OrderLineOrderID     = "order_id"
+	OrderLineNo          = "line_no"
+	OrderLineSneakerID   = "sneaker_id"
+	OrderLineArticle     = "article"
+	OrderLineSneakerName = "sneaker_name"
+	OrderLineSize        = "size"
+	OrderLineSizeSystem  = "size_system"
+	OrderLineQuantity    = "quantity"
+	OrderLinePrice       = "price"
+)
+
+const (
+	OrderTransitionsTable = "order_transitions"
+
+	OrderTransitionID        = "id"
+	OrderTransitionOrderID   = "order_id"
+	OrderTransitionFrom      = "from_status"
+	OrderTransitionTo        = "to_status"
+	OrderTransitionReason    = "reason"
+	OrderTransitionActor     = "actor"
+	OrderTransitionRequestID = "request_id"
+	OrderTransitionCreatedAt = "created_at"
 )
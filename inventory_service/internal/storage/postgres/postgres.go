@@ -2,7 +2,9 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -10,131 +12,229 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kripst/krosovka/inventory_service/config"
 	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+	"github.com/kripst/krosovka/inventory_service/internal/tracing"
 	"go.uber.org/zap"
 )
 
+// closeTimeout - сколько Close ждёт возврата соединений в пул
+const closeTimeout = 60 * time.Second
+
 type PostgresStorageImpl struct {
-	pool  *pgxpool.Pool //postgres
-	log *zap.Logger
-	ctx context.Context
-	sq  squirrel.StatementBuilderType
+	pool *pgxpool.Pool //postgres
+	log  *zap.Logger
+	sq   squirrel.StatementBuilderType
 }
 
 func NewPostgresStorageImpl(storageConfig *config.StorageConfig, log *zap.Logger, ctx context.Context) (*PostgresStorageImpl, error) {
-	poolConfig, err := pgxpool.ParseConfig(storageConfig.DSN())
-    if err != nil {
-        return nil, err
-    }
-    
-    poolConfig.MaxConns = int32(storageConfig.PoolMax)
-    // Здесь можно установить другие параметры конфигурации, например:
-    // poolConfig.MinConns = int32(storageConfig.PoolMin)
-    // poolConfig.MaxConnLifetime = storageConfig.MaxConnLifetime
-    // poolConfig.MaxConnIdleTime = storageConfig.MaxConnIdleTime
-    
-    pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
-    if err != nil {
-        return nil, err
-    }
-	
+	poolConfig, err := newPoolConfig(storageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// pgxpool подключается лениво, поэтому доступность БД проверяем явно
+	if err := connectWithRetry(ctx, pool, storageConfig, log); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
 	return &PostgresStorageImpl{
 		pool: pool,
-		log: log,
-		sq: squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
+		log:  log,
+		sq:   squirrel.StatementBuilder.PlaceholderFormat(squirrel.Dollar),
 	}, nil
 }
 
+func newPoolConfig(storageConfig *config.StorageConfig) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(storageConfig.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse storage config: %w", err)
+	}
+
+	poolConfig.MaxConns = int32(storageConfig.PoolMax)
+	poolConfig.MinConns = int32(storageConfig.PoolMin)
+	if storageConfig.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = storageConfig.MaxConnLifetime
+	}
+	if storageConfig.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = storageConfig.MaxConnIdleTime
+	}
+	if storageConfig.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = storageConfig.HealthCheckPeriod
+	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+	if storageConfig.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(storageConfig.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return poolConfig, nil
+}
+
+func connectWithRetry(ctx context.Context, pool *pgxpool.Pool, storageConfig *config.StorageConfig, log *zap.Logger) error {
+	backoff := storageConfig.ConnectBackoff
+
+	for attempt := 0; ; attempt++ {
+		err := pool.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= storageConfig.ConnectRetries {
+			return fmt.Errorf("failed to connect to postgres after %d attempts: %w", attempt+1, err)
+		}
+
+		log.Warn("postgres is not available, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context canceled while connecting to postgres: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if storageConfig.ConnectBackoffMax > 0 && backoff > storageConfig.ConnectBackoffMax {
+			backoff = storageConfig.ConnectBackoffMax
+		}
+	}
+}
+
+func (s *PostgresStorageImpl) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// Close закрывает пул. pgxpool.Close блокируется, пока все захваченные
+// соединения не вернутся, поэтому ожидание ограничено closeTimeout.
 func (s *PostgresStorageImpl) Close() error {
-	//TODO graceful shd wait for all conns, actions. select
-	timeLimit := 60 * time.Second
+	done := make(chan struct{})
+	go func() {
+		s.pool.Close()
+		close(done)
+	}()
+
 	select {
-	case <- s.ctx.Done():
-		s.log.Info("ctx done, close pool")
-	case <- time.After(timeLimit):
-		s.log.Info("time limit ", zap.Any("time limit", timeLimit))
-		return fmt.Errorf("time limit done")
+	case <-done:
+		s.log.Info("postgres pool closed")
+		return nil
+	case <-time.After(closeTimeout):
+		s.log.Error("postgres pool close timed out", zap.Duration("time limit", closeTimeout))
+		return fmt.Errorf("close postgres pool: time limit %s exceeded", closeTimeout)
 	}
-	s.pool.Close()
-	return nil
 }
 
 func (s *PostgresStorageImpl) CreateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
-    if len(sneakers) == 0 {
-        return nil
-    }
-
-	 if err := ctx.Err(); err != nil {
-        return fmt.Errorf("context canceled before starting transaction: %w", err)
-    }
-
-    tx, err := s.pool.Begin(ctx)
-    if err != nil {
-        return fmt.Errorf("failed to begin transaction: %w", err)
-    }
-    defer tx.Rollback(ctx)
-
-    // SQL запрос с включением ID
-    query := fmt.Sprintf(`
-        INSERT INTO %s (
-            %s, %s, %s, %s, %s, %s, %s, %s
-        ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8
-        )`,
-        SneakersTable,
-        SneakersID,
-        SneakersArticle,
-        SneakersName,
-        SneakersDescription,
-        SneakersPrice,
-        SneakersSize,
-        SneakersBrand,
-        SneakersProductionAddress,
-    )
-
-    batch := &pgx.Batch{}
-    
-    for _, sneaker := range sneakers {
-		if err := ctx.Err(); err != nil {
-            return fmt.Errorf("context canceled during batch preparation: %w", err)
-        }
-
-		if sneaker.Price <= float64(0) {
-            s.log.Error("Price belong or eq zero", zap.Float64("Price", sneaker.Price), zap.String("ID", SneakersID))
-            return fmt.Errorf("Price belong or eq zero")
-        }
-		
-        batch.Queue(query,
-            sneaker.ID,
-            sneaker.Article,
-            sneaker.SneakerName,
-            sneaker.SneakerDescription,
-            sneaker.Price,
-            sneaker.Size,
-            sneaker.Brand,
-            sneaker.ProductionAddress,
-        )
-    }
+	if len(sneakers) == 0 {
+		return nil
+	}
 
 	if err := ctx.Err(); err != nil {
-        return fmt.Errorf("context canceled before sending batch: %w", err)
-    }
+		return fmt.Errorf("context canceled before starting transaction: %w", err)
+	}
 
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// SQL запрос с включением ID, возвращает снимок новой строки для аудита.
+	// Нулевой ID означает, что его выдаёт последовательность таблицы.
+	query := fmt.Sprintf(`
+		INSERT INTO %s AS s (
+			%s, %s, %s, %s, %s, %s, %s, %s, %s, %s
+		) VALUES (
+			COALESCE(NULLIF($1::integer, 0), nextval(pg_get_serial_sequence('%s', '%s'))),
+			$2, $3, $4, $5, $6, $7, $8, $9, $10
+		)
+		RETURNING s.%s, s.%s, to_jsonb(s)`,
+		SneakersTable,
+		SneakersID,
+		SneakersArticle,
+		SneakersName,
+		SneakersDescription,
+		SneakersPrice,
+		SneakersCurrency,
+		SneakersSize,
+		SneakersBrand,
+		SneakersProductionAddress,
+		SneakersSizeSystem,
+		SneakersTable, SneakersID,
+		SneakersID,
+		SneakersArticle,
+	)
+
+	batch := &pgx.Batch{}
 
-    br := tx.SendBatch(ctx, batch)
-    if err := br.Close(); err != nil {
-        return fmt.Errorf("batch insert failed: %w", err)
-    }
+	for _, sneaker := range sneakers {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context canceled during batch preparation: %w", err)
+		}
+
+		if !sneaker.Price.IsPositive() {
+			s.log.Error("Price belong or eq zero", zap.Stringer("Price", sneaker.BasePrice()), zap.String("ID", SneakersID))
+			return fmt.Errorf("Price belong or eq zero")
+		}
+		if err := checkPriceScale(sneaker); err != nil {
+			return err
+		}
+
+		batch.Queue(query,
+			sneaker.ID,
+			sneaker.Article,
+			sneaker.SneakerName,
+			sneaker.SneakerDescription,
+			sneaker.Price,
+			sneaker.Currency,
+			sneaker.Size,
+			sneaker.Brand,
+			sneaker.ProductionAddress,
+			sneaker.SizeSystem,
+		)
+	}
 
 	if err := ctx.Err(); err != nil {
-        return fmt.Errorf("context canceled before commit: %w", err)
-    }
+		return fmt.Errorf("context canceled before sending batch: %w", err)
+	}
 
+	entries := make([]*model.AuditEntry, 0, len(sneakers))
+	br := tx.SendBatch(ctx, batch)
+	for _, sneaker := range sneakers {
+		entry := &model.AuditEntry{Action: model.AuditCreate}
+		if err := br.QueryRow().Scan(&entry.SneakerID, &entry.Article, &entry.After); err != nil {
+			br.Close()
+			return fmt.Errorf("batch insert failed: %w", err)
+		}
+		sneaker.ID = entry.SneakerID
+		entries = append(entries, entry)
+	}
+	if err := br.Close(); err != nil {
+		return fmt.Errorf("batch insert failed: %w", err)
+	}
+
+	if err := s.savePictures(ctx, tx, sneakers); err != nil {
+		return err
+	}
+
+	if err := s.insertAudit(ctx, tx, entries); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context canceled before commit: %w", err)
+	}
 
-    if err := tx.Commit(ctx); err != nil {
-        return fmt.Errorf("transaction commit failed: %w", err)
-    }
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
 
-    return nil
+	return nil
 }
 
 func (s *PostgresStorageImpl) UpdateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
@@ -153,26 +253,44 @@ func (s *PostgresStorageImpl) UpdateSneakers(ctx context.Context, sneakers []*mo
 	}
 	defer tx.Rollback(ctx)
 
-	// SQL запрос для UPDATE с использованием констант
+	// SQL запрос для UPDATE с использованием констант.
+	// CTE блокирует строку и сохраняет её снимок до изменения для аудита.
+	// Валюту нельзя сменить, пока действует запланированная цена в старой валюте.
 	query := fmt.Sprintf(`
-		UPDATE %s SET 
+		WITH before AS (
+			SELECT %s, to_jsonb(b) AS snapshot FROM %s b
+			WHERE %s = $8 AND %s IS NULL
+				AND (%s IS NULL OR %s = $9)
+			FOR UPDATE
+		)
+		UPDATE %s AS s SET
 			%s = $1,
 			%s = $2,
 			%s = $3,
 			%s = $4,
+			%s = $9,
 			%s = $5,
 			%s = $6,
 			%s = $7,
-		WHERE %s = $8`,
+			%s = $10
+		FROM before
+		WHERE s.%s = before.%s
+		RETURNING s.%s, s.%s, before.snapshot, to_jsonb(s)`,
+		SneakersID, SneakersTable,
+		SneakersID, SneakersDeletedAt,
+		SneakersPriceChangeID, SneakersCurrency,
 		SneakersTable,
 		SneakersArticle,
 		SneakersName,
 		SneakersDescription,
 		SneakersPrice,
+		SneakersCurrency,
 		SneakersSize,
 		SneakersBrand,
 		SneakersProductionAddress,
-		SneakersID,
+		SneakersSizeSystem,
+		SneakersID, SneakersID,
+		SneakersID, SneakersArticle,
 	)
 
 	batch := &pgx.Batch{}
@@ -183,6 +301,10 @@ func (s *PostgresStorageImpl) UpdateSneakers(ctx context.Context, sneakers []*mo
 			return fmt.Errorf("context canceled during batch preparation: %w", err)
 		}
 
+		if err := checkPriceScale(sneaker); err != nil {
+			return err
+		}
+
 		batch.Queue(query,
 			sneaker.Article,
 			sneaker.SneakerName,
@@ -192,15 +314,39 @@ func (s *PostgresStorageImpl) UpdateSneakers(ctx context.Context, sneakers []*mo
 			sneaker.Brand,
 			sneaker.ProductionAddress,
 			sneaker.ID,
+			sneaker.Currency,
+			sneaker.SizeSystem,
 		)
 	}
 
 	// Отправляем batch
+	entries := make([]*model.AuditEntry, 0, len(sneakers))
 	br := tx.SendBatch(ctx, batch)
+	for _, sneaker := range sneakers {
+		entry := &model.AuditEntry{Action: model.AuditUpdate}
+		err := br.QueryRow().Scan(&entry.SneakerID, &entry.Article, &entry.Before, &entry.After)
+		if errors.Is(err, pgx.ErrNoRows) {
+			br.Close()
+			return fmt.Errorf("sneaker %d not found, deleted or has an active price change in another currency", sneaker.ID)
+		}
+		if err != nil {
+			br.Close()
+			return fmt.Errorf("batch update failed: %w", err)
+		}
+		entries = append(entries, entry)
+	}
 	if err := br.Close(); err != nil {
 		return fmt.Errorf("batch update failed: %w", err)
 	}
 
+	if err := s.savePictures(ctx, tx, sneakers); err != nil {
+		return err
+	}
+
+	if err := s.insertAudit(ctx, tx, entries); err != nil {
+		return err
+	}
+
 	// Коммитим транзакцию
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("transaction commit failed: %w", err)
@@ -210,31 +356,131 @@ func (s *PostgresStorageImpl) UpdateSneakers(ctx context.Context, sneakers []*mo
 }
 
 func (s *PostgresStorageImpl) DeleteSneakers(ctx context.Context, itemIDs []int32) error {
+	query := fmt.Sprintf(`
+		WITH before AS (
+			SELECT %s, to_jsonb(b) AS snapshot FROM %s b
+			WHERE %s = ANY($1) AND %s IS NULL
+			FOR UPDATE
+		)
+		UPDATE %s AS s
+		SET %s = CURRENT_TIMESTAMP
+		FROM before
+		WHERE s.%s = before.%s
+		RETURNING s.%s, s.%s, before.snapshot, to_jsonb(s)`,
+		SneakersID, SneakersTable,
+		SneakersID, SneakersDeletedAt, // Проверка что запись еще не удалена
+		SneakersTable,
+		SneakersDeletedAt, // Поле для мягкого удаления
+		SneakersID, SneakersID,
+		SneakersID, SneakersArticle,
+	)
+
+	if err := s.changeWithAudit(ctx, model.AuditDelete, query, itemIDs); err != nil {
+		return fmt.Errorf("failed to soft delete items: %w", err)
+	}
+
+	return nil
+}
+
+// RestoreSneakers снимает мягкое удаление.
+func (s *PostgresStorageImpl) RestoreSneakers(ctx context.Context, itemIDs []int32) error {
+	query := fmt.Sprintf(`
+		WITH before AS (
+			SELECT %s, to_jsonb(b) AS snapshot FROM %s b
+			WHERE %s = ANY($1) AND %s IS NOT NULL
+			FOR UPDATE
+		)
+		UPDATE %s AS s
+		SET %s = NULL
+		FROM before
+		WHERE s.%s = before.%s
+		RETURNING s.%s, s.%s, before.snapshot, to_jsonb(s)`,
+		SneakersID, SneakersTable,
+		SneakersID, SneakersDeletedAt,
+		SneakersTable,
+		SneakersDeletedAt,
+		SneakersID, SneakersID,
+		SneakersID, SneakersArticle,
+	)
+
+	if err := s.changeWithAudit(ctx, model.AuditRestore, query, itemIDs); err != nil {
+		return fmt.Errorf("failed to restore items: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeSneakers окончательно удаляет ранее мягко удалённые записи.
+func (s *PostgresStorageImpl) PurgeSneakers(ctx context.Context, itemIDs []int32) error {
+	query := fmt.Sprintf(`
+		DELETE FROM %s AS s
+		WHERE s.%s = ANY($1) AND s.%s IS NOT NULL
+		RETURNING s.%s, s.%s, to_jsonb(s), NULL::jsonb`,
+		SneakersTable,
+		SneakersID, SneakersDeletedAt,
+		SneakersID, SneakersArticle,
+	)
+
+	if err := s.changeWithAudit(ctx, model.AuditPurge, query, itemIDs); err != nil {
+		return fmt.Errorf("failed to purge items: %w", err)
+	}
+
+	return nil
+}
+
+// changeWithAudit выполняет query, возвращающий (id, article, before, after),
+// и пишет журнал аудита в той же транзакции.
+func (s *PostgresStorageImpl) changeWithAudit(ctx context.Context, action model.AuditAction, query string, itemIDs []int32) error {
 	// Проверяем, не отменен ли контекст перед началом
 	if err := ctx.Err(); err != nil {
 		return fmt.Errorf("context canceled before starting: %w", err)
 	}
 
-    query := fmt.Sprintf(`
-        UPDATE %s 
-        SET %s = CURRENT_TIMESTAMP 
-        WHERE %s = ANY($1) AND %s IS NULL`,
-        SneakersTable,
-        SneakersDeletedAt,  // Поле для мягкого удаления
-        SneakersID,
-        SneakersDeletedAt,  // Проверка что запись еще не удалена
-    )
-
-    // Выполняем запрос с массивом ID
-    result, err := s.pool.Exec(ctx, query, itemIDs)
-    if err != nil {
-        return fmt.Errorf("failed to soft delete items: %w", err)
-    }
-
-    // Проверяем что действительно обновили записи
-    if result.RowsAffected() == 0 {
-        return fmt.Errorf("items not found or already deleted")
-    }
-
-    return nil
-}
\ No newline at end of file
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Выполняем запрос с массивом ID
+	rows, err := tx.Query(ctx, query, itemIDs)
+	if err != nil {
+		return err
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*model.AuditEntry, error) {
+		entry := &model.AuditEntry{Action: action}
+		err := row.Scan(&entry.SneakerID, &entry.Article, &entry.Before, &entry.After)
+		return entry, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Проверяем что действительно обновили записи
+	if len(entries) == 0 {
+		return fmt.Errorf("items not found or already in requested state")
+	}
+
+	if err := s.insertAudit(ctx, tx, entries); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// priceScale - число знаков после запятой в колонках NUMERIC(10, 2)
+const priceScale = 2
+
+// checkPriceScale не даёт PostgreSQL молча округлить цену при записи.
+func checkPriceScale(sneaker *model.Sneaker) error {
+	if !sneaker.Price.Equal(sneaker.Price.Round(priceScale)) {
+		return fmt.Errorf("sneaker %d: price %s has more than %d fractional digits", sneaker.ID, sneaker.Price, priceScale)
+	}
+	return nil
+}
+
+var _ storage.Storage = (*PostgresStorageImpl)(nil)
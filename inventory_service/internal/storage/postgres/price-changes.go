@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/reqctx"
+	"github.com/shopspring/decimal"
+)
+
+// activeStatuses - изменения, которые ещё занимают свой период.
+var activeStatuses = []string{string(model.PriceChangeScheduled), string(model.PriceChangeActive)}
+
+// SchedulePriceChanges сохраняет изменения цены, заполняя ID, Status, CreatedBy
+// и CreatedAt. Периоды изменений одного товара не должны пересекаться.
+func (s *PostgresStorageImpl) SchedulePriceChanges(ctx context.Context, changes []*model.PriceChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context canceled before starting transaction: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокируем товар, чтобы параллельное планирование не прошло проверку пересечения
+	lockQuery := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1 AND %s IS NULL FOR UPDATE`,
+		SneakersCurrency, SneakersTable, SneakersID, SneakersDeletedAt)
+
+	overlapQuery := fmt.Sprintf(`
+		SELECT %s FROM %s
+		WHERE %s = $1 AND %s = ANY($2) AND %s < $4 AND %s > $3
+		LIMIT 1`,
+		PriceChangeID, PriceChangesTable,
+		PriceChangeSneakerID, PriceChangeStatus, PriceChangeStartsAt, PriceChangeEndsAt,
+	)
+
+	insertQuery := fmt.Sprintf(`
+		INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING %s, %s, %s`,
+		PriceChangesTable,
+		PriceChangeSneakerID,
+		PriceChangePrice,
+		PriceChangeCurrency,
+		PriceChangeDiscountPercent,
+		PriceChangeStartsAt,
+		PriceChangeEndsAt,
+		PriceChangeCreatedBy,
+		PriceChangeID, PriceChangeStatus, PriceChangeCreatedAt,
+	)
+
+	actor := reqctx.Actor(ctx)
+
+	for _, change := range changes {
+		var currency money.Currency
+		err := tx.QueryRow(ctx, lockQuery, change.SneakerID).Scan(&currency)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("sneaker %d not found or deleted", change.SneakerID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock sneaker %d: %w", change.SneakerID, err)
+		}
+
+		// Фиксированная цена задаётся только в валюте товара, без неявной конвертации
+		var price *decimal.Decimal
+		var priceCurrency *string
+		if change.Price != nil {
+			if change.Price.Currency != currency {
+				return fmt.Errorf("sneaker %d: %w: price in %s, sneaker in %s",
+					change.SneakerID, money.ErrCurrencyMismatch, change.Price.Currency, currency)
+			}
+			amount, code := change.Price.Amount, string(change.Price.Currency)
+			price, priceCurrency = &amount, &code
+		}
+
+		var overlapID int64
+		err = tx.QueryRow(ctx, overlapQuery, change.SneakerID, activeStatuses, change.StartsAt, change.EndsAt).Scan(&overlapID)
+		if err == nil {
+			return fmt.Errorf("sneaker %d: period overlaps price change %d", change.SneakerID, overlapID)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("failed to check overlapping price changes: %w", err)
+		}
+
+		change.CreatedBy = actor
+		err = tx.QueryRow(ctx, insertQuery,
+			change.SneakerID,
+			price,
+			priceCurrency,
+			change.DiscountPercent,
+			change.StartsAt,
+			change.EndsAt,
+			change.CreatedBy,
+		).Scan(&change.ID, &change.Status, &change.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert price change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// CancelPriceChanges отменяет запланированные изменения, а действующие
+// сразу откатывает к базовой цене.
+func (s *PostgresStorageImpl) CancelPriceChanges(ctx context.Context, ids []int64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context canceled before starting: %w", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cancelQuery := fmt.Sprintf(`
+		UPDATE %s SET %s = $1
+		WHERE %s = ANY($2) AND %s = ANY($3)`,
+		PriceChangesTable, PriceChangeStatus,
+		PriceChangeID, PriceChangeStatus,
+	)
+
+	tag, err := tx.Exec(ctx, cancelQuery, string(model.PriceChangeCancelled), ids, activeStatuses)
+	if err != nil {
+		return fmt.Errorf("failed to cancel price changes: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("price changes not found or already finished")
+	}
+
+	if _, err := tx.Exec(ctx, revertQuery(SneakersPriceChangeID+" = ANY($1)"), ids); err != nil {
+		return fmt.Errorf("failed to revert cancelled prices: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyPriceChanges - один проход воркера: откатывает закончившиеся изменения,
+// применяет наступившие и помечает пропущенные. Строки берутся с SKIP LOCKED,
+// поэтому несколько реплик сервиса не мешают друг другу.
+func (s *PostgresStorageImpl) ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error) {
+	var run model.PriceChangeRun
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return run, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Откат: сначала, чтобы следующее изменение могло начаться ровно в ends_at предыдущего
+	completeQuery := fmt.Sprintf(`
+		UPDATE %s SET %s = '%s'
+		WHERE %s IN (
+			SELECT %s FROM %s
+			WHERE %s = '%s' AND %s <= $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s`,
+		PriceChangesTable, PriceChangeStatus, model.PriceChangeCompleted,
+		PriceChangeID,
+		PriceChangeID, PriceChangesTable,
+		PriceChangeStatus, model.PriceChangeActive, PriceChangeEndsAt,
+		PriceChangeID,
+	)
+	rows, err := tx.Query(ctx, completeQuery, now)
+	if err != nil {
+		return run, fmt.Errorf("failed to complete price changes: %w", err)
+	}
+	completed, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return run, fmt.Errorf("failed to complete price changes: %w", err)
+	}
+	if len(completed) > 0 {
+		tag, err := tx.Exec(ctx, revertQuery(SneakersPriceChangeID+" = ANY($1)"), completed)
+		if err != nil {
+			return run, fmt.Errorf("failed to revert prices: %w", err)
+		}
+		run.Reverted = tag.RowsAffected()
+	}
+
+	// Применение: скидка считается от базовой цены на момент начала периода,
+	// фиксированная цена в чужой валюте не применяется и со временем истекает
+	applyQuery := fmt.Sprintf(`
+		WITH due AS (
+			SELECT %[1]s, %[2]s, %[3]s, %[4]s, %[5]s, %[18]s FROM %[6]s
+			WHERE %[7]s = '%[8]s' AND %[9]s <= $1 AND %[5]s > $1
+			FOR UPDATE SKIP LOCKED
+		), applied AS (
+			UPDATE %[10]s AS s SET
+				%[11]s = COALESCE(due.%[3]s, ROUND(s.%[12]s * (100 - due.%[4]s) / 100, 2)),
+				%[13]s = due.%[5]s,
+				%[14]s = due.%[1]s
+			FROM due
+			WHERE s.%[15]s = due.%[2]s AND s.%[16]s IS NULL
+				AND (due.%[3]s IS NULL OR due.%[18]s = s.%[19]s)
+			RETURNING due.%[1]s
+		)
+		UPDATE %[6]s AS pc SET %[7]s = '%[17]s'
+		FROM applied WHERE pc.%[1]s = applied.%[1]s`,
+		PriceChangeID, PriceChangeSneakerID, PriceChangePrice, PriceChangeDiscountPercent, PriceChangeEndsAt,
+		PriceChangesTable,
+		PriceChangeStatus, model.PriceChangeScheduled, PriceChangeStartsAt,
+		SneakersTable,
+		SneakersScheduledPrice, SneakersPrice,
+		SneakersScheduledEndsAt, SneakersPriceChangeID,
+		SneakersID, SneakersDeletedAt,
+		model.PriceChangeActive,
+		PriceChangeCurrency, SneakersCurrency,
+	)
+	tag, err := tx.Exec(ctx, applyQuery, now)
+	if err != nil {
+		return run, fmt.Errorf("failed to apply price changes: %w", err)
+	}
+	run.Applied = tag.RowsAffected()
+
+	// Период прошёл целиком, пока сервис не работал
+	expireQuery := fmt.Sprintf(`UPDATE %s SET %s = '%s' WHERE %s = '%s' AND %s <= $1`,
+		PriceChangesTable, PriceChangeStatus, model.PriceChangeExpired,
+		PriceChangeStatus, model.PriceChangeScheduled, PriceChangeEndsAt,
+	)
+	tag, err = tx.Exec(ctx, expireQuery, now)
+	if err != nil {
+		return run, fmt.Errorf("failed to expire price changes: %w", err)
+	}
+	run.Expired = tag.RowsAffected()
+
+	if err := tx.Commit(ctx); err != nil {
+		return run, fmt.Errorf("transaction commit failed: %w", err)
+	}
+
+	return run, nil
+}
+
+// GetPriceHistory возвращает историю цены товара от новых точек к старым.
+func (s *PostgresStorageImpl) GetPriceHistory(ctx context.Context, filter model.PriceHistoryFilter, pagination model.Pagination) ([]model.PriceHistoryEntry, error) {
+	queryBuilder := s.sq.Select(
+		PriceHistoryID,
+		PriceHistorySneakerID,
+		PriceHistoryPrice,
+		PriceHistoryEffectivePrice,
+		PriceHistoryCurrency,
+		PriceHistoryPriceChangeID,
+		PriceHistoryChangedAt,
+	).From(PriceHistoryTable).Where(squirrel.Eq{PriceHistorySneakerID: filter.SneakerID})
+
+	if !filter.From.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.GtOrEq{PriceHistoryChangedAt: filter.From})
+	}
+	if !filter.To.IsZero() {
+		queryBuilder = queryBuilder.Where(squirrel.Lt{PriceHistoryChangedAt: filter.To})
+	}
+
+	queryBuilder = queryBuilder.OrderBy(PriceHistoryChangedAt+" DESC", PriceHistoryID+" DESC")
+	if pagination.Limit > 0 {
+		queryBuilder = queryBuilder.Limit(uint64(pagination.Limit))
+	}
+	if pagination.Offset > 0 {
+		queryBuilder = queryBuilder.Offset(uint64(pagination.Offset))
+	}
+
+	sql, args, err := queryBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при построении SQL-запроса: %w", err)
+	}
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса к БД: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.PriceHistoryEntry])
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сканировании результатов: %w", err)
+	}
+
+	return entries, nil
+}
+
+// revertQuery возвращает товарам, подходящим под where, базовую цену.
+func revertQuery(where string) string {
+	return fmt.Sprintf(`UPDATE %s SET %s = NULL, %s = NULL, %s = NULL WHERE %s`,
+		SneakersTable,
+		SneakersScheduledPrice, SneakersScheduledEndsAt, SneakersPriceChangeID,
+		where,
+	)
+}
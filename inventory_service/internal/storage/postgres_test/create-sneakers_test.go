@@ -8,6 +8,9 @@ import (
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/money"
+	"github.com/kripst/krosovka/inventory_service/internal/sizing"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -20,8 +23,8 @@ func TestCreateSneakers_Success(t *testing.T) {
 	storage := NewMockPostgresStorageImpl(TestDbPool, zap.NewNop())
 
 	sneakersToCreate := []*model.Sneaker{
-		{ID: 1, Article: "ART-001", SneakerName: "Runner Pro", Price: 150.00, Brand: "Nike"},
-		{ID: 2, Article: "ART-002", SneakerName: "Classic", Price: 120.50, Brand: "Adidas"},
+		{ID: 1, Article: "ART-001", SneakerName: "Runner Pro", Price: decimal.RequireFromString("150.00"), Currency: money.RUB, Size: 42, SizeSystem: sizing.EU, Brand: "Nike"},
+		{ID: 2, Article: "ART-002", SneakerName: "Classic", Price: decimal.RequireFromString("120.50"), Currency: money.RUB, Size: 42, SizeSystem: sizing.EU, Brand: "Adidas"},
 	}
 
 	// Очистка таблицы после теста
@@ -79,8 +82,8 @@ func TestCreateSneakers_Failure_DuplicateKey(t *testing.T) {
 	storage := NewMockPostgresStorageImpl(TestDbPool, zap.NewNop())
 
 	sneakersToCreate := []*model.Sneaker{
-		{ID: 10, Article: "ART-010", SneakerName: "Duplicate Test 1", Price: 99.99, Brand: "Puma"},
-		{ID: 10, Article: "ART-011", SneakerName: "Duplicate Test 2", Price: 99.99, Brand: "Puma"},
+		{ID: 10, Article: "ART-010", SneakerName: "Duplicate Test 1", Price: decimal.RequireFromString("99.99"), Currency: money.RUB, Size: 42, SizeSystem: sizing.EU, Brand: "Puma"},
+		{ID: 10, Article: "ART-011", SneakerName: "Duplicate Test 2", Price: decimal.RequireFromString("99.99"), Currency: money.RUB, Size: 42, SizeSystem: sizing.EU, Brand: "Puma"},
 	}
 	t.Cleanup(func() {
 		_, err := TestDbPool.Exec(ctx, "TRUNCATE TABLE sneakers RESTART IDENTITY CASCADE")
@@ -117,7 +120,7 @@ func TestCreateSneakers_Failure_ContextCanceled(t *testing.T) {
 	cancel()
 
 	sneakersToCreate := []*model.Sneaker{
-		{ID: 20, Article: "ART-020", SneakerName: "Context Test", Price: 50.00, Brand: "Reebok"},
+		{ID: 20, Article: "ART-020", SneakerName: "Context Test", Price: decimal.RequireFromString("50.00"), Currency: money.RUB, Size: 42, SizeSystem: sizing.EU, Brand: "Reebok"},
 	}
 
 	// --- Act ---
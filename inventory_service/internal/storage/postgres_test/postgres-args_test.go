@@ -9,7 +9,9 @@ const (
 	SneakersName              = "sneaker_name"
 	SneakersDescription       = "sneaker_description"
 	SneakersPrice             = "price"
+	SneakersCurrency          = "currency"
 	SneakersSize              = "size"
+	SneakersSizeSystem        = "size_system"
 	SneakersBrand             = "brand"
 	SneakersProductionAddress = "production_address"
 	SneakersCreatedAt         = "created_at"
@@ -32,9 +32,9 @@ func (s *MockPostgresStorageImpl) CreateSneakers(ctx context.Context, sneakers [
     // SQL запрос с включением ID
     query := fmt.Sprintf(`
         INSERT INTO %s (
-            %s, %s, %s, %s, %s, %s, %s, %s
+            %s, %s, %s, %s, %s, %s, %s, %s, %s, %s
         ) VALUES (
-            $1, $2, $3, $4, $5, $6, $7, $8
+            $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
         )`,
         SneakersTable,
         SneakersID,
@@ -42,9 +42,11 @@ func (s *MockPostgresStorageImpl) CreateSneakers(ctx context.Context, sneakers [
         SneakersName,
         SneakersDescription,
         SneakersPrice,
+        SneakersCurrency,
         SneakersSize,
         SneakersBrand,
         SneakersProductionAddress,
+        SneakersSizeSystem,
     )
 
     batch := &pgx.Batch{}
@@ -54,8 +56,8 @@ func (s *MockPostgresStorageImpl) CreateSneakers(ctx context.Context, sneakers [
             return fmt.Errorf("context canceled during batch preparation: %w", err)
         }
 
-        if sneaker.Price <= float64(0) {
-            s.log.Error("Price belong or eq zero", zap.Float64("Price", sneaker.Price), zap.String("ID", SneakersID))
+        if !sneaker.Price.IsPositive() {
+            s.log.Error("Price belong or eq zero", zap.Stringer("Price", sneaker.BasePrice()), zap.String("ID", SneakersID))
             return fmt.Errorf("Price belong or eq zero")
         }
         
@@ -65,9 +67,11 @@ func (s *MockPostgresStorageImpl) CreateSneakers(ctx context.Context, sneakers [
             sneaker.SneakerName,
             sneaker.SneakerDescription,
             sneaker.Price,
+            sneaker.Currency,
             sneaker.Size,
             sneaker.Brand,
             sneaker.ProductionAddress,
+            sneaker.SizeSystem,
         )
     }
 
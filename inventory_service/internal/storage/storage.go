@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/kripst/krosovka/inventory_service/internal/model"
 )
@@ -10,6 +11,20 @@ type Storage interface {
 	CreateSneakers(ctx context.Context, sneakers []*model.Sneaker) error
 	UpdateSneakers(ctx context.Context, sneakers []*model.Sneaker) error
 	DeleteSneakers(ctx context.Context, sneakerIDs []int32) error
-	GetSneakers(ctx context.Context) ([]*model.Sneaker, error)
+	RestoreSneakers(ctx context.Context, sneakerIDs []int32) error
+	PurgeSneakers(ctx context.Context, sneakerIDs []int32) error
+	GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) ([]model.Sneaker, error)
+	GetAuditLog(ctx context.Context, filter model.AuditFilter, pagination model.Pagination) ([]model.AuditEntry, error)
+	SchedulePriceChanges(ctx context.Context, changes []*model.PriceChange) error
+	CancelPriceChanges(ctx context.Context, priceChangeIDs []int64) error
+	ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error)
+	GetPriceHistory(ctx context.Context, filter model.PriceHistoryFilter, pagination model.Pagination) ([]model.PriceHistoryEntry, error)
+	SaveExchangeRates(ctx context.Context, rates []model.ExchangeRate) error
+	GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error)
+	CreateOrder(ctx context.Context, order *model.Order) error
+	GetOrder(ctx context.Context, orderID int64) (model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter, pagination model.Pagination) ([]model.Order, error)
+	TransitionOrder(ctx context.Context, change model.OrderStatusChange) (model.Order, error)
+	Ping(ctx context.Context) error
 	Close() error
 }
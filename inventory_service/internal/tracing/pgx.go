@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer пишет спан на каждый SQL-запрос и запрос внутри батча.
+// Подключается через pgx.ConnConfig.Tracer.
+type QueryTracer struct{}
+
+var (
+	_ pgx.QueryTracer = QueryTracer{}
+	_ pgx.BatchTracer = QueryTracer{}
+)
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = startQuerySpan(ctx, data.SQL)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	endQuerySpan(span, data.CommandTag.RowsAffected(), data.Err)
+	span.End()
+}
+
+func (QueryTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx, span := Tracer().Start(ctx, "db batch", trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.Int("db.batch.size", data.Batch.Len()),
+	)
+	return ctx
+}
+
+func (QueryTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	_, span := startQuerySpan(ctx, data.SQL)
+	endQuerySpan(span, data.CommandTag.RowsAffected(), data.Err)
+	span.End()
+}
+
+func (QueryTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	span := trace.SpanFromContext(ctx)
+	recordError(span, data.Err)
+	span.End()
+}
+
+func startQuerySpan(ctx context.Context, sql string) (context.Context, trace.Span) {
+	statement := strings.Join(strings.Fields(sql), " ")
+	operation := statementName(statement)
+
+	return Tracer().Start(ctx, "db "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.statement", statement),
+		),
+	)
+}
+
+func endQuerySpan(span trace.Span, rows int64, err error) {
+	span.SetAttributes(attribute.Int64("db.rows_affected", rows))
+	recordError(span, err)
+}
+
+func recordError(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// statementName - первое ключевое слово запроса: SELECT, INSERT, ...
+func statementName(statement string) string {
+	name, _, _ := strings.Cut(statement, " ")
+	if name == "" {
+		return "query"
+	}
+	return strings.ToUpper(name)
+}
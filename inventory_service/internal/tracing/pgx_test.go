@@ -0,0 +1,70 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kripst/krosovka/inventory_service/internal/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	return recorder
+}
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	out := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		out[kv.Key] = kv.Value
+	}
+	return out
+}
+
+// Спан запроса содержит SQL, имя операции и количество строк.
+func TestQueryTracer_Query(t *testing.T) {
+	require := require.New(t)
+	recorder := setupRecorder(t)
+	tracer := tracing.QueryTracer{}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{
+		SQL: "\n\t\tUPDATE sneakers\n\t\tSET deleted_at = CURRENT_TIMESTAMP WHERE id = ANY($1)",
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+
+	spans := recorder.Ended()
+	require.Len(spans, 1)
+	require.Equal("db UPDATE", spans[0].Name())
+	a := attrs(spans[0])
+	require.Equal("UPDATE sneakers SET deleted_at = CURRENT_TIMESTAMP WHERE id = ANY($1)", a["db.statement"].AsString())
+	require.Equal(int64(3), a["db.rows_affected"].AsInt64())
+}
+
+func TestQueryTracer_Error(t *testing.T) {
+	require := require.New(t)
+	recorder := setupRecorder(t)
+	tracer := tracing.QueryTracer{}
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	spans := recorder.Ended()
+	require.Len(spans, 1)
+	require.Equal(codes.Error, spans[0].Status().Code)
+	require.Equal("connection reset", spans[0].Status().Description)
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/kripst/krosovka/inventory_service/internal/model"
+	"github.com/kripst/krosovka/inventory_service/internal/storage"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedStorage оборачивает каждый метод хранилища в спан, внутри
+// которого оказываются спаны SQL-запросов от QueryTracer.
+type tracedStorage struct {
+	next storage.Storage
+}
+
+func WrapStorage(next storage.Storage) storage.Storage {
+	return &tracedStorage{next: next}
+}
+
+func startStorageSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "storage."+method)
+}
+
+func (s *tracedStorage) CreateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
+	ctx, span := startStorageSpan(ctx, "CreateSneakers")
+	defer span.End()
+	err := s.next.CreateSneakers(ctx, sneakers)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) UpdateSneakers(ctx context.Context, sneakers []*model.Sneaker) error {
+	ctx, span := startStorageSpan(ctx, "UpdateSneakers")
+	defer span.End()
+	err := s.next.UpdateSneakers(ctx, sneakers)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) DeleteSneakers(ctx context.Context, sneakerIDs []int32) error {
+	ctx, span := startStorageSpan(ctx, "DeleteSneakers")
+	defer span.End()
+	err := s.next.DeleteSneakers(ctx, sneakerIDs)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) RestoreSneakers(ctx context.Context, sneakerIDs []int32) error {
+	ctx, span := startStorageSpan(ctx, "RestoreSneakers")
+	defer span.End()
+	err := s.next.RestoreSneakers(ctx, sneakerIDs)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) PurgeSneakers(ctx context.Context, sneakerIDs []int32) error {
+	ctx, span := startStorageSpan(ctx, "PurgeSneakers")
+	defer span.End()
+	err := s.next.PurgeSneakers(ctx, sneakerIDs)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) GetSneakers(ctx context.Context, filter model.SneakerFilters, pagination model.Pagination) ([]model.Sneaker, error) {
+	ctx, span := startStorageSpan(ctx, "GetSneakers")
+	defer span.End()
+	sneakers, err := s.next.GetSneakers(ctx, filter, pagination)
+	recordError(span, err)
+	return sneakers, err
+}
+
+func (s *tracedStorage) GetAuditLog(ctx context.Context, filter model.AuditFilter, pagination model.Pagination) ([]model.AuditEntry, error) {
+	ctx, span := startStorageSpan(ctx, "GetAuditLog")
+	defer span.End()
+	entries, err := s.next.GetAuditLog(ctx, filter, pagination)
+	recordError(span, err)
+	return entries, err
+}
+
+func (s *tracedStorage) SchedulePriceChanges(ctx context.Context, changes []*model.PriceChange) error {
+	ctx, span := startStorageSpan(ctx, "SchedulePriceChanges")
+	defer span.End()
+	err := s.next.SchedulePriceChanges(ctx, changes)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) CancelPriceChanges(ctx context.Context, priceChangeIDs []int64) error {
+	ctx, span := startStorageSpan(ctx, "CancelPriceChanges")
+	defer span.End()
+	err := s.next.CancelPriceChanges(ctx, priceChangeIDs)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) ApplyPriceChanges(ctx context.Context, now time.Time) (model.PriceChangeRun, error) {
+	ctx, span := startStorageSpan(ctx, "ApplyPriceChanges")
+	defer span.End()
+	run, err := s.next.ApplyPriceChanges(ctx, now)
+	recordError(span, err)
+	return run, err
+}
+
+func (s *tracedStorage) GetPriceHistory(ctx context.Context, filter model.PriceHistoryFilter, pagination model.Pagination) ([]model.PriceHistoryEntry, error) {
+	ctx, span := startStorageSpan(ctx, "GetPriceHistory")
+	defer span.End()
+	entries, err := s.next.GetPriceHistory(ctx, filter, pagination)
+	recordError(span, err)
+	return entries, err
+}
+
+func (s *tracedStorage) SaveExchangeRates(ctx context.Context, rates []model.ExchangeRate) error {
+	ctx, span := startStorageSpan(ctx, "SaveExchangeRates")
+	defer span.End()
+	err := s.next.SaveExchangeRates(ctx, rates)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	ctx, span := startStorageSpan(ctx, "GetExchangeRates")
+	defer span.End()
+	rates, err := s.next.GetExchangeRates(ctx)
+	recordError(span, err)
+	return rates, err
+}
+
+func (s *tracedStorage) CreateOrder(ctx context.Context, order *model.Order) error {
+	ctx, span := startStorageSpan(ctx, "CreateOrder")
+	defer span.End()
+	err := s.next.CreateOrder(ctx, order)
+	recordError(span, err)
+	return err
+}
+
+func (s *tracedStorage) GetOrder(ctx context.Context, orderID int64) (model.Order, error) {
+	ctx, span := startStorageSpan(ctx, "GetOrder")
+	defer span.End()
+	order, err := s.next.GetOrder(ctx, orderID)
+	recordError(span, err)
+	return order, err
+}
+
+func (s *tracedStorage) ListOrders(ctx context.Context, filter model.OrderFilter, pagination model.Pagination) ([]model.Order, error) {
+	ctx, span := startStorageSpan(ctx, "ListOrders")
+	defer span.End()
+	orders, err := s.next.ListOrders(ctx, filter, pagination)
+	recordError(span, err)
+	return orders, err
+}
+
+func (s *tracedStorage) TransitionOrder(ctx context.Context, change model.OrderStatusChange) (model.Order, error) {
+	ctx, span := startStorageSpan(ctx, "TransitionOrder")
+	defer span.End()
+	order, err := s.next.TransitionOrder(ctx, change)
+	recordError(span, err)
+	return order, err
+}
+
+func (s *tracedStorage) Ping(ctx context.Context) error {
+	return s.next.Ping(ctx)
+}
+
+func (s *tracedStorage) Close() error {
+	return s.next.Close()
+}
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS trigger_sneakers_audit_append_only ON sneakers_audit;
+DROP FUNCTION IF EXISTS sneakers_audit_append_only();
+
+DROP INDEX IF EXISTS idx_sneakers_audit_created_at;
+DROP INDEX IF EXISTS idx_sneakers_audit_actor;
+DROP INDEX IF EXISTS idx_sneakers_audit_article;
+DROP INDEX IF EXISTS idx_sneakers_audit_sneaker_id;
+
+DROP TABLE IF EXISTS sneakers_audit;
@@ -0,0 +1,32 @@
+-- Append-only audit trail of catalog changes
+CREATE TABLE IF NOT EXISTS sneakers_audit
+(
+    id BIGSERIAL PRIMARY KEY,
+    sneaker_id INTEGER NOT NULL,                 -- No FK: history must outlive purged rows
+    article VARCHAR(50) NOT NULL,
+    action VARCHAR(16) NOT NULL
+        CHECK (action IN ('create', 'update', 'delete', 'restore', 'purge')),
+    actor VARCHAR(255) NOT NULL DEFAULT '',      -- Authenticated caller
+    request_id VARCHAR(64) NOT NULL DEFAULT '',  -- Correlation ID
+    snapshot_before JSONB,                       -- Row before the change (NULL for create)
+    snapshot_after JSONB,                        -- Row after the change (NULL for purge)
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sneakers_audit_sneaker_id ON sneakers_audit (sneaker_id, created_at);
+CREATE INDEX idx_sneakers_audit_article ON sneakers_audit (article, created_at);
+CREATE INDEX idx_sneakers_audit_actor ON sneakers_audit (actor, created_at);
+CREATE INDEX idx_sneakers_audit_created_at ON sneakers_audit (created_at);
+
+-- Forbid rewriting history
+CREATE OR REPLACE FUNCTION sneakers_audit_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'sneakers_audit is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sneakers_audit_append_only
+BEFORE UPDATE OR DELETE ON sneakers_audit
+FOR EACH ROW
+EXECUTE FUNCTION sneakers_audit_append_only();
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS trigger_sneakers_price_history ON sneakers;
+DROP FUNCTION IF EXISTS record_price_history();
+DROP TABLE IF EXISTS price_history;
+
+ALTER TABLE sneakers
+    DROP CONSTRAINT IF EXISTS fk_sneakers_price_change,
+    DROP COLUMN IF EXISTS price_change_id,
+    DROP COLUMN IF EXISTS scheduled_ends_at,
+    DROP COLUMN IF EXISTS scheduled_price;
+
+DROP TABLE IF EXISTS price_changes;
@@ -0,0 +1,68 @@
+-- Scheduled price override applied by the price worker.
+-- price stays the base (original) price, customers pay COALESCE(scheduled_price, price)
+ALTER TABLE sneakers
+    ADD COLUMN scheduled_price NUMERIC(10, 2) CHECK (scheduled_price > 0),
+    ADD COLUMN scheduled_ends_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN price_change_id BIGINT;
+
+-- Scheduled price changes ("-20% from Friday to Sunday")
+CREATE TABLE price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    sneaker_id INTEGER NOT NULL REFERENCES sneakers (id) ON DELETE CASCADE,
+    price NUMERIC(10, 2) CHECK (price > 0),                                        -- Fixed price, or
+    discount_percent NUMERIC(5, 2) CHECK (discount_percent > 0 AND discount_percent < 100), -- percent off the base price
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'active', 'completed', 'cancelled', 'expired')),
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((price IS NULL) <> (discount_percent IS NULL)),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_price_changes_sneaker ON price_changes (sneaker_id, starts_at);
+CREATE INDEX idx_price_changes_due ON price_changes (starts_at) WHERE status = 'scheduled';
+CREATE INDEX idx_price_changes_active ON price_changes (ends_at) WHERE status = 'active';
+
+ALTER TABLE sneakers
+    ADD CONSTRAINT fk_sneakers_price_change
+    FOREIGN KEY (price_change_id) REFERENCES price_changes (id) ON DELETE SET NULL;
+
+-- Every change of the base or effective price. No FK to sneakers so history
+-- survives purge, same as sneakers_audit.
+CREATE TABLE price_history (
+    id BIGSERIAL PRIMARY KEY,
+    sneaker_id INTEGER NOT NULL,
+    price NUMERIC(10, 2) NOT NULL,            -- Base price
+    effective_price NUMERIC(10, 2) NOT NULL,  -- Price customers pay
+    price_change_id BIGINT,                   -- Scheduled change in effect, if any
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_price_history_sneaker ON price_history (sneaker_id, changed_at DESC);
+
+CREATE OR REPLACE FUNCTION record_price_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.price IS NOT DISTINCT FROM OLD.price
+        AND NEW.scheduled_price IS NOT DISTINCT FROM OLD.scheduled_price THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO price_history (sneaker_id, price, effective_price, price_change_id)
+    VALUES (NEW.id, NEW.price, COALESCE(NEW.scheduled_price, NEW.price), NEW.price_change_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sneakers_price_history
+AFTER INSERT OR UPDATE OF price, scheduled_price ON sneakers
+FOR EACH ROW
+EXECUTE FUNCTION record_price_history();
+
+-- Current prices become the first history point
+INSERT INTO price_history (sneaker_id, price, effective_price, changed_at)
+SELECT id, price, price, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+FROM sneakers;
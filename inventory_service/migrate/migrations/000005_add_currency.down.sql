@@ -0,0 +1,27 @@
+DROP TRIGGER IF EXISTS trigger_sneakers_price_history ON sneakers;
+
+CREATE OR REPLACE FUNCTION record_price_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.price IS NOT DISTINCT FROM OLD.price
+        AND NEW.scheduled_price IS NOT DISTINCT FROM OLD.scheduled_price THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO price_history (sneaker_id, price, effective_price, price_change_id)
+    VALUES (NEW.id, NEW.price, COALESCE(NEW.scheduled_price, NEW.price), NEW.price_change_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_sneakers_price_history
+AFTER INSERT OR UPDATE OF price, scheduled_price ON sneakers
+FOR EACH ROW
+EXECUTE FUNCTION record_price_history();
+
+ALTER TABLE price_history DROP COLUMN IF EXISTS currency;
+ALTER TABLE price_changes
+    DROP CONSTRAINT IF EXISTS price_changes_currency_check,
+    DROP COLUMN IF EXISTS currency;
+ALTER TABLE sneakers DROP COLUMN IF EXISTS currency;
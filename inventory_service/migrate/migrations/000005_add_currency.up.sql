@@ -0,0 +1,38 @@
+-- Prices are stored as NUMERIC together with an ISO 4217 currency code.
+-- Existing rows were priced in RUB; new rows must state the currency explicitly.
+ALTER TABLE sneakers
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE sneakers ALTER COLUMN currency DROP DEFAULT;
+
+-- Currency of the fixed price, NULL for percent discounts
+ALTER TABLE price_changes
+    ADD COLUMN currency CHAR(3) CHECK (currency ~ '^[A-Z]{3}$');
+UPDATE price_changes SET currency = 'RUB' WHERE price IS NOT NULL;
+ALTER TABLE price_changes
+    ADD CONSTRAINT price_changes_currency_check CHECK ((price IS NULL) = (currency IS NULL));
+
+ALTER TABLE price_history
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE price_history ALTER COLUMN currency DROP DEFAULT;
+
+CREATE OR REPLACE FUNCTION record_price_history()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.price IS NOT DISTINCT FROM OLD.price
+        AND NEW.scheduled_price IS NOT DISTINCT FROM OLD.scheduled_price
+        AND NEW.currency IS NOT DISTINCT FROM OLD.currency THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO price_history (sneaker_id, price, effective_price, currency, price_change_id)
+    VALUES (NEW.id, NEW.price, COALESCE(NEW.scheduled_price, NEW.price), NEW.currency, NEW.price_change_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_sneakers_price_history ON sneakers;
+CREATE TRIGGER trigger_sneakers_price_history
+AFTER INSERT OR UPDATE OF price, scheduled_price, currency ON sneakers
+FOR EACH ROW
+EXECUTE FUNCTION record_price_history();
@@ -0,0 +1,12 @@
+-- Reference exchange rates for displaying prices in other currencies.
+-- 1 unit of base_currency costs rate units of quote_currency.
+CREATE TABLE exchange_rates (
+    base_currency CHAR(3) NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency CHAR(3) NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    source VARCHAR(16) NOT NULL DEFAULT 'admin',
+    updated_by VARCHAR(255) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency),
+    CHECK (base_currency <> quote_currency)
+);
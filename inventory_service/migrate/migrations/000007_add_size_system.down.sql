@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_sneakers_size;
+ALTER TABLE sneakers DROP COLUMN IF EXISTS size_system;
+
+CREATE INDEX idx_sneakers_size ON sneakers (size);
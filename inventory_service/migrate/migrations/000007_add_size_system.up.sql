@@ -0,0 +1,10 @@
+-- Sizes are stored in the system they were entered in, conversions use brand size charts.
+-- Existing rows were entered in EU sizes.
+ALTER TABLE sneakers
+    ADD COLUMN size_system VARCHAR(4) NOT NULL DEFAULT 'EU'
+        CHECK (size_system IN ('EU', 'US_M', 'US_W', 'UK', 'CM'));
+ALTER TABLE sneakers ALTER COLUMN size_system DROP DEFAULT;
+
+-- Replaces the size-only index from 000001: lookups filter by system first.
+DROP INDEX IF EXISTS idx_sneakers_size;
+CREATE INDEX idx_sneakers_size ON sneakers (size_system, size);
@@ -0,0 +1,49 @@
+-- Orders and their lines. Lines keep the article, size and price at the time
+-- of sale and have no FK to sneakers, so orders outlive purged rows.
+CREATE TABLE orders (
+    id BIGSERIAL PRIMARY KEY,
+    customer_id VARCHAR(64) NOT NULL,            -- Caller-defined key, e.g. tg:<chat_id>
+    customer_name VARCHAR(255) NOT NULL,
+    customer_phone VARCHAR(32) NOT NULL DEFAULT '',
+    customer_address TEXT NOT NULL DEFAULT '',
+    total NUMERIC(14, 2) NOT NULL CHECK (total >= 0),
+    currency CHAR(3) NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    status VARCHAR(16) NOT NULL DEFAULT 'created'
+        CHECK (status IN ('created', 'paid', 'shipped', 'delivered', 'cancelled', 'returned')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_orders_customer ON orders (customer_id, created_at DESC);
+CREATE INDEX idx_orders_status ON orders (status, created_at);
+
+CREATE TABLE order_lines (
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    line_no SMALLINT NOT NULL,
+    sneaker_id INTEGER NOT NULL,                 -- No FK: the order must outlive purged rows
+    article VARCHAR(50) NOT NULL,
+    sneaker_name VARCHAR(255) NOT NULL DEFAULT '',
+    size DECIMAL(3, 1) NOT NULL,
+    size_system VARCHAR(4) NOT NULL
+        CHECK (size_system IN ('EU', 'US_M', 'US_W', 'UK', 'CM')),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price NUMERIC(10, 2) NOT NULL CHECK (price > 0), -- Effective price when the order was created
+    PRIMARY KEY (order_id, line_no),
+    UNIQUE (order_id, article)
+);
+
+CREATE INDEX idx_order_lines_article ON order_lines (article);
+
+-- Append-only history of status changes
+CREATE TABLE order_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    from_status VARCHAR(16),                     -- NULL for the created entry
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor VARCHAR(255) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_transitions_order ON order_transitions (order_id, id);
@@ -1,29 +1,156 @@
 package migrate
 
 import (
+	"embed"
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
+	"io/fs"
+	"strings"
+
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
+// Миграции вшиваются в бинарник, чтобы не зависеть от рабочей директории
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const migrationsDir = "migrations"
+
+type Migrator struct {
+	m *gomigrate.Migrate
+}
+
+// Status описывает состояние схемы относительно каталога migrations.
+type Status struct {
+	Version uint
+	Dirty   bool
+	Applied []uint
+	Pending []uint
+}
+
+func NewMigrator(connString string) (*Migrator, error) {
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations source: %w", err)
+	}
+
+	m, err := gomigrate.NewWithSourceInstance("iofs", src, databaseURL(connString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to init migrate: %w", err)
+	}
+
+	return &Migrator{m: m}, nil
+}
+
+func (m *Migrator) Up() error {
+	return ignoreNoChange(m.m.Up())
+}
+
+// Down откатывает n последних миграций.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", n)
+	}
+	return ignoreNoChange(m.m.Steps(-n))
+}
+
+// Goto мигрирует схему вверх или вниз до версии version.
+func (m *Migrator) Goto(version uint) error {
+	return ignoreNoChange(m.m.Migrate(version))
+}
+
+// Force выставляет версию без выполнения миграций и снимает флаг dirty.
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrator) Status() (*Status, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, gomigrate.ErrNilVersion) {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	applied := err == nil
+
+	versions, err := Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Version: version, Dirty: dirty}
+	for _, v := range versions {
+		if applied && v <= version {
+			status.Applied = append(status.Applied, v)
+		} else {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+
+	return status, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Versions возвращает все версии из каталога migrations по возрастанию.
+func Versions() ([]uint, error) {
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations source: %w", err)
+	}
+	defer src.Close()
+
+	return listVersions(src)
+}
+
+func listVersions(src source.Driver) ([]uint, error) {
+	versions := make([]uint, 0)
+
+	v, err := src.First()
+	for err == nil {
+		versions = append(versions, v)
+		v, err = src.Next(v)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	return versions, nil
+}
+
 func RunMigrations(connString string) error {
-	// Формируем команду для выполнения миграций
-	path := "D:/code/first_krosovochniy_site/backend/inventory_service/migrate/migrations"
-	cmd := exec.Command(
-		"migrate",           // Исполняемый файл migrate (должен быть в PATH)
-		"-path", path, // Путь к папке с миграциями
-		"-database", connString, // Строка подключения к БД
-		"up",                   // Направление миграции (вверх)
-	)
-
-	// Перенаправляем вывод команды в текущий процесс
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	// Запускаем команду и ждём завершения
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ошибка при выполнении миграций: %v", err)
+	m, err := NewMigrator(connString)
+	if err != nil {
+		return fmt.Errorf("ошибка при выполнении миграций: %w", err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return fmt.Errorf("ошибка при выполнении миграций: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// databaseURL переводит DSN postgres:// в схему драйвера pgx5://
+func databaseURL(connString string) string {
+	for _, scheme := range []string{"postgres://", "postgresql://"} {
+		if strings.HasPrefix(connString, scheme) {
+			return "pgx5://" + strings.TrimPrefix(connString, scheme)
+		}
+	}
+	return connString
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, gomigrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
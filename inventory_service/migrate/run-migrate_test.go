@@ -0,0 +1,96 @@
+package migrate_test
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kripst/krosovka/inventory_service/migrate"
+	"github.com/stretchr/testify/require"
+)
+
+// Все миграции из каталога migrations должны быть видны и идти по возрастанию.
+func TestVersions(t *testing.T) {
+	require := require.New(t)
+
+	versions, err := migrate.Versions()
+
+	require.NoError(err)
+	require.Equal([]uint{1, 2, 3, 4, 5, 6, 7, 8}, versions)
+}
+
+var (
+	createIndex = regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)\s+ON\s+(\w+)([^;]*);`)
+	dropIndex   = regexp.MustCompile(`(?i)DROP\s+INDEX\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+	dropTable   = regexp.MustCompile(`(?i)DROP\s+TABLE\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+)
+
+type index struct {
+	table, definition string
+}
+
+// applyIndexes повторяет над набором индексов CREATE INDEX, DROP INDEX и DROP TABLE
+// из файла миграции в порядке их следования.
+func applyIndexes(t *testing.T, indexes map[string]index, name string) {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("migrations", name))
+	require.NoError(t, err)
+	sql := regexp.MustCompile(`--[^\n]*`).ReplaceAllString(string(raw), "")
+
+	type statement struct {
+		at    int
+		apply func()
+	}
+	var statements []statement
+	for _, m := range createIndex.FindAllStringSubmatchIndex(sql, -1) {
+		idx, table, def := sql[m[2]:m[3]], sql[m[4]:m[5]], strings.TrimSpace(sql[m[6]:m[7]])
+		statements = append(statements, statement{m[0], func() {
+			_, exists := indexes[idx]
+			require.False(t, exists, "%s: index %s already exists", name, idx)
+			indexes[idx] = index{table: table, definition: def}
+		}})
+	}
+	for _, m := range dropIndex.FindAllStringSubmatchIndex(sql, -1) {
+		idx := sql[m[2]:m[3]]
+		statements = append(statements, statement{m[0], func() { delete(indexes, idx) }})
+	}
+	for _, m := range dropTable.FindAllStringSubmatchIndex(sql, -1) {
+		table := sql[m[2]:m[3]]
+		statements = append(statements, statement{m[0], func() {
+			maps.DeleteFunc(indexes, func(_ string, i index) bool { return i.table == table })
+		}})
+	}
+	slices.SortFunc(statements, func(a, b statement) int { return a.at - b.at })
+	for _, s := range statements {
+		s.apply()
+	}
+}
+
+// Имена индексов не повторяются, а down-миграция возвращает индексы предыдущей версии.
+func TestMigrations_Indexes(t *testing.T) {
+	require := require.New(t)
+	versions, err := migrate.Versions()
+	require.NoError(err)
+
+	files := func(version uint, direction string) string {
+		matches, err := filepath.Glob(filepath.Join("migrations", fmt.Sprintf("%06d_*.%s.sql", version, direction)))
+		require.NoError(err)
+		require.Len(matches, 1)
+		return filepath.Base(matches[0])
+	}
+
+	indexes := make(map[string]index)
+	for _, version := range versions {
+		before := maps.Clone(indexes)
+		applyIndexes(t, indexes, files(version, "up"))
+
+		after := maps.Clone(indexes)
+		applyIndexes(t, after, files(version, "down"))
+		require.Equal(before, after, "down-миграция %d должна вернуть индексы версии %d", version, version-1)
+	}
+}
@@ -19,10 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	InventoryService_CreateSneakers_FullMethodName = "/inventoryservice.InventoryService/CreateSneakers"
-	InventoryService_GetSneakers_FullMethodName    = "/inventoryservice.InventoryService/GetSneakers"
-	InventoryService_UpdateSneakers_FullMethodName = "/inventoryservice.InventoryService/UpdateSneakers"
-	InventoryService_DeleteSneakers_FullMethodName = "/inventoryservice.InventoryService/DeleteSneakers"
+	InventoryService_CreateSneakers_FullMethodName       = "/inventoryservice.InventoryService/CreateSneakers"
+	InventoryService_GetSneakers_FullMethodName          = "/inventoryservice.InventoryService/GetSneakers"
+	InventoryService_UpdateSneakers_FullMethodName       = "/inventoryservice.InventoryService/UpdateSneakers"
+	InventoryService_DeleteSneakers_FullMethodName       = "/inventoryservice.InventoryService/DeleteSneakers"
+	InventoryService_RestoreSneakers_FullMethodName      = "/inventoryservice.InventoryService/RestoreSneakers"
+	InventoryService_PurgeSneakers_FullMethodName        = "/inventoryservice.InventoryService/PurgeSneakers"
+	InventoryService_GetAuditLog_FullMethodName          = "/inventoryservice.InventoryService/GetAuditLog"
+	InventoryService_SchedulePriceChanges_FullMethodName = "/inventoryservice.InventoryService/SchedulePriceChanges"
+	InventoryService_CancelPriceChanges_FullMethodName   = "/inventoryservice.InventoryService/CancelPriceChanges"
+	InventoryService_GetPriceHistory_FullMethodName      = "/inventoryservice.InventoryService/GetPriceHistory"
+	InventoryService_SetExchangeRates_FullMethodName     = "/inventoryservice.InventoryService/SetExchangeRates"
+	InventoryService_GetExchangeRates_FullMethodName     = "/inventoryservice.InventoryService/GetExchangeRates"
 )
 
 // InventoryServiceClient is the client API for InventoryService service.
@@ -33,6 +41,14 @@ type InventoryServiceClient interface {
 	GetSneakers(ctx context.Context, in *GetSneakersRequest, opts ...grpc.CallOption) (*GetSneakersResponse, error)
 	UpdateSneakers(ctx context.Context, in *UpdateSneakersRequest, opts ...grpc.CallOption) (*Response, error)
 	DeleteSneakers(ctx context.Context, in *DeleteSneakersRequest, opts ...grpc.CallOption) (*Response, error)
+	RestoreSneakers(ctx context.Context, in *RestoreSneakersRequest, opts ...grpc.CallOption) (*Response, error)
+	PurgeSneakers(ctx context.Context, in *PurgeSneakersRequest, opts ...grpc.CallOption) (*Response, error)
+	GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error)
+	SchedulePriceChanges(ctx context.Context, in *SchedulePriceChangesRequest, opts ...grpc.CallOption) (*SchedulePriceChangesResponse, error)
+	CancelPriceChanges(ctx context.Context, in *CancelPriceChangesRequest, opts ...grpc.CallOption) (*Response, error)
+	GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error)
+	SetExchangeRates(ctx context.Context, in *SetExchangeRatesRequest, opts ...grpc.CallOption) (*Response, error)
+	GetExchangeRates(ctx context.Context, in *GetExchangeRatesRequest, opts ...grpc.CallOption) (*GetExchangeRatesResponse, error)
 }
 
 type inventoryServiceClient struct {
@@ -83,6 +99,86 @@ func (c *inventoryServiceClient) DeleteSneakers(ctx context.Context, in *DeleteS
 	return out, nil
 }
 
+func (c *inventoryServiceClient) RestoreSneakers(ctx context.Context, in *RestoreSneakersRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, InventoryService_RestoreSneakers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) PurgeSneakers(ctx context.Context, in *PurgeSneakersRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, InventoryService_PurgeSneakers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetAuditLog(ctx context.Context, in *GetAuditLogRequest, opts ...grpc.CallOption) (*GetAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAuditLogResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) SchedulePriceChanges(ctx context.Context, in *SchedulePriceChangesRequest, opts ...grpc.CallOption) (*SchedulePriceChangesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SchedulePriceChangesResponse)
+	err := c.cc.Invoke(ctx, InventoryService_SchedulePriceChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) CancelPriceChanges(ctx context.Context, in *CancelPriceChangesRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, InventoryService_CancelPriceChanges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetPriceHistory(ctx context.Context, in *GetPriceHistoryRequest, opts ...grpc.CallOption) (*GetPriceHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPriceHistoryResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetPriceHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) SetExchangeRates(ctx context.Context, in *SetExchangeRatesRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, InventoryService_SetExchangeRates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *inventoryServiceClient) GetExchangeRates(ctx context.Context, in *GetExchangeRatesRequest, opts ...grpc.CallOption) (*GetExchangeRatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetExchangeRatesResponse)
+	err := c.cc.Invoke(ctx, InventoryService_GetExchangeRates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InventoryServiceServer is the server API for InventoryService service.
 // All implementations must embed UnimplementedInventoryServiceServer
 // for forward compatibility.
@@ -91,6 +187,14 @@ type InventoryServiceServer interface {
 	GetSneakers(context.Context, *GetSneakersRequest) (*GetSneakersResponse, error)
 	UpdateSneakers(context.Context, *UpdateSneakersRequest) (*Response, error)
 	DeleteSneakers(context.Context, *DeleteSneakersRequest) (*Response, error)
+	RestoreSneakers(context.Context, *RestoreSneakersRequest) (*Response, error)
+	PurgeSneakers(context.Context, *PurgeSneakersRequest) (*Response, error)
+	GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error)
+	SchedulePriceChanges(context.Context, *SchedulePriceChangesRequest) (*SchedulePriceChangesResponse, error)
+	CancelPriceChanges(context.Context, *CancelPriceChangesRequest) (*Response, error)
+	GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error)
+	SetExchangeRates(context.Context, *SetExchangeRatesRequest) (*Response, error)
+	GetExchangeRates(context.Context, *GetExchangeRatesRequest) (*GetExchangeRatesResponse, error)
 	mustEmbedUnimplementedInventoryServiceServer()
 }
 
@@ -113,6 +217,30 @@ func (UnimplementedInventoryServiceServer) UpdateSneakers(context.Context, *Upda
 func (UnimplementedInventoryServiceServer) DeleteSneakers(context.Context, *DeleteSneakersRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteSneakers not implemented")
 }
+func (UnimplementedInventoryServiceServer) RestoreSneakers(context.Context, *RestoreSneakersRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSneakers not implemented")
+}
+func (UnimplementedInventoryServiceServer) PurgeSneakers(context.Context, *PurgeSneakersRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSneakers not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetAuditLog(context.Context, *GetAuditLogRequest) (*GetAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
+func (UnimplementedInventoryServiceServer) SchedulePriceChanges(context.Context, *SchedulePriceChangesRequest) (*SchedulePriceChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SchedulePriceChanges not implemented")
+}
+func (UnimplementedInventoryServiceServer) CancelPriceChanges(context.Context, *CancelPriceChangesRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelPriceChanges not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetPriceHistory(context.Context, *GetPriceHistoryRequest) (*GetPriceHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPriceHistory not implemented")
+}
+func (UnimplementedInventoryServiceServer) SetExchangeRates(context.Context, *SetExchangeRatesRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetExchangeRates not implemented")
+}
+func (UnimplementedInventoryServiceServer) GetExchangeRates(context.Context, *GetExchangeRatesRequest) (*GetExchangeRatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetExchangeRates not implemented")
+}
 func (UnimplementedInventoryServiceServer) mustEmbedUnimplementedInventoryServiceServer() {}
 func (UnimplementedInventoryServiceServer) testEmbeddedByValue()                          {}
 
@@ -206,6 +334,150 @@ func _InventoryService_DeleteSneakers_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InventoryService_RestoreSneakers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSneakersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).RestoreSneakers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_RestoreSneakers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).RestoreSneakers(ctx, req.(*RestoreSneakersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_PurgeSneakers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSneakersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).PurgeSneakers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_PurgeSneakers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).PurgeSneakers(ctx, req.(*PurgeSneakersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetAuditLog(ctx, req.(*GetAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_SchedulePriceChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchedulePriceChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).SchedulePriceChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_SchedulePriceChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).SchedulePriceChanges(ctx, req.(*SchedulePriceChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_CancelPriceChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelPriceChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).CancelPriceChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_CancelPriceChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).CancelPriceChanges(ctx, req.(*CancelPriceChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetPriceHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPriceHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetPriceHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetPriceHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetPriceHistory(ctx, req.(*GetPriceHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_SetExchangeRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetExchangeRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).SetExchangeRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_SetExchangeRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).SetExchangeRates(ctx, req.(*SetExchangeRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InventoryService_GetExchangeRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetExchangeRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InventoryServiceServer).GetExchangeRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InventoryService_GetExchangeRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InventoryServiceServer).GetExchangeRates(ctx, req.(*GetExchangeRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InventoryService_ServiceDesc is the grpc.ServiceDesc for InventoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,6 +501,38 @@ var InventoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteSneakers",
 			Handler:    _InventoryService_DeleteSneakers_Handler,
 		},
+		{
+			MethodName: "RestoreSneakers",
+			Handler:    _InventoryService_RestoreSneakers_Handler,
+		},
+		{
+			MethodName: "PurgeSneakers",
+			Handler:    _InventoryService_PurgeSneakers_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _InventoryService_GetAuditLog_Handler,
+		},
+		{
+			MethodName: "SchedulePriceChanges",
+			Handler:    _InventoryService_SchedulePriceChanges_Handler,
+		},
+		{
+			MethodName: "CancelPriceChanges",
+			Handler:    _InventoryService_CancelPriceChanges_Handler,
+		},
+		{
+			MethodName: "GetPriceHistory",
+			Handler:    _InventoryService_GetPriceHistory_Handler,
+		},
+		{
+			MethodName: "SetExchangeRates",
+			Handler:    _InventoryService_SetExchangeRates_Handler,
+		},
+		{
+			MethodName: "GetExchangeRates",
+			Handler:    _InventoryService_GetExchangeRates_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/inventory.proto",
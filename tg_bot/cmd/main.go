@@ -1,5 +1,186 @@
 package main
 
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/config"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/kripst/krosovka/tg_bot/internal/dispatch"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"github.com/kripst/krosovka/tg_bot/internal/inventory"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/kripst/krosovka/tg_bot/internal/payments"
+	"github.com/kripst/krosovka/tg_bot/internal/postgres"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+	"github.com/kripst/krosovka/tg_bot/internal/throttle"
+	"github.com/kripst/krosovka/tg_bot/internal/tracing"
+	"github.com/kripst/krosovka/tg_bot/internal/webhook"
+	"go.uber.org/zap"
+)
+
 func main() {
-	
-}
\ No newline at end of file
+	configPath := flag.String("config", "", "path to YAML config (default $"+config.ConfigPathEnv+")")
+	flag.Parse()
+
+	os.Exit(run(*configPath))
+}
+
+func run(configPath string) int {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: load config:", err)
+		return 1
+	}
+
+	log, err := zap.NewProduction()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: init logger:", err)
+		return 1
+	}
+	defer log.Sync()
+
+	log.Info("config loaded", zap.Any("config", cfg.Redacted()))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Error("ERROR: init tracing", zap.Error(err))
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("ERROR: shutdown tracing", zap.Error(err))
+		}
+	}()
+
+	conn, client, err := inventory.Dial(cfg.Inventory)
+	if err != nil {
+		log.Error("ERROR: connect inventory", zap.Error(err))
+		return 1
+	}
+	defer conn.Close()
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(cfg.Telegram.Token, cfg.Telegram.Endpoint())
+	if err != nil {
+		log.Error("ERROR: connect telegram", zap.Error(err))
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stores, closeStores, err := newStores(ctx, cfg.Storage)
+	if err != nil {
+		log.Error("ERROR: init storage", zap.Error(err))
+		return 1
+	}
+	defer closeStores()
+	go fsm.RunSweeper(ctx, stores.dialogs, cfg.Dialogs.SweepInterval, log)
+
+	b := bot.New(api, client, log, bot.Settings{
+		Admins:        cfg.Admins,
+		Language:      cfg.Locale.Language,
+		Location:      cfg.Location(),
+		Dialogs:       stores.dialogs,
+		DialogTimeout: cfg.Dialogs.Timeout,
+
+		InlineCacheTime: cfg.Telegram.InlineCacheTime,
+
+		Subscriptions: stores.subscriptions,
+		Throttle:      throttle.New(cfg.Notifier.MessagesPerSecond, cfg.Notifier.PerChatInterval),
+		NotifyQuota:   throttle.NewQuota(cfg.Notifier.PerUserPerHour, time.Hour),
+
+		Cart:     stores.cart,
+		Orders:   orders.NewGRPCService(pb.NewOrdersServiceClient(conn)),
+		Payments: newPayments(cfg.Payments),
+	})
+	go b.RunNotifier(ctx, cfg.Notifier.Interval)
+	dispatcher := dispatch.New(b.HandleUpdate, cfg.Telegram.Workers, log)
+
+	code := 0
+	switch cfg.Telegram.Mode {
+	case config.ModeWebhook:
+		code = runWebhook(ctx, cfg, api, dispatcher, log)
+	default:
+		b.RunPolling(ctx, dispatcher, cfg.Telegram.PollTimeout)
+	}
+
+	// Новые обновления больше не принимаются, дожидаемся уже принятых
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Shutdown)
+	defer cancel()
+	if err := dispatcher.Shutdown(shutdownCtx); err != nil {
+		log.Warn("updates were not processed before shutdown timeout", zap.Error(err))
+	}
+
+	log.Info("bot stopped")
+	return code
+}
+
+type stores struct {
+	dialogs       fsm.Store
+	subscriptions subscriptions.Store
+	cart          cart.Store
+}
+
+// newStores - хранилища диалогов, подписок и корзин; postgres нужен, если реплик несколько.
+func newStores(ctx context.Context, cfg config.StorageConfig) (stores, func(), error) {
+	if cfg.Driver != config.StoragePostgres {
+		return stores{
+			dialogs:       fsm.NewMemoryStore(),
+			subscriptions: subscriptions.NewMemoryStore(),
+			cart:          cart.NewMemoryStore(),
+		}, func() {}, nil
+	}
+	pool, err := postgres.Connect(ctx, cfg.DSN)
+	if err != nil {
+		return stores{}, nil, err
+	}
+	return stores{
+		dialogs:       fsm.NewPostgresStore(pool),
+		subscriptions: subscriptions.NewPostgresStore(pool),
+		cart:          cart.NewPostgresStore(pool),
+	}, pool.Close, nil
+}
+
+// newPayments - провайдер счетов для Telegram Payments, nil - оплата в боте выключена.
+func newPayments(cfg config.PaymentsConfig) payments.Provider {
+	if cfg.Provider != config.PaymentsFake {
+		return nil
+	}
+	return payments.NewFakeProvider(payments.FakeConfig{
+		Outcome:       payments.Outcome(cfg.Fake.Outcome),
+		Delay:         cfg.Fake.Delay,
+		ProviderToken: cfg.ProviderToken,
+	})
+}
+
+func runWebhook(ctx context.Context, cfg *config.Config, api *tgbotapi.BotAPI, dispatcher *dispatch.Dispatcher, log *zap.Logger) int {
+	server, err := webhook.New(cfg.Telegram.Webhook, api, dispatcher, log)
+	if err != nil {
+		log.Error("ERROR: init webhook", zap.Error(err))
+		return 1
+	}
+	if err := server.Register(); err != nil {
+		log.Error("ERROR: register webhook", zap.Error(err))
+		return 1
+	}
+
+	// Обработчик вебхука отвечает сразу, так что HTTP останавливается быстро,
+	// основное ожидание - в dispatcher.Shutdown
+	if err := server.Run(ctx, cfg.Timeouts.Shutdown); err != nil {
+		log.Error("ERROR: webhook server", zap.Error(err))
+		return 1
+	}
+	return 0
+}
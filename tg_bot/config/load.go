@@ -1,8 +1,381 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+// ConfigPathEnv задаёт путь к YAML-файлу, если он не передан флагом.
+const ConfigPathEnv = "CONFIG_PATH"
+
+const redacted = "[REDACTED]"
+
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
+const (
+	StorageMemory   = "memory"
+	StoragePostgres = "postgres"
+)
+
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+const (
+	PaymentsNone = "none"
+	PaymentsFake = "fake"
+)
+
+// SupportedLanguages - языки, на которые переведены сообщения бота.
+var SupportedLanguages = []string{"ru", "en"}
+
+// secretTokenPattern - допустимый X-Telegram-Bot-Api-Secret-Token по документации Bot API
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+type TelegramConfig struct {
+	Token string `yaml:"token" env:"TG_TOKEN"`
+	// APIBaseURL - адрес Bot API, в тестах указывает на локальную заглушку
+	APIBaseURL string `yaml:"api_base_url" env:"TG_API_BASE_URL" env-default:"https://api.telegram.org"`
+	Mode       string `yaml:"mode" env:"TG_MODE" env-default:"polling"` // polling | webhook
+	// PollTimeout - таймаут long polling getUpdates
+	PollTimeout time.Duration `yaml:"poll_timeout" env:"TG_POLL_TIMEOUT" env-default:"30s"`
+	// Workers - сколько обновлений обрабатывается одновременно; один чат - всегда по очереди
+	Workers int `yaml:"workers" env:"TG_WORKERS" env-default:"16"`
+	// InlineCacheTime - сколько Telegram и бот кэшируют результаты inline-поиска
+	InlineCacheTime time.Duration `yaml:"inline_cache_time" env:"TG_INLINE_CACHE_TIME" env-default:"1m"`
+	Webhook         WebhookConfig `yaml:"webhook"`
+}
+
+// Endpoint - шаблон адреса метода для tgbotapi (bot<token>/<method>).
+func (c TelegramConfig) Endpoint() string {
+	return strings.TrimRight(c.APIBaseURL, "/") + "/bot%s/%s"
+}
+
+type WebhookConfig struct {
+	// URL - публичный HTTPS-адрес, который регистрируется через setWebhook
+	URL        string `yaml:"url" env:"TG_WEBHOOK_URL"`
+	ListenAddr string `yaml:"listen_addr" env:"TG_WEBHOOK_LISTEN_ADDR" env-default:":8443"`
+	// SecretToken приходит от Telegram в заголовке X-Telegram-Bot-Api-Secret-Token
+	SecretToken string `yaml:"secret_token" env:"TG_WEBHOOK_SECRET_TOKEN"`
+	// MaxConnections - сколько параллельных соединений Telegram открывает к вебхуку, 1..100
+	MaxConnections int `yaml:"max_connections" env:"TG_WEBHOOK_MAX_CONNECTIONS" env-default:"40"`
+}
+
+type InventoryConfig struct {
+	Addr   string `yaml:"addr" env:"INVENTORY_ADDR"`
+	APIKey string `yaml:"api_key" env:"INVENTORY_API_KEY"`
+	// Timeout - дедлайн одного RPC к inventory_service
+	Timeout time.Duration `yaml:"timeout" env:"INVENTORY_TIMEOUT" env-default:"5s"`
+	TLS     TLSConfig     `yaml:"tls"`
+}
+
+// TLSConfig - TLS до inventory_service; сертификат клиента нужен только для mTLS.
+type TLSConfig struct {
+	Enabled            bool   `yaml:"enabled" env:"INVENTORY_TLS_ENABLED" env-default:"false"`
+	CAFile             string `yaml:"ca_file" env:"INVENTORY_TLS_CA_FILE"`
+	CertFile           string `yaml:"cert_file" env:"INVENTORY_TLS_CERT_FILE"`
+	KeyFile            string `yaml:"key_file" env:"INVENTORY_TLS_KEY_FILE"`
+	ServerName         string `yaml:"server_name" env:"INVENTORY_TLS_SERVER_NAME"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" env:"INVENTORY_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+}
+
+type LocaleConfig struct {
+	// Language - язык по умолчанию, если язык пользователя не поддерживается
+	Language string `yaml:"language" env:"BOT_LANGUAGE" env-default:"ru"`
+	// Timezone - часовой пояс для дат в сообщениях
+	Timezone string `yaml:"timezone" env:"BOT_TIMEZONE" env-default:"Europe/Moscow"`
+}
+
+// StorageConfig - где бот хранит диалоги, подписки и корзины.
+type StorageConfig struct {
+	// Driver - memory для одной реплики, postgres - общий для нескольких
+	Driver string `yaml:"driver" env:"TG_STORAGE" env-default:"memory"`
+	DSN    string `yaml:"dsn" env:"TG_STORAGE_DSN"`
+}
+
+// DialogsConfig - многошаговые диалоги (добавление товара, оформление заказа).
+type DialogsConfig struct {
+	// Timeout - время на ответ в шаге диалога
+	Timeout time.Duration `yaml:"timeout" env:"TG_DIALOG_TIMEOUT" env-default:"15m"`
+	// SweepInterval - как часто удалять истёкшие диалоги из хранилища
+	SweepInterval time.Duration `yaml:"sweep_interval" env:"TG_DIALOG_SWEEP_INTERVAL" env-default:"1m"`
+}
+
+// NotifierConfig - уведомления о появлении товара и снижении цены.
+type NotifierConfig struct {
+	// Interval - как часто опрашивать inventory_service об изменениях
+	Interval time.Duration `yaml:"interval" env:"TG_NOTIFY_INTERVAL" env-default:"30s"`
+	// MessagesPerSecond - общий темп рассылки, Telegram допускает до 30 сообщений в секунду.
+	// Делить его между репликами не нужно: рассылку в каждый момент ведёт одна из них
+	MessagesPerSecond int `yaml:"messages_per_second" env:"TG_NOTIFY_MESSAGES_PER_SECOND" env-default:"25"`
+	// PerChatInterval - пауза между сообщениями в один чат
+	PerChatInterval time.Duration `yaml:"per_chat_interval" env:"TG_NOTIFY_PER_CHAT_INTERVAL" env-default:"1s"`
+	// PerUserPerHour - сколько уведомлений пользователь получает в час, остальные откладываются
+	PerUserPerHour int `yaml:"per_user_per_hour" env:"TG_NOTIFY_PER_USER_PER_HOUR" env-default:"20"`
+}
+
+// TracingConfig - экспорт трейсов; RPC к inventory_service продолжают трейс обновления.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"` // none | stdout | otlp
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" env-default:"localhost:4317"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" env-default:"true"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" env-default:"tg_bot"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+// PaymentsConfig - оплата заказов в Telegram Payments.
+type PaymentsConfig struct {
+	// Provider - none: заказ оплачивается после звонка менеджера; fake - локальный
+	// провайдер без настоящего шлюза для разработки и тестового режима Telegram.
+	// fake хранит счета в памяти процесса, поэтому работает только с storage memory
+	Provider string `yaml:"provider" env:"TG_PAYMENTS_PROVIDER" env-default:"none"`
+	// ProviderToken - токен платёжного провайдера из @BotFather для sendInvoice
+	ProviderToken string             `yaml:"provider_token" env:"TG_PAYMENTS_PROVIDER_TOKEN"`
+	Fake          FakePaymentsConfig `yaml:"fake"`
+}
+
+// FakePaymentsConfig - поведение локального провайдера.
+type FakePaymentsConfig struct {
+	// Outcome - success или failure: принимает ли провайдер оплату в pre_checkout_query,
+	// до списания денег
+	Outcome string `yaml:"outcome" env:"TG_PAYMENTS_FAKE_OUTCOME" env-default:"success"`
+	// Delay - задержка каждого ответа провайдера
+	Delay time.Duration `yaml:"delay" env:"TG_PAYMENTS_FAKE_DELAY" env-default:"0s"`
+}
+
+type TimeoutsConfig struct {
+	// Shutdown - сколько ждать обработки принятых обновлений при остановке
+	Shutdown time.Duration `yaml:"shutdown" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+}
 
 type Config struct {
-	TgToken string
-	
+	Telegram  TelegramConfig  `yaml:"telegram"`
+	Inventory InventoryConfig `yaml:"inventory"`
+	// Admins - Telegram ID пользователей с доступом к командам управления каталогом
+	Admins   []int64        `yaml:"admins" env:"TG_ADMIN_IDS" env-separator:","`
+	Locale   LocaleConfig   `yaml:"locale"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Dialogs  DialogsConfig  `yaml:"dialogs"`
+	Notifier NotifierConfig `yaml:"notifier"`
+	Payments PaymentsConfig `yaml:"payments"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+	Timeouts TimeoutsConfig `yaml:"timeouts"`
+}
+
+// Load читает конфиг из YAML-файла path (или CONFIG_PATH), затем
+// переопределяет значения из окружения и подставляет значения по умолчанию.
+// Без файла конфиг собирается только из окружения.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv(ConfigPathEnv)
+	}
+
+	cfg := &Config{}
+	if path != "" {
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, fmt.Errorf("failed to read config %q: %w", path, err)
+		}
+	} else if err := cleanenv.ReadEnv(cfg); err != nil {
+		return nil, fmt.Errorf("failed to read config from env: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate возвращает все найденные ошибки конфигурации разом.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Telegram.Token == "" {
+		fail("telegram.token", "is required (set TG_TOKEN)")
+	} else if !strings.Contains(c.Telegram.Token, ":") {
+		fail("telegram.token", "must look like <bot_id>:<secret>")
+	}
+	if u, err := url.Parse(c.Telegram.APIBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("telegram.api_base_url", "must be an http(s) URL, got %q", c.Telegram.APIBaseURL)
+	}
+	if c.Telegram.PollTimeout < 0 {
+		fail("telegram.poll_timeout", "must not be negative, got %s", c.Telegram.PollTimeout)
+	}
+	if c.Telegram.Workers <= 0 {
+		fail("telegram.workers", "must be positive, got %d", c.Telegram.Workers)
+	}
+	if c.Telegram.InlineCacheTime <= 0 {
+		fail("telegram.inline_cache_time", "must be positive, got %s", c.Telegram.InlineCacheTime)
+	}
+
+	switch c.Telegram.Mode {
+	case ModePolling:
+	case ModeWebhook:
+		w := c.Telegram.Webhook
+		if u, err := url.Parse(w.URL); w.URL == "" || err != nil || u.Scheme != "https" || u.Host == "" {
+			fail("telegram.webhook.url", "is required in webhook mode and must be an https URL, got %q", w.URL)
+		}
+		if _, _, err := net.SplitHostPort(w.ListenAddr); err != nil {
+			fail("telegram.webhook.listen_addr", "must be host:port, got %q", w.ListenAddr)
+		}
+		if !secretTokenPattern.MatchString(w.SecretToken) {
+			fail("telegram.webhook.secret_token", "is required in webhook mode: 1-256 characters A-Z, a-z, 0-9, _ and -")
+		}
+		if w.MaxConnections < 1 || w.MaxConnections > 100 {
+			fail("telegram.webhook.max_connections", "must be in 1..100, got %d", w.MaxConnections)
+		}
+	default:
+		fail("telegram.mode", "must be polling or webhook, got %q", c.Telegram.Mode)
+	}
+
+	if c.Inventory.Addr == "" {
+		fail("inventory.addr", "is required (set INVENTORY_ADDR)")
+	} else if _, _, err := net.SplitHostPort(c.Inventory.Addr); err != nil {
+		fail("inventory.addr", "must be host:port, got %q", c.Inventory.Addr)
+	}
+	if c.Inventory.Timeout <= 0 {
+		fail("inventory.timeout", "must be positive, got %s", c.Inventory.Timeout)
+	}
+	if (c.Inventory.TLS.CertFile == "") != (c.Inventory.TLS.KeyFile == "") {
+		fail("inventory.tls.cert_file", "cert_file and key_file must be set together")
+	}
+	if !c.Inventory.TLS.Enabled && (c.Inventory.TLS.CAFile != "" || c.Inventory.TLS.CertFile != "") {
+		fail("inventory.tls.enabled", "certificates are set, but TLS is disabled")
+	}
+
+	for i, id := range c.Admins {
+		if id <= 0 {
+			fail(fmt.Sprintf("admins[%d]", i), "must be a positive Telegram user ID, got %d", id)
+		}
+	}
+
+	switch c.Storage.Driver {
+	case StorageMemory:
+	case StoragePostgres:
+		if u, err := url.Parse(c.Storage.DSN); c.Storage.DSN == "" || err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			fail("storage.dsn", "is required for postgres and must be a postgres:// URL (set TG_STORAGE_DSN)")
+		}
+	default:
+		fail("storage.driver", "must be memory or postgres, got %q", c.Storage.Driver)
+	}
+	if c.Dialogs.Timeout <= 0 {
+		fail("dialogs.timeout", "must be positive, got %s", c.Dialogs.Timeout)
+	}
+	if c.Dialogs.SweepInterval <= 0 {
+		fail("dialogs.sweep_interval", "must be positive, got %s", c.Dialogs.SweepInterval)
+	}
+
+	if c.Notifier.Interval <= 0 {
+		fail("notifier.interval", "must be positive, got %s", c.Notifier.Interval)
+	}
+	if c.Notifier.MessagesPerSecond < 1 || c.Notifier.MessagesPerSecond > 30 {
+		fail("notifier.messages_per_second", "must be in 1..30, got %d", c.Notifier.MessagesPerSecond)
+	}
+	if c.Notifier.PerChatInterval <= 0 {
+		fail("notifier.per_chat_interval", "must be positive, got %s", c.Notifier.PerChatInterval)
+	}
+	if c.Notifier.PerUserPerHour <= 0 {
+		fail("notifier.per_user_per_hour", "must be positive, got %d", c.Notifier.PerUserPerHour)
+	}
+
+	switch c.Payments.Provider {
+	case PaymentsNone:
+	case PaymentsFake:
+		if c.Storage.Driver == StoragePostgres {
+			fail("payments.provider", "fake keeps invoices in process memory and cannot serve several replicas, use storage.driver memory")
+		}
+		if c.Payments.ProviderToken == "" {
+			fail("payments.provider_token", "is required to send invoices (set TG_PAYMENTS_PROVIDER_TOKEN)")
+		}
+		if c.Payments.Fake.Outcome != "success" && c.Payments.Fake.Outcome != "failure" {
+			fail("payments.fake.outcome", "must be success or failure, got %q", c.Payments.Fake.Outcome)
+		}
+		if c.Payments.Fake.Delay < 0 {
+			fail("payments.fake.delay", "must not be negative, got %s", c.Payments.Fake.Delay)
+		}
+	default:
+		fail("payments.provider", "must be none or fake, got %q", c.Payments.Provider)
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingNone, TracingStdout:
+	case TracingOTLP:
+		if c.Tracing.Endpoint == "" {
+			fail("tracing.endpoint", "is required for otlp exporter")
+		}
+	default:
+		fail("tracing.exporter", "must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio", "must be in 0..1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Timeouts.Shutdown <= 0 {
+		fail("timeouts.shutdown", "must be positive, got %s", c.Timeouts.Shutdown)
+	}
+
+	if !slices.Contains(SupportedLanguages, c.Locale.Language) {
+		fail("locale.language", "must be one of %s, got %q", strings.Join(SupportedLanguages, ", "), c.Locale.Language)
+	}
+	if _, err := time.LoadLocation(c.Locale.Timezone); err != nil {
+		fail("locale.timezone", "unknown time zone %q", c.Locale.Timezone)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Location - часовой пояс из locale.timezone, конфиг должен быть провалидирован.
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Locale.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Redacted возвращает копию конфига без секретов, пригодную для логирования.
+func (c *Config) Redacted() *Config {
+	out := *c
+	out.Telegram.Token = redact(c.Telegram.Token)
+	out.Telegram.Webhook.SecretToken = redact(c.Telegram.Webhook.SecretToken)
+	out.Inventory.APIKey = redact(c.Inventory.APIKey)
+	out.Storage.DSN = redactDSN(c.Storage.DSN)
+	out.Payments.ProviderToken = redact(c.Payments.ProviderToken)
+	return &out
+}
+
+// redactDSN скрывает пароль, адрес БД в логах остаётся виден.
+func redactDSN(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return redact(dsn)
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	return u.String()
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
 }
-func Load() (*Config, error)
\ No newline at end of file
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/config"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// Значения из файла переопределяются окружением, пустые поля получают значения по умолчанию.
+func TestLoad_FileEnvDefaults(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, `
+telegram:
+  token: "1:from-file"
+  api_base_url: http://127.0.0.1:8081/
+inventory:
+  addr: inventory:50051
+admins: [10, 20]
+`)
+	t.Setenv("TG_TOKEN", "1:from-env")
+
+	cfg, err := config.Load(path)
+
+	require.NoError(err)
+	require.Equal("1:from-env", cfg.Telegram.Token)
+	require.Equal("http://127.0.0.1:8081/bot%s/%s", cfg.Telegram.Endpoint())
+	require.Equal(config.ModePolling, cfg.Telegram.Mode)
+	require.Equal(30*time.Second, cfg.Telegram.PollTimeout)
+	require.Equal(5*time.Second, cfg.Inventory.Timeout)
+	require.Equal([]int64{10, 20}, cfg.Admins)
+	require.Equal("ru", cfg.Locale.Language)
+	require.Equal("Europe/Moscow", cfg.Location().String())
+	require.Equal(config.StorageMemory, cfg.Storage.Driver)
+	require.Equal(15*time.Minute, cfg.Dialogs.Timeout)
+	require.Equal(30*time.Second, cfg.Notifier.Interval)
+	require.Equal(25, cfg.Notifier.MessagesPerSecond)
+	require.Equal(config.PaymentsNone, cfg.Payments.Provider)
+	require.Equal(config.TracingNone, cfg.Tracing.Exporter)
+	require.Equal("tg_bot", cfg.Tracing.ServiceName)
+}
+
+func TestLoad_EnvOnly(t *testing.T) {
+	require := require.New(t)
+	t.Setenv(config.ConfigPathEnv, "")
+	t.Setenv("TG_TOKEN", "1:token")
+	t.Setenv("INVENTORY_ADDR", "localhost:50051")
+	t.Setenv("TG_ADMIN_IDS", "1,2,3")
+	t.Setenv("BOT_LANGUAGE", "en")
+
+	cfg, err := config.Load("")
+
+	require.NoError(err)
+	require.Equal([]int64{1, 2, 3}, cfg.Admins)
+	require.Equal("en", cfg.Locale.Language)
+}
+
+// Без обязательных значений бот не стартует, ошибка называет поле и переменную окружения.
+func TestLoad_MissingRequired(t *testing.T) {
+	require := require.New(t)
+	t.Setenv(config.ConfigPathEnv, "")
+	t.Setenv("TG_TOKEN", "")
+	t.Setenv("INVENTORY_ADDR", "")
+
+	_, err := config.Load("")
+
+	require.Error(err)
+	require.Contains(err.Error(), "telegram.token: is required (set TG_TOKEN)")
+	require.Contains(err.Error(), "inventory.addr: is required (set INVENTORY_ADDR)")
+}
+
+// Все ошибки валидации возвращаются вместе, с именем поля.
+func TestLoad_Validation(t *testing.T) {
+	require := require.New(t)
+	path := writeConfig(t, `
+telegram:
+  token: "1:token"
+  mode: webhook
+  webhook:
+    url: http://bot.example.com/hook
+    secret_token: "bad token!"
+inventory:
+  addr: inventory
+  tls:
+    cert_file: client.pem
+admins: [-5]
+locale:
+  language: de
+  timezone: Mars/Olympus
+storage:
+  driver: postgres
+dialogs:
+  timeout: -1s
+notifier:
+  messages_per_second: 100
+payments:
+  provider: fake
+  fake:
+    outcome: maybe
+tracing:
+  exporter: jaeger
+  sample_ratio: 2
+`)
+
+	_, err := config.Load(path)
+
+	require.Error(err)
+	for _, field := range []string{
+		"telegram.webhook.url",
+		"telegram.webhook.secret_token",
+		"inventory.addr",
+		"inventory.tls.cert_file",
+		"inventory.tls.enabled",
+		"admins[0]",
+		"locale.language",
+		"locale.timezone",
+		"storage.dsn",
+		"dialogs.timeout",
+		"notifier.messages_per_second",
+		"payments.provider",
+		"payments.provider_token",
+		"payments.fake.outcome",
+		"tracing.exporter",
+		"tracing.sample_ratio",
+	} {
+		require.Contains(err.Error(), field+":")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	require := require.New(t)
+	cfg := &config.Config{
+		Telegram:  config.TelegramConfig{Token: "1:secret", Webhook: config.WebhookConfig{SecretToken: "hook"}},
+		Inventory: config.InventoryConfig{Addr: "inventory:50051", APIKey: "key"},
+		Storage:   config.StorageConfig{DSN: "postgres://bot:pass@db:5432/bot?sslmode=disable"},
+		Payments:  config.PaymentsConfig{ProviderToken: "pay"},
+	}
+
+	out := cfg.Redacted()
+
+	require.Equal("[REDACTED]", out.Telegram.Token)
+	require.Equal("[REDACTED]", out.Telegram.Webhook.SecretToken)
+	require.Equal("[REDACTED]", out.Inventory.APIKey)
+	require.Equal("[REDACTED]", out.Payments.ProviderToken)
+	require.Equal("inventory:50051", out.Inventory.Addr)
+	require.Equal("postgres://bot:%5BREDACTED%5D@db:5432/bot?sslmode=disable", out.Storage.DSN)
+	require.Equal("1:secret", cfg.Telegram.Token)
+}
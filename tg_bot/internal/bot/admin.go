@@ -0,0 +1,472 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+// Префиксы callback-данных команд администратора
+const (
+	editPrefix   = "edit"   // edit:<поле>
+	deletePrefix = "delete" // delete:<id> или delete:cancel
+)
+
+// Поля товара, которые заполняются в диалогах
+const (
+	stepArticle     = "article"
+	stepName        = "name"
+	stepBrand       = "brand"
+	stepDescription = "description"
+	stepPrice       = "price"
+	stepSize        = "size"
+	stepPhoto       = "photo"
+)
+
+// editFields - поля, доступные в /edit
+var editFields = []string{stepName, stepBrand, stepDescription, stepPrice, stepSize, stepPhoto}
+
+func (b *Bot) isAdmin(user *tgbotapi.User) bool {
+	return user != nil && slices.Contains(b.settings.Admins, user.ID)
+}
+
+// adminOnly пропускает к команде только пользователей из списка администраторов.
+func (b *Bot) adminOnly(next CommandHandler) CommandHandler {
+	return func(ctx context.Context, msg *tgbotapi.Message) error {
+		if !b.isAdmin(msg.From) {
+			b.reply(msg.Chat.ID, b.texts(msg.From).AdminOnly)
+			return nil
+		}
+		return next(ctx, msg)
+	}
+}
+
+func (b *Bot) adminOnlyCallback(next CallbackHandler) CallbackHandler {
+	return func(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error {
+		if !b.isAdmin(query.From) {
+			b.answer(query.ID, b.texts(query.From).AdminOnly)
+			return nil
+		}
+		return next(ctx, query, args)
+	}
+}
+
+func (b *Bot) handleAdd(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	if _, err := b.catalogDialog.Start(ctx, msg.Chat.ID, stateAddArticle, catalogDraft{Sneaker: &pb.Sneaker{}}); err != nil {
+		return b.dialogFailed(msg.Chat.ID, t, err)
+	}
+	b.reply(msg.Chat.ID, b.prompt(t, stepArticle))
+	return nil
+}
+
+func (b *Bot) handleEdit(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	article := strings.TrimSpace(msg.CommandArguments())
+	if article == "" {
+		b.reply(msg.Chat.ID, t.UsageEdit)
+		return nil
+	}
+
+	sneaker, err := b.findByArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+	if sneaker == nil {
+		b.reply(msg.Chat.ID, fmt.Sprintf(t.NotFound, article))
+		return nil
+	}
+
+	if _, err := b.catalogDialog.Start(ctx, msg.Chat.ID, stateEditChoose, catalogDraft{Sneaker: sneaker}); err != nil {
+		return b.dialogFailed(msg.Chat.ID, t, err)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(editFields); i += 3 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, field := range editFields[i:min(i+3, len(editFields))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fieldName(t, field), editPrefix+":"+field))
+		}
+		rows = append(rows, row)
+	}
+
+	out := tgbotapi.NewMessage(msg.Chat.ID, b.renderCard(t, sneaker)+"\n\n"+t.EditChoose)
+	out.ParseMode = tgbotapi.ModeHTML
+	out.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.send(out)
+	return nil
+}
+
+func (b *Bot) handleEditField(ctx context.Context, query *tgbotapi.CallbackQuery, field string) error {
+	t := b.texts(query.From)
+	if query.Message == nil {
+		b.answer(query.ID, "")
+		return nil
+	}
+	chatID := query.Message.Chat.ID
+
+	s, ok, err := b.loadCatalogDialog(ctx, chatID, query.From)
+	if err != nil {
+		return err
+	}
+	if !ok || !b.catalogDialog.Can(s, eventChoose) || !slices.Contains(editFields, field) {
+		b.answer(query.ID, t.UsageEdit)
+		return nil
+	}
+
+	s.Data.Field = field
+	if err := b.catalogDialog.Fire(ctx, s, eventChoose); err != nil {
+		b.answer(query.ID, "")
+		return b.dialogFailed(chatID, t, err)
+	}
+	b.answer(query.ID, "")
+	b.reply(chatID, b.prompt(t, field))
+	return nil
+}
+
+func (b *Bot) handlePrice(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	article, value, _ := strings.Cut(strings.TrimSpace(msg.CommandArguments()), " ")
+	if article == "" || strings.TrimSpace(value) == "" {
+		b.reply(msg.Chat.ID, t.UsagePrice)
+		return nil
+	}
+
+	sneaker, err := b.findByArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+	if sneaker == nil {
+		b.reply(msg.Chat.ID, fmt.Sprintf(t.NotFound, article))
+		return nil
+	}
+
+	price, err := parsePrice(value, sneaker.GetPrice().GetCurrencyCode())
+	if err != nil {
+		b.reply(msg.Chat.ID, t.BadPrice)
+		return nil
+	}
+	sneaker.Price = price
+
+	return b.updateSneaker(ctx, msg.Chat.ID, t, msg.MessageID, sneaker)
+}
+
+func (b *Bot) handleDelete(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	article := strings.TrimSpace(msg.CommandArguments())
+	if article == "" {
+		b.reply(msg.Chat.ID, t.UsageDelete)
+		return nil
+	}
+
+	sneaker, err := b.findByArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+	if sneaker == nil {
+		b.reply(msg.Chat.ID, fmt.Sprintf(t.NotFound, article))
+		return nil
+	}
+
+	out := tgbotapi.NewMessage(msg.Chat.ID, b.renderCard(t, sneaker)+"\n\n"+t.ConfirmDelete)
+	out.ParseMode = tgbotapi.ModeHTML
+	out.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnDelete, deletePrefix+":"+strconv.Itoa(int(sneaker.GetSneakerId()))),
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnCancel, deletePrefix+":cancel"),
+	))
+	b.send(out)
+	return nil
+}
+
+// handleDeleteConfirm удаляет товар после подтверждения кнопкой.
+func (b *Bot) handleDeleteConfirm(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error {
+	t := b.texts(query.From)
+	if query.Message == nil {
+		b.answer(query.ID, "")
+		return nil
+	}
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	id, err := strconv.Atoi(args)
+	if err != nil {
+		b.answer(query.ID, "")
+		b.send(tgbotapi.NewEditMessageText(chatID, messageID, t.Cancelled))
+		return nil
+	}
+
+	// Артикул нужен для подсказки /restore, заодно проверяем, что товар ещё не удалён
+	resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{SneakerId: []int32{int32(id)}, Partition: 1})
+	if err != nil {
+		return fmt.Errorf("get sneaker %d: %w", id, err)
+	}
+	if len(resp.GetSneakers()) == 0 {
+		b.answer(query.ID, fmt.Sprintf(t.NotFound, strconv.Itoa(id)))
+		return nil
+	}
+	article := resp.GetSneakers()[0].GetArticle()
+
+	deleted, err := b.inventory.DeleteSneakers(ctx, &pb.DeleteSneakersRequest{
+		RequestId:  int32(messageID),
+		SneakerIds: []int32{int32(id)},
+	})
+	b.answer(query.ID, "")
+	if b.rejected(chatID, t, deleted, err) {
+		return nil
+	}
+
+	b.send(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf(t.Deleted, article, article)))
+	return nil
+}
+
+func (b *Bot) handleRestore(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	article := strings.TrimSpace(msg.CommandArguments())
+	if article == "" {
+		b.reply(msg.Chat.ID, t.UsageRestore)
+		return nil
+	}
+
+	resp, err := b.inventory.RestoreSneakers(ctx, &pb.RestoreSneakersRequest{
+		RequestId: int32(msg.MessageID),
+		Articles:  []string{article},
+	})
+	if b.rejected(msg.Chat.ID, t, resp, err) {
+		return nil
+	}
+
+	b.reply(msg.Chat.ID, fmt.Sprintf(t.Restored, article))
+	return nil
+}
+
+func (b *Bot) handleCancel(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	// Отменяется любой диалог чата, не только диалог каталога
+	_, err := b.dialogs.Load(ctx, msg.Chat.ID)
+	if errors.Is(err, fsm.ErrNotFound) {
+		b.reply(msg.Chat.ID, t.NothingToCancel)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load dialog: %w", err)
+	}
+	if err := b.dialogs.Delete(ctx, msg.Chat.ID); err != nil {
+		return fmt.Errorf("delete dialog: %w", err)
+	}
+	b.reply(msg.Chat.ID, t.Cancelled)
+	return nil
+}
+
+// handleSkip пропускает необязательный шаг диалога (фото).
+func (b *Bot) handleSkip(ctx context.Context, msg *tgbotapi.Message) error {
+	s, ok, err := b.loadCatalogDialog(ctx, msg.Chat.ID, msg.From)
+	if err != nil || !ok {
+		return err
+	}
+	if !b.catalogDialog.Can(s, eventSkip) {
+		b.reply(msg.Chat.ID, b.texts(msg.From).UnknownCommand)
+		return nil
+	}
+	return b.finishDialog(ctx, msg, s, eventSkip)
+}
+
+// handleDialogInput принимает ответ на текущий шаг диалога.
+func (b *Bot) handleDialogInput(ctx context.Context, msg *tgbotapi.Message, s *fsm.Session[catalogDraft]) error {
+	t := b.texts(msg.From)
+	// Права могли отозвать, пока диалог был открыт
+	if !b.isAdmin(msg.From) {
+		b.reply(msg.Chat.ID, t.AdminOnly)
+		return b.catalogDialog.Finish(ctx, msg.Chat.ID)
+	}
+	if !b.catalogDialog.Can(s, eventAnswer) {
+		b.reply(msg.Chat.ID, t.EditChoose)
+		return nil
+	}
+
+	field := s.Data.field(s.State)
+	if problem := applyStep(t, s.Data.Sneaker, field, msg); problem != "" {
+		b.reply(msg.Chat.ID, problem+"\n"+b.prompt(t, field))
+		return nil
+	}
+
+	if err := b.catalogDialog.Fire(ctx, s, eventAnswer); err != nil {
+		return b.dialogFailed(msg.Chat.ID, t, err)
+	}
+	if s.State != fsm.None {
+		b.reply(msg.Chat.ID, b.prompt(t, s.Data.field(s.State)))
+		return nil
+	}
+	return b.submitDraft(ctx, msg, t, s)
+}
+
+// applyStep записывает ответ в черновик; непустая строка - что не так с ответом.
+func applyStep(t *messages, sneaker *pb.Sneaker, step string, msg *tgbotapi.Message) string {
+	if step == stepPhoto {
+		if len(msg.Photo) == 0 {
+			return ""
+		}
+		// Telegram присылает несколько размеров, последний - самый большой
+		sneaker.Picture = msg.Photo[len(msg.Photo)-1].FileID
+		return ""
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		return t.EmptyValue
+	}
+
+	switch step {
+	case stepArticle:
+		sneaker.Article = text
+	case stepName:
+		sneaker.SneakerName = text
+	case stepBrand:
+		sneaker.Brand = text
+	case stepDescription:
+		sneaker.SneakerDescription = text
+	case stepPrice:
+		currency := sneaker.GetPrice().GetCurrencyCode()
+		if currency == "" {
+			currency = defaultCurrency
+		}
+		price, err := parsePrice(text, currency)
+		if err != nil {
+			return t.BadPrice
+		}
+		sneaker.Price = price
+	case stepSize:
+		value, system, err := parseSize(text)
+		if err != nil {
+			return t.BadSize
+		}
+		sneaker.Size = value
+		sneaker.SizeSystem = system
+	}
+	return ""
+}
+
+// finishDialog закрывает диалог событием event и отправляет черновик в inventory.
+func (b *Bot) finishDialog(ctx context.Context, msg *tgbotapi.Message, s *fsm.Session[catalogDraft], event fsm.Event) error {
+	t := b.texts(msg.From)
+	if err := b.catalogDialog.Fire(ctx, s, event); err != nil {
+		return b.dialogFailed(msg.Chat.ID, t, err)
+	}
+	return b.submitDraft(ctx, msg, t, s)
+}
+
+// submitDraft отправляет черновик закрытого диалога в inventory. Диалог закрывается
+// до запроса, чтобы повтор того же ответа не создал товар дважды; при отказе
+// inventory причина показывается, начать можно заново.
+func (b *Bot) submitDraft(ctx context.Context, msg *tgbotapi.Message, t *messages, s *fsm.Session[catalogDraft]) error {
+	if s.Data.Field != "" {
+		return b.updateSneaker(ctx, msg.Chat.ID, t, msg.MessageID, s.Data.Sneaker)
+	}
+
+	resp, err := b.inventory.CreateSneakers(ctx, &pb.CreateSneakersRequest{
+		RequestId: int32(msg.MessageID),
+		Sneakers:  []*pb.Sneaker{s.Data.Sneaker},
+	})
+	if b.rejected(msg.Chat.ID, t, resp, err) {
+		return nil
+	}
+
+	var id int32
+	if ids := resp.GetSneakerIds(); len(ids) > 0 {
+		id = ids[0]
+	}
+	b.reply(msg.Chat.ID, fmt.Sprintf(t.Created, id))
+	return nil
+}
+
+func (b *Bot) updateSneaker(ctx context.Context, chatID int64, t *messages, requestID int, sneaker *pb.Sneaker) error {
+	resp, err := b.inventory.UpdateSneakers(ctx, &pb.UpdateSneakersRequest{
+		RequestId: int32(requestID),
+		Sneakers:  []*pb.Sneaker{sneaker},
+	})
+	if b.rejected(chatID, t, resp, err) {
+		return nil
+	}
+
+	updated, err := b.findByArticle(ctx, sneaker.GetArticle())
+	if err != nil || updated == nil {
+		b.reply(chatID, t.Updated)
+		return err
+	}
+	out := tgbotapi.NewMessage(chatID, t.Updated+"\n\n"+b.renderCard(t, updated))
+	out.ParseMode = tgbotapi.ModeHTML
+	b.send(out)
+	return nil
+}
+
+// rejected показывает администратору, почему inventory не выполнил запрос.
+// Ошибки валидации приходят в Response, остальные - статусом gRPC.
+func (b *Bot) rejected(chatID int64, t *messages, resp *pb.Response, err error) bool {
+	if err != nil {
+		b.log.Error("ERROR: inventory request", zap.Int64("chat_id", chatID), zap.Error(err))
+		b.reply(chatID, fmt.Sprintf(t.Rejected, status.Convert(err).Message()))
+		return true
+	}
+	if resp.GetStatus() != pb.Response_SUCCESS {
+		b.reply(chatID, fmt.Sprintf(t.Rejected, resp.GetErrorMessage()))
+		return true
+	}
+	return false
+}
+
+// findByArticle - товар по артикулу или nil, если его нет.
+func (b *Bot) findByArticle(ctx context.Context, article string) (*pb.Sneaker, error) {
+	resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{Articles: []string{article}, Partition: 1})
+	if err != nil {
+		return nil, fmt.Errorf("find sneaker %s: %w", article, err)
+	}
+	if len(resp.GetSneakers()) == 0 {
+		return nil, nil
+	}
+	return resp.GetSneakers()[0], nil
+}
+
+func (b *Bot) prompt(t *messages, step string) string {
+	switch step {
+	case stepArticle:
+		return t.AskArticle
+	case stepName:
+		return t.AskName
+	case stepBrand:
+		return t.AskBrand
+	case stepDescription:
+		return t.AskDescription
+	case stepPrice:
+		return t.AskPrice
+	case stepSize:
+		return t.AskSize
+	case stepPhoto:
+		return t.AskPhoto
+	}
+	return ""
+}
+
+func fieldName(t *messages, field string) string {
+	switch field {
+	case stepName:
+		return t.FieldName
+	case stepBrand:
+		return t.FieldBrand
+	case stepDescription:
+		return t.FieldDescription
+	case stepPrice:
+		return t.FieldPrice
+	case stepSize:
+		return t.FieldSize
+	case stepPhoto:
+		return t.FieldPhoto
+	}
+	return field
+}
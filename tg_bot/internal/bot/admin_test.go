@@ -0,0 +1,246 @@
+package bot_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const adminID = 100
+
+func newAdminBot(t *testing.T, inventory *fakeInventory) (*bot.Bot, *telegramtest.Server) {
+	t.Helper()
+	server := telegramtest.NewServer(t)
+	return bot.New(server.BotAPI(t), inventory, zap.NewNop(), bot.Settings{Admins: []int64{adminID}}), server
+}
+
+func lastText(t *testing.T, server *telegramtest.Server, method string) string {
+	t.Helper()
+	calls := server.Calls(method)
+	require.NotEmpty(t, calls)
+	return calls[len(calls)-1].Params.Get("text")
+}
+
+// Команды управления каталогом недоступны обычным пользователям.
+func TestAdmin_NotAdmin(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/add"))
+	b.HandleUpdate(ctx, telegramtest.Message(7, "ART-009"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "delete:1"))
+
+	// --- Assert ---
+	sent := server.Calls("sendMessage")
+	require.Len(sent, 1)
+	require.Equal("Команда доступна только администраторам", sent[0].Params.Get("text"))
+	require.Empty(inventory.created)
+	require.Empty(inventory.removed)
+	require.Len(inventory.sneakers, 2)
+}
+
+// /add проводит по шагам, повторяет вопрос на неверный ответ и создаёт товар.
+func TestAdmin_AddDialog(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+	photo := telegramtest.Message(adminID, "")
+	photo.Message.Photo = []tgbotapi.PhotoSize{{FileID: "small"}, {FileID: "large"}}
+
+	// --- Act ---
+	for _, text := range []string{"/add", "ART-100", "Gel-Kayano 14", "Asics", "дорого"} {
+		b.HandleUpdate(ctx, telegramtest.Message(adminID, text))
+	}
+
+	// --- Assert ---
+	require.Contains(lastText(t, server, "sendMessage"), "Не понял цену")
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "14 990,50"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "9.5 US M"))
+	b.HandleUpdate(ctx, photo)
+
+	// --- Assert ---
+	require.Len(inventory.created, 1)
+	created := inventory.created[0].GetSneakers()[0]
+	require.Equal("ART-100", created.GetArticle())
+	require.Equal("Gel-Kayano 14", created.GetSneakerName())
+	require.Equal("Asics", created.GetBrand())
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 14990, Nanos: 500_000_000}, created.GetPrice())
+	require.Equal(float32(9.5), created.GetSize())
+	require.Equal("US_M", created.GetSizeSystem())
+	require.Equal("large", created.GetPicture())
+	require.Equal("Товар добавлен, ID 1", lastText(t, server, "sendMessage"))
+
+	// Диалог закрыт, обычные сообщения больше не обрабатываются
+	calls := len(server.Calls("sendMessage"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "ещё"))
+	require.Len(server.Calls("sendMessage"), calls)
+}
+
+// Отказ inventory показывается администратору с причиной.
+func TestAdmin_AddRejected(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{reject: &pb.Response{
+		Status:       pb.Response_VALIDATION_ERROR,
+		StatusCode:   http.StatusBadRequest,
+		ErrorMessage: "unknown size 60 EU for brand asics",
+	}}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	for _, text := range []string{"/add", "ART-100", "Gel", "Asics", "100", "60 EU", "/skip"} {
+		b.HandleUpdate(ctx, telegramtest.Message(adminID, text))
+	}
+
+	// --- Assert ---
+	require.Len(inventory.created, 1)
+	require.Empty(inventory.created[0].GetSneakers()[0].GetPicture())
+	require.Equal("Inventory отклонил запрос: unknown size 60 EU for brand asics", lastText(t, server, "sendMessage"))
+}
+
+// /edit меняет выбранное поле, остальные поля товара сохраняются.
+func TestAdmin_Edit(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/edit ART-002"))
+	b.HandleUpdate(ctx, telegramtest.Callback(adminID, 5, "edit:name"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "Samba OG"))
+
+	// --- Assert ---
+	require.Len(inventory.updated, 1)
+	updated := inventory.updated[0].GetSneakers()[0]
+	require.Equal("Samba OG", updated.GetSneakerName())
+	require.Equal("Adidas", updated.GetBrand())
+	require.Equal(int64(10990), updated.GetPrice().GetUnits())
+	require.Contains(lastText(t, server, "sendMessage"), "Изменения сохранены")
+}
+
+// /price меняет цену в валюте товара, изменение уходит в inventory от имени администратора.
+func TestAdmin_Price(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/price ART-001 11990"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/price ART-404 11990"))
+
+	// --- Assert ---
+	require.Len(inventory.updated, 1)
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 11990}, inventory.updated[0].GetSneakers()[0].GetPrice())
+	require.Equal([]string{"tg:100"}, inventory.actors)
+	require.Equal("Товар ART-404 не найден", lastText(t, server, "sendMessage"))
+}
+
+// Запятая между разрядами не превращает 12,990 в 12.99, неоднозначная запись отклоняется.
+func TestAdmin_PriceCommas(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/price ART-001 12,990"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/price ART-001 12 990,5"))
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/price ART-001 1,2990"))
+
+	// --- Assert ---
+	require.Len(inventory.updated, 2)
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 12990}, inventory.updated[0].GetSneakers()[0].GetPrice())
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 12990, Nanos: 500_000_000}, inventory.updated[1].GetSneakers()[0].GetPrice())
+	require.Contains(lastText(t, server, "sendMessage"), "Не понял цену")
+}
+
+// /delete удаляет только после подтверждения, /restore возвращает товар.
+func TestAdmin_DeleteRestore(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server := newAdminBot(t, inventory)
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/delete ART-001"))
+
+	// --- Assert ---
+	buttons := keyboard(t, server.Calls("sendMessage")[0])
+	require.Equal("delete:1", *buttons[0][0].CallbackData)
+	require.Equal("delete:cancel", *buttons[0][1].CallbackData)
+	require.Empty(inventory.removed)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(adminID, 5, "delete:1"))
+
+	// --- Assert ---
+	require.Len(inventory.removed, 1)
+	require.Equal([]int32{1}, inventory.removed[0].GetSneakerIds())
+	require.Equal("Товар ART-001 удалён. Вернуть: /restore ART-001", lastText(t, server, "editMessageText"))
+	require.Len(inventory.sneakers, 1)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(adminID, "/restore ART-001"))
+
+	// --- Assert ---
+	require.Equal([]string{"ART-001"}, inventory.restored[0].GetArticles())
+	require.Equal("Товар ART-001 восстановлен", lastText(t, server, "sendMessage"))
+	require.Len(inventory.sneakers, 2)
+}
+
+// Диалог хранится в общем хранилище и продолжается на другой реплике бота.
+func TestAdmin_DialogAcrossReplicas(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{}
+	server := telegramtest.NewServer(t)
+	store := fsm.NewMemoryStore()
+	settings := bot.Settings{Admins: []int64{adminID}, Dialogs: store}
+	first := bot.New(server.BotAPI(t), inventory, zap.NewNop(), settings)
+	second := bot.New(server.BotAPI(t), inventory, zap.NewNop(), settings)
+	ctx := context.Background()
+
+	// --- Act ---
+	for i, text := range []string{"/add", "ART-100", "Gel", "Asics", "100", "42 EU", "/skip"} {
+		replica := first
+		if i%2 == 1 {
+			replica = second
+		}
+		replica.HandleUpdate(ctx, telegramtest.Message(adminID, text))
+	}
+
+	// --- Assert ---
+	require.Len(inventory.created, 1)
+	require.Equal("ART-100", inventory.created[0].GetSneakers()[0].GetArticle())
+	_, err := store.Load(ctx, adminID)
+	require.ErrorIs(err, fsm.ErrNotFound)
+
+	// --- Act ---
+	first.HandleUpdate(ctx, telegramtest.Message(adminID, "/cancel"))
+
+	// --- Assert ---
+	require.Equal("Нечего отменять", lastText(t, server, "sendMessage"))
+}
@@ -0,0 +1,328 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/cache"
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/kripst/krosovka/tg_bot/internal/dispatch"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"github.com/kripst/krosovka/tg_bot/internal/inventory"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/kripst/krosovka/tg_bot/internal/payments"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+	"github.com/kripst/krosovka/tg_bot/internal/throttle"
+	"github.com/kripst/krosovka/tg_bot/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// Inventory - методы inventory_service, которые нужны боту.
+type Inventory interface {
+	GetSneakers(ctx context.Context, in *pb.GetSneakersRequest, opts ...grpc.CallOption) (*pb.GetSneakersResponse, error)
+	CreateSneakers(ctx context.Context, in *pb.CreateSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error)
+	UpdateSneakers(ctx context.Context, in *pb.UpdateSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error)
+	DeleteSneakers(ctx context.Context, in *pb.DeleteSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error)
+	RestoreSneakers(ctx context.Context, in *pb.RestoreSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error)
+}
+
+// CommandHandler обрабатывает команду вида /catalog.
+type CommandHandler func(ctx context.Context, msg *tgbotapi.Message) error
+
+// CallbackHandler обрабатывает нажатие inline-кнопки, args - данные после "<prefix>:".
+type CallbackHandler func(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error
+
+// defaultLanguage - язык, если в Settings он не задан
+const defaultLanguage = "ru"
+
+// defaultDialogTimeout - время на ответ в диалоге, если в Settings оно не задано
+const defaultDialogTimeout = 15 * time.Minute
+
+// defaultInlineCacheTime - время жизни результатов inline-поиска, если в Settings оно не задано
+const defaultInlineCacheTime = time.Minute
+
+// Лимиты уведомлений, если в Settings они не заданы. Темп общий для всех реплик:
+// рассылку ведёт одна из них, см. Store.LockNotifier
+const (
+	defaultMessagesPerSecond = 25
+	defaultPerChatInterval   = time.Second
+	defaultNotifyPerHour     = 20
+)
+
+// Settings - настройки бота из конфига.
+type Settings struct {
+	// Admins - Telegram ID администраторов каталога
+	Admins []int64
+	// Language - язык сообщений, если язык пользователя не поддерживается
+	Language string
+	// Location - часовой пояс для дат в сообщениях, nil - UTC
+	Location *time.Location
+	// Dialogs - где хранятся диалоги, nil - в памяти процесса
+	Dialogs fsm.Store
+	// DialogTimeout - время на ответ в шаге диалога
+	DialogTimeout time.Duration
+	// InlineCacheTime - сколько Telegram и бот кэшируют результаты inline-поиска
+	InlineCacheTime time.Duration
+	// Subscriptions - где хранятся подписки, nil - в памяти процесса
+	Subscriptions subscriptions.Store
+	// Throttle - темп отправки уведомлений, nil - значения по умолчанию
+	Throttle *throttle.Throttle
+	// NotifyQuota - сколько уведомлений получает один чат, nil - значение по умолчанию
+	NotifyQuota *throttle.Quota
+	// Cart - где хранятся корзины, nil - в памяти процесса
+	Cart cart.Store
+	// Orders - куда передаются оформленные заказы, nil - в память процесса
+	Orders orders.Service
+	// Payments - провайдер счетов для Telegram Payments, nil - заказ оплачивается
+	// вне бота, после звонка менеджера
+	Payments payments.Provider
+}
+
+type Bot struct {
+	api       *tgbotapi.BotAPI
+	inventory Inventory
+	log       *zap.Logger
+	settings  Settings
+
+	commands  map[string]CommandHandler
+	callbacks map[string]CallbackHandler
+
+	dialogs        fsm.Store
+	catalogDialog  *fsm.Machine[catalogDraft]
+	checkoutDialog *fsm.Machine[checkoutDraft]
+
+	searchCache *cache.TTL[string, searchPage]
+
+	subscriptions subscriptions.Store
+	throttle      *throttle.Throttle
+	notifyQuota   *throttle.Quota
+	// deferred - занятые уведомления, которым не хватило квоты чата, по ID подписки
+	deferredMu sync.Mutex
+	deferred   map[int64]notice
+
+	cart     cart.Store
+	orders   orders.Service
+	payments payments.Provider
+}
+
+func New(api *tgbotapi.BotAPI, inventory Inventory, log *zap.Logger, settings Settings) *Bot {
+	if settings.Location == nil {
+		settings.Location = time.UTC
+	}
+	if settings.Dialogs == nil {
+		settings.Dialogs = fsm.NewMemoryStore()
+	}
+	if settings.DialogTimeout <= 0 {
+		settings.DialogTimeout = defaultDialogTimeout
+	}
+	if settings.InlineCacheTime <= 0 {
+		settings.InlineCacheTime = defaultInlineCacheTime
+	}
+	if settings.Subscriptions == nil {
+		settings.Subscriptions = subscriptions.NewMemoryStore()
+	}
+	if settings.Throttle == nil {
+		settings.Throttle = throttle.New(defaultMessagesPerSecond, defaultPerChatInterval)
+	}
+	if settings.NotifyQuota == nil {
+		settings.NotifyQuota = throttle.NewQuota(defaultNotifyPerHour, time.Hour)
+	}
+	if settings.Cart == nil {
+		settings.Cart = cart.NewMemoryStore()
+	}
+	if settings.Orders == nil {
+		settings.Orders = orders.NewMemoryService()
+	}
+	b := &Bot{
+		api:       api,
+		inventory: inventory,
+		log:       log,
+		settings:  settings,
+		commands:  make(map[string]CommandHandler),
+		callbacks: make(map[string]CallbackHandler),
+		dialogs:   settings.Dialogs,
+
+		searchCache: cache.New[string, searchPage](settings.InlineCacheTime, inlineCacheSize),
+
+		subscriptions: settings.Subscriptions,
+		throttle:      settings.Throttle,
+		notifyQuota:   settings.NotifyQuota,
+		deferred:      make(map[int64]notice),
+
+		cart:     settings.Cart,
+		orders:   settings.Orders,
+		payments: settings.Payments,
+	}
+	b.catalogDialog = fsm.New[catalogDraft](settings.Dialogs, catalogDefinition(settings))
+	b.checkoutDialog = fsm.New[checkoutDraft](settings.Dialogs, checkoutDefinition(settings))
+
+	b.commands["start"] = b.handleStart
+	b.commands["catalog"] = b.handleCatalog
+	b.commands["cancel"] = b.handleCancel
+	b.commands["skip"] = b.handleSkip
+	b.callbacks[catalogPrefix] = b.handleCatalogPage
+
+	// Подписки на появление товара и снижение цены
+	b.commands["subscribe"] = b.handleSubscribe
+	b.commands["subscriptions"] = b.handleSubscriptions
+	b.commands["unsubscribe"] = b.handleUnsubscribe
+
+	// Корзина и оформление заказа
+	b.commands["cart"] = b.handleCart
+	b.commands["checkout"] = b.handleCheckout
+	b.callbacks[cartPrefix] = b.handleCartButton
+	b.callbacks[checkoutPrefix] = b.handleCheckoutButton
+
+	// Управление каталогом
+	b.commands["add"] = b.adminOnly(b.handleAdd)
+	b.commands["edit"] = b.adminOnly(b.handleEdit)
+	b.commands["price"] = b.adminOnly(b.handlePrice)
+	b.commands["delete"] = b.adminOnly(b.handleDelete)
+	b.commands["restore"] = b.adminOnly(b.handleRestore)
+	b.callbacks[editPrefix] = b.adminOnlyCallback(b.handleEditField)
+	b.callbacks[deletePrefix] = b.adminOnlyCallback(b.handleDeleteConfirm)
+
+	return b
+}
+
+// HandleUpdate разбирает одно обновление, общий обработчик для polling и webhook.
+// Ошибки обработчиков логируются, пользователь получает общее сообщение об ошибке.
+func (b *Bot) HandleUpdate(ctx context.Context, update tgbotapi.Update) {
+	// Корневой спан обновления: RPC к inventory_service становятся его потомками
+	ctx, span := tracing.Tracer().Start(ctx, updateSpanName(update), trace.WithAttributes(
+		attribute.Int("telegram.update_id", update.UpdateID),
+		attribute.Int64("telegram.chat_id", dispatch.ChatKey(update)),
+	))
+	defer span.End()
+
+	// Изменения каталога в аудите inventory_service записываются на администратора, а не на ключ бота
+	if user := update.SentFrom(); b.isAdmin(user) {
+		ctx = inventory.WithActor(ctx, "tg:"+strconv.FormatInt(user.ID, 10))
+	}
+
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		msg := update.Message
+		handler, ok := b.commands[msg.Command()]
+		if !ok {
+			b.reply(msg.Chat.ID, b.texts(msg.From).UnknownCommand)
+			return
+		}
+		if err := handler(ctx, msg); err != nil {
+			b.log.Error("ERROR: handle command", zap.String("command", msg.Command()), zap.Int64("chat_id", msg.Chat.ID), zap.Error(err))
+			b.reply(msg.Chat.ID, b.texts(msg.From).InternalError)
+		}
+
+	case update.Message != nil && update.Message.SuccessfulPayment != nil:
+		msg := update.Message
+		if err := b.handleSuccessfulPayment(ctx, msg); err != nil {
+			// Деньги уже списаны: ошибку разбирает администратор по логу
+			b.log.Error("ERROR: handle successful payment", zap.Int64("chat_id", msg.Chat.ID),
+				zap.String("payload", msg.SuccessfulPayment.InvoicePayload), zap.Error(err))
+			b.reply(msg.Chat.ID, b.texts(msg.From).InternalError)
+		}
+
+	case update.Message != nil:
+		// Обычные сообщения - ответы на шаги открытого диалога
+		msg := update.Message
+		if err := b.handleDialogMessage(ctx, msg); err != nil {
+			b.log.Error("ERROR: handle dialog", zap.Int64("chat_id", msg.Chat.ID), zap.Error(err))
+			b.reply(msg.Chat.ID, b.texts(msg.From).InternalError)
+		}
+
+	case update.InlineQuery != nil:
+		query := update.InlineQuery
+		if err := b.handleInlineQuery(ctx, query); err != nil {
+			// Ответить на inline-запрос ошибкой нельзя, Telegram просто не покажет результатов
+			b.log.Error("ERROR: handle inline query", zap.String("query", query.Query), zap.Error(err))
+		}
+
+	case update.PreCheckoutQuery != nil:
+		query := update.PreCheckoutQuery
+		if err := b.handlePreCheckout(ctx, query); err != nil {
+			b.log.Error("ERROR: handle pre-checkout query", zap.String("payload", query.InvoicePayload), zap.Error(err))
+		}
+
+	case update.CallbackQuery != nil:
+		query := update.CallbackQuery
+		prefix, args, _ := strings.Cut(query.Data, ":")
+		handler, ok := b.callbacks[prefix]
+		if !ok {
+			b.answer(query.ID, "")
+			return
+		}
+		if err := handler(ctx, query, args); err != nil {
+			b.log.Error("ERROR: handle callback", zap.String("data", query.Data), zap.Error(err))
+			b.answer(query.ID, b.texts(query.From).InternalError)
+		}
+	}
+}
+
+// updateSpanName - имя спана по виду обновления, у команд - с именем команды.
+func updateSpanName(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return "telegram command /" + update.Message.Command()
+	case update.Message != nil && update.Message.SuccessfulPayment != nil:
+		return "telegram successful_payment"
+	case update.Message != nil:
+		return "telegram message"
+	case update.InlineQuery != nil:
+		return "telegram inline_query"
+	case update.PreCheckoutQuery != nil:
+		return "telegram pre_checkout_query"
+	case update.CallbackQuery != nil:
+		return "telegram callback_query"
+	default:
+		return "telegram update"
+	}
+}
+
+// RunPolling получает обновления через getUpdates до отмены ctx и передаёт их в d.
+func (b *Bot) RunPolling(ctx context.Context, d *dispatch.Dispatcher, timeout time.Duration) {
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = int(timeout.Seconds())
+	updates := b.api.GetUpdatesChan(u)
+
+	b.log.Info("polling started", zap.String("bot", b.api.Self.UserName))
+	for {
+		select {
+		case <-ctx.Done():
+			b.api.StopReceivingUpdates()
+			return
+		case update := <-updates:
+			d.Dispatch(update)
+		}
+	}
+}
+
+func (b *Bot) handleStart(ctx context.Context, msg *tgbotapi.Message) error {
+	// "/start catalog" - переход из inline-поиска, где ничего не нашлось
+	if msg.CommandArguments() == catalogPrefix {
+		return b.handleCatalog(ctx, msg)
+	}
+	b.reply(msg.Chat.ID, b.texts(msg.From).Start)
+	return nil
+}
+
+func (b *Bot) send(c tgbotapi.Chattable) {
+	if _, err := b.api.Request(c); err != nil {
+		b.log.Error("ERROR: telegram request", zap.Error(err))
+	}
+}
+
+func (b *Bot) reply(chatID int64, text string) {
+	b.send(tgbotapi.NewMessage(chatID, text))
+}
+
+func (b *Bot) answer(callbackID, text string) {
+	b.send(tgbotapi.NewCallback(callbackID, text))
+}
@@ -0,0 +1,264 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/shopspring/decimal"
+)
+
+// cartPrefix - префикс callback-данных корзины, "cart:<действие>[:<id товара>]"
+const cartPrefix = "cart"
+
+// Действия кнопок корзины
+const (
+	cartAdd      = "add"
+	cartInc      = "inc"
+	cartDec      = "dec"
+	cartDel      = "del"
+	cartClear    = "clear"
+	cartCheckout = "checkout"
+)
+
+// handleCart показывает корзину с кнопками количества и оформления.
+func (b *Bot) handleCart(ctx context.Context, msg *tgbotapi.Message) error {
+	items, err := b.cart.Items(ctx, msg.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("load cart: %w", err)
+	}
+	b.send(cartMessage(b.texts(msg.From), msg.Chat.ID, items, ""))
+	return nil
+}
+
+func (b *Bot) handleCartButton(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error {
+	t := b.texts(query.From)
+	if query.Message == nil {
+		b.answer(query.ID, "")
+		return nil
+	}
+	chatID := query.Message.Chat.ID
+
+	action, arg, _ := strings.Cut(args, ":")
+	switch action {
+	case cartAdd:
+		id, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			break
+		}
+		return b.addToCart(ctx, query, chatID, int32(id))
+
+	case cartInc, cartDec, cartDel:
+		id, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			break
+		}
+		items, err := b.cart.Items(ctx, chatID)
+		if err != nil {
+			return fmt.Errorf("load cart: %w", err)
+		}
+		for _, item := range items {
+			if item.SneakerID != int32(id) {
+				continue
+			}
+			quantity := 0
+			switch action {
+			case cartInc:
+				quantity = item.Quantity + 1
+			case cartDec:
+				quantity = item.Quantity - 1
+			}
+			if quantity > cart.MaxQuantity {
+				b.answer(query.ID, fmt.Sprintf(t.CartLimit, cart.MaxQuantity))
+				return nil
+			}
+			if _, err := b.cart.SetQuantity(ctx, chatID, item.SneakerID, quantity); err != nil {
+				return fmt.Errorf("set cart quantity: %w", err)
+			}
+		}
+		b.answer(query.ID, "")
+		return b.refreshCart(ctx, chatID, query.Message.MessageID, t)
+
+	case cartClear:
+		if err := b.cart.Clear(ctx, chatID); err != nil {
+			return fmt.Errorf("clear cart: %w", err)
+		}
+		b.answer(query.ID, "")
+		return b.refreshCart(ctx, chatID, query.Message.MessageID, t)
+
+	case cartCheckout:
+		b.answer(query.ID, "")
+		return b.startCheckout(ctx, chatID, query.From)
+	}
+
+	b.answer(query.ID, "")
+	return nil
+}
+
+// addToCart кладёт в корзину товар с карточки по текущей цене.
+func (b *Bot) addToCart(ctx context.Context, query *tgbotapi.CallbackQuery, chatID int64, id int32) error {
+	t := b.texts(query.From)
+	resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{SneakerId: []int32{id}, Partition: 1})
+	if err != nil {
+		return fmt.Errorf("get sneaker %d: %w", id, err)
+	}
+	if len(resp.GetSneakers()) == 0 {
+		b.answer(query.ID, t.CartUnavailableNow)
+		return nil
+	}
+
+	s := resp.GetSneakers()[0]
+	price := currentPrice(s)
+	quantity, err := b.cart.Add(ctx, chatID, cart.Item{
+		SneakerID:  s.GetSneakerId(),
+		Article:    s.GetArticle(),
+		Name:       s.GetSneakerName(),
+		Size:       s.GetSize(),
+		SizeSystem: s.GetSizeSystem(),
+		Quantity:   1,
+		Price:      moneyAmount(price),
+		Currency:   price.GetCurrencyCode(),
+	})
+	if err != nil {
+		return fmt.Errorf("add to cart: %w", err)
+	}
+	b.answer(query.ID, fmt.Sprintf(t.CartAdded, quantity))
+	return nil
+}
+
+// refreshCart перерисовывает сообщение с корзиной после нажатия кнопки.
+func (b *Bot) refreshCart(ctx context.Context, chatID int64, messageID int, t *messages) error {
+	items, err := b.cart.Items(ctx, chatID)
+	if err != nil {
+		return fmt.Errorf("load cart: %w", err)
+	}
+	text, markup := renderCart(t, items)
+	b.send(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, markup))
+	return nil
+}
+
+// revalidateCart сверяет корзину с inventory: пропавшие товары и товары другого
+// размера убираются, цены обновляются. Возвращает актуальную корзину и
+// предупреждения о том, что изменилось не в пользу покупателя.
+func (b *Bot) revalidateCart(ctx context.Context, chatID int64, t *messages) ([]cart.Item, []string, error) {
+	items, err := b.cart.Items(ctx, chatID)
+	if err != nil || len(items) == 0 {
+		return nil, nil, err
+	}
+
+	ids := make([]int32, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.SneakerID)
+	}
+	resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{SneakerId: ids, Partition: int32(len(ids))})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get cart sneakers: %w", err)
+	}
+	if resp.GetStatusCode() != http.StatusOK {
+		return nil, nil, fmt.Errorf("get cart sneakers: status %d: %s", resp.GetStatusCode(), resp.GetErrorMessage())
+	}
+	current := make(map[int32]*pb.Sneaker, len(resp.GetSneakers()))
+	for _, s := range resp.GetSneakers() {
+		current[s.GetSneakerId()] = s
+	}
+
+	var (
+		valid    []cart.Item
+		warnings []string
+	)
+	for _, item := range items {
+		s, ok := current[item.SneakerID]
+		if !ok || s.GetSize() != item.Size || s.GetSizeSystem() != item.SizeSystem {
+			warnings = append(warnings, fmt.Sprintf(t.CartUnavailable, itemTitle(t, item)))
+			if _, err := b.cart.SetQuantity(ctx, chatID, item.SneakerID, 0); err != nil {
+				return nil, nil, fmt.Errorf("remove unavailable item: %w", err)
+			}
+			continue
+		}
+
+		price := currentPrice(s)
+		amount, currency := moneyAmount(price), price.GetCurrencyCode()
+		if currency != item.Currency || !amount.Equal(item.Price) {
+			if currency != item.Currency || amount.GreaterThan(item.Price) {
+				warnings = append(warnings, fmt.Sprintf(t.CartPriceUp, itemTitle(t, item),
+					formatAmount(item.Price, item.Currency), formatAmount(amount, currency)))
+			}
+			if err := b.cart.Reprice(ctx, chatID, item.SneakerID, amount, currency); err != nil {
+				return nil, nil, fmt.Errorf("reprice cart item: %w", err)
+			}
+			item.Price, item.Currency = amount, currency
+		}
+		valid = append(valid, item)
+	}
+	return valid, warnings, nil
+}
+
+// cartMessage - корзина отдельным сообщением, с предупреждениями над ней.
+func cartMessage(t *messages, chatID int64, items []cart.Item, notice string) tgbotapi.MessageConfig {
+	text, markup := renderCart(t, items)
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+	out := tgbotapi.NewMessage(chatID, text)
+	out.ReplyMarkup = markup
+	return out
+}
+
+func renderCart(t *messages, items []cart.Item) (string, tgbotapi.InlineKeyboardMarkup) {
+	if len(items) == 0 {
+		return t.CartEmpty, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, item := range items {
+		n := strconv.Itoa(i + 1)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➖ "+n, cartData(cartDec, item.SneakerID)),
+			tgbotapi.NewInlineKeyboardButtonData("➕ "+n, cartData(cartInc, item.SneakerID)),
+			tgbotapi.NewInlineKeyboardButtonData("✖ "+n, cartData(cartDel, item.SneakerID)),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnCheckout, cartPrefix+":"+cartCheckout),
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnClearCart, cartPrefix+":"+cartClear),
+	))
+	return t.CartTitle + "\n" + renderItems(t, items), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// renderItems - позиции с количеством и итог; суммы в разных валютах не складываются.
+func renderItems(t *messages, items []cart.Item) string {
+	var (
+		sb         strings.Builder
+		currencies []string
+		totals     = make(map[string]decimal.Decimal)
+	)
+	for i, item := range items {
+		fmt.Fprintf(&sb, "%d. %s\n   %d × %s = %s\n", i+1, itemTitle(t, item), item.Quantity,
+			formatAmount(item.Price, item.Currency), formatAmount(item.Total(), item.Currency))
+		if _, ok := totals[item.Currency]; !ok {
+			currencies = append(currencies, item.Currency)
+		}
+		totals[item.Currency] = totals[item.Currency].Add(item.Total())
+	}
+
+	sums := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		sums = append(sums, formatAmount(totals[currency], currency))
+	}
+	fmt.Fprintf(&sb, "%s: %s", t.CartTotal, strings.Join(sums, " + "))
+	return sb.String()
+}
+
+// itemTitle - "Air Max 90, 42.5 EU (ART-001)"
+func itemTitle(t *messages, item cart.Item) string {
+	return fmt.Sprintf("%s, %s (%s)", item.Name, formatSize(t, item.Size, item.SizeSystem), item.Article)
+}
+
+func cartData(action string, sneakerID int32) string {
+	return cartPrefix + ":" + action + ":" + strconv.Itoa(int(sneakerID))
+}
@@ -0,0 +1,244 @@
+package bot_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newShopBot(t *testing.T, inventory *fakeInventory) (*bot.Bot, *telegramtest.Server, *cart.MemoryStore, *orders.MemoryService) {
+	t.Helper()
+	server := telegramtest.NewServer(t)
+	carts := cart.NewMemoryStore()
+	service := orders.NewMemoryService()
+	b := bot.New(server.BotAPI(t), inventory, zap.NewNop(), bot.Settings{
+		Admins: []int64{adminID},
+		Cart:   carts,
+		Orders: service,
+	})
+	return b, server, carts, service
+}
+
+func lastAnswer(t *testing.T, server *telegramtest.Server) string {
+	t.Helper()
+	calls := server.Calls("answerCallbackQuery")
+	require.NotEmpty(t, calls)
+	return calls[len(calls)-1].Params.Get("text")
+}
+
+// Кнопка карточки кладёт товар в корзину, кнопки корзины меняют количество.
+func TestCart_AddAndQuantities(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	b, server, carts, _ := newShopBot(t, &fakeInventory{sneakers: testSneakers()})
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 6, "cart:add:2"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 6, "cart:add:9"))
+
+	// --- Assert ---
+	answers := server.Calls("answerCallbackQuery")
+	require.Len(answers, 4)
+	require.Equal("В корзине: 2 шт. /cart - корзина", answers[1].Params.Get("text"))
+	require.Equal("Этого товара уже нет в продаже", answers[3].Params.Get("text"))
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/cart"))
+
+	// --- Assert ---
+	sent := server.Calls("sendMessage")
+	require.Len(sent, 1)
+	require.Equal("Корзина:\n"+
+		"1. Air Max 90, 42.5 EU (ART-001)\n   2 × 9990.50 ₽ = 19981.00 ₽\n"+
+		"2. Samba <OG>, 9 UK (ART-002)\n   1 × 10990.00 ₽ = 10990.00 ₽\n"+
+		"Итого: 30971.00 ₽", sent[0].Params.Get("text"))
+	buttons := keyboard(t, sent[0])
+	require.Len(buttons, 3)
+	require.Equal("cart:dec:1", *buttons[0][0].CallbackData)
+	require.Equal("cart:inc:2", *buttons[1][1].CallbackData)
+	require.Equal("cart:checkout", *buttons[2][0].CallbackData)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 101, "cart:dec:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 101, "cart:del:2"))
+
+	// --- Assert ---
+	edits := server.Calls("editMessageText")
+	require.Len(edits, 2)
+	require.Equal("101", edits[1].Params.Get("message_id"))
+	require.Equal("Корзина:\n1. Air Max 90, 42.5 EU (ART-001)\n   1 × 9990.50 ₽ = 9990.50 ₽\nИтого: 9990.50 ₽", edits[1].Params.Get("text"))
+
+	// --- Act ---
+	for range cart.MaxQuantity {
+		b.HandleUpdate(ctx, telegramtest.Callback(7, 101, "cart:inc:1"))
+	}
+
+	// --- Assert ---
+	require.Equal("Не больше 10 пар одного товара", lastAnswer(t, server))
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 101, "cart:clear"))
+
+	// --- Assert ---
+	require.Equal("Корзина пуста. /catalog - каталог", lastText(t, server, "editMessageText"))
+	items, err := carts.Items(ctx, 7)
+	require.NoError(err)
+	require.Empty(items)
+}
+
+// Оформление собирает имя, телефон и адрес, создаёт заказ по ценам inventory и очищает корзину.
+// Слишком длинное для inventory_service имя отклоняется на шаге диалога.
+func TestCheckout_Flow(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	b, server, carts, service := newShopBot(t, &fakeInventory{sneakers: testSneakers()})
+	ctx := context.Background()
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+
+	// --- Act ---
+	longName := strings.Repeat("Я", 128)
+	for _, text := range []string{"/checkout", longName, "Иван Петров", "позвоните мне", "+7 (999) 123-45-67"} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+	}
+
+	// --- Assert ---
+	sent := sentTo(server, "7")
+	require.Len(sent, 5)
+	require.Contains(sent[0], "Как к вам обращаться")
+	require.Contains(sent[1], "Слишком длинно", "256 байт при пределе 255")
+	require.Contains(sent[2], "Телефон для связи")
+	require.Contains(server.Calls("sendMessage")[2].Params.Get("reply_markup"), `"request_contact":true`)
+	require.Equal("Не понял номер. Пример: +7 999 123-45-67", sent[3])
+	require.Equal("Адрес доставки:", sent[4])
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(7, "Москва, Тверская 1"))
+	b.HandleUpdate(ctx, telegramtest.Message(7, "ещё что-то"))
+
+	// --- Assert ---
+	sent = sentTo(server, "7")
+	require.Equal("Проверьте заказ:\n"+
+		"1. Air Max 90, 42.5 EU (ART-001)\n   2 × 9990.50 ₽ = 19981.00 ₽\nИтого: 19981.00 ₽\n\n"+
+		"Получатель: Иван Петров\nТелефон: +79991234567\nАдрес: Москва, Тверская 1", sent[5])
+	require.Contains(sent[6], "Подтвердите заказ кнопкой")
+	require.Empty(service.Orders())
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 105, "checkout:confirm"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 105, "checkout:confirm"))
+
+	// --- Assert ---
+	placed := service.Orders()
+	require.Len(placed, 1, "повторное нажатие не создаёт второй заказ")
+	order := placed[0]
+	require.Equal(orders.Customer{ChatID: 7, Name: "Иван Петров", Phone: "+79991234567", Address: "Москва, Тверская 1"}, order.Customer)
+	require.Equal("RUB", order.Currency)
+	require.Equal("19981", order.Total.String())
+	require.Len(order.Lines, 1)
+	require.Equal("ART-001", order.Lines[0].Article)
+	require.Equal(float32(42.5), order.Lines[0].Size)
+	require.Equal(2, order.Lines[0].Quantity)
+	require.Equal("9990.5", order.Lines[0].Price.String())
+
+	require.Equal("Заказ №1 на 19981.00 ₽ оформлен. Мы позвоним по номеру +79991234567, чтобы подтвердить доставку",
+		lastText(t, server, "editMessageText"))
+	require.Contains(sentTo(server, "100")[0], "Новый заказ №1")
+	items, err := carts.Items(ctx, 7)
+	require.NoError(err)
+	require.Empty(items)
+}
+
+// Подорожание и снятие товара с продажи показываются до оформления и перед подтверждением.
+func TestCheckout_Revalidation(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server, carts, service := newShopBot(t, inventory)
+	ctx := context.Background()
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:2"))
+
+	inventory.sneakers[0].EffectivePrice = &pb.Money{CurrencyCode: "RUB", Units: 11990}
+	inventory.sneakers = inventory.sneakers[:1]
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/checkout"))
+
+	// --- Assert ---
+	require.Equal("Air Max 90, 42.5 EU (ART-001) подорожал: 9990.50 ₽ → 11990.00 ₽\n"+
+		"Samba <OG>, 9 UK (ART-002) больше нет в продаже, товар убран из корзины\n"+
+		"Проверьте корзину и нажмите «Оформить заказ» ещё раз\n\n"+
+		"Корзина:\n1. Air Max 90, 42.5 EU (ART-001)\n   1 × 11990.00 ₽ = 11990.00 ₽\nИтого: 11990.00 ₽", lastText(t, server, "sendMessage"))
+	items, err := carts.Items(ctx, 7)
+	require.NoError(err)
+	require.Len(items, 1)
+	require.Equal("11990", items[0].Price.String())
+
+	// --- Act ---
+	for _, text := range []string{"/checkout", "Иван", "89991234567", "Москва"} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+	}
+	inventory.sneakers[0].EffectivePrice = &pb.Money{CurrencyCode: "RUB", Units: 12490}
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 110, "checkout:confirm"))
+
+	// --- Assert ---
+	require.Empty(service.Orders())
+	require.Contains(lastText(t, server, "sendMessage"), "подорожал: 11990.00 ₽ → 12490.00 ₽\n\nПроверьте заказ:")
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 111, "checkout:confirm"))
+
+	// --- Assert ---
+	require.Len(service.Orders(), 1)
+	require.Equal("12490", service.Orders()[0].Total.String())
+}
+
+// conflictingOrders отклоняет каждый заказ, как сервис заказов при изменившейся цене.
+type conflictingOrders struct {
+	orders.Service
+	calls int
+}
+
+func (c *conflictingOrders) Create(ctx context.Context, order orders.Order) (orders.Order, error) {
+	c.calls++
+	return orders.Order{}, fmt.Errorf("%w: price of ART-001 is 10990.00 RUB", orders.ErrConflict)
+}
+
+// Отказ сервиса заказов из-за цены не теряет корзину, и заказ можно подтвердить ещё раз.
+func TestCheckout_OrderConflict(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	carts := cart.NewMemoryStore()
+	service := &conflictingOrders{}
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: testSneakers()}, zap.NewNop(), bot.Settings{Cart: carts, Orders: service})
+	ctx := context.Background()
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	for _, text := range []string{"/checkout", "Иван", "89991234567", "Москва"} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+	}
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 110, "checkout:confirm"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 110, "checkout:confirm"))
+
+	// --- Assert ---
+	require.Equal(2, service.calls)
+	require.Contains(lastText(t, server, "sendMessage"), "изменились цены или наличие")
+	items, err := carts.Items(ctx, 7)
+	require.NoError(err)
+	require.Len(items, 1)
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+// catalogPrefix - префикс callback-данных листания каталога, "catalog:<offset>"
+const catalogPrefix = "catalog"
+
+// catalogPage - страница каталога: карточка товара со смещением offset
+type catalogPage struct {
+	sneaker *pb.Sneaker
+	offset  int
+	hasNext bool
+}
+
+func (b *Bot) handleCatalog(ctx context.Context, msg *tgbotapi.Message) error {
+	page, err := b.loadCatalogPage(ctx, 0)
+	if err != nil {
+		return err
+	}
+	t := b.texts(msg.From)
+	if page.sneaker == nil {
+		b.reply(msg.Chat.ID, t.CatalogEmpty)
+		return nil
+	}
+
+	out := tgbotapi.NewMessage(msg.Chat.ID, b.renderCard(t, page.sneaker))
+	out.ParseMode = tgbotapi.ModeHTML
+	out.ReplyMarkup = catalogKeyboard(t, page)
+	b.send(out)
+	return nil
+}
+
+func (b *Bot) handleCatalogPage(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error {
+	offset, err := strconv.Atoi(args)
+	if err != nil || offset < 0 {
+		b.answer(query.ID, "")
+		return nil
+	}
+
+	page, err := b.loadCatalogPage(ctx, offset)
+	if err != nil {
+		return err
+	}
+	// Товары могли удалить, пока пользователь листал
+	t := b.texts(query.From)
+	if page.sneaker == nil || query.Message == nil {
+		b.answer(query.ID, t.CatalogEnd)
+		return nil
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(query.Message.Chat.ID, query.Message.MessageID, b.renderCard(t, page.sneaker), catalogKeyboard(t, page))
+	edit.ParseMode = tgbotapi.ModeHTML
+	b.send(edit)
+	b.answer(query.ID, "")
+	return nil
+}
+
+// loadCatalogPage запрашивает товар со смещением offset и следующий за ним,
+// чтобы знать, показывать ли кнопку "вперёд".
+func (b *Bot) loadCatalogPage(ctx context.Context, offset int) (catalogPage, error) {
+	resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{
+		Partition: 2,
+		Offset:    int32(offset),
+	})
+	if err != nil {
+		return catalogPage{}, fmt.Errorf("get sneakers: %w", err)
+	}
+	if resp.GetStatusCode() != http.StatusOK {
+		return catalogPage{}, fmt.Errorf("get sneakers: status %d: %s", resp.GetStatusCode(), resp.GetErrorMessage())
+	}
+
+	page := catalogPage{offset: offset}
+	if sneakers := resp.GetSneakers(); len(sneakers) > 0 {
+		page.sneaker = sneakers[0]
+		page.hasNext = len(sneakers) > 1
+	}
+	return page, nil
+}
+
+func catalogKeyboard(t *messages, page catalogPage) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	if page.offset > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(t.BtnPrev, catalogData(page.offset-1)))
+	}
+	if page.hasNext {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(t.BtnNext, catalogData(page.offset+1)))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnAddToCart, cartData(cartAdd, page.sneaker.GetSneakerId())),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func catalogData(offset int) string {
+	return catalogPrefix + ":" + strconv.Itoa(offset)
+}
@@ -0,0 +1,154 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/dispatch"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testSneakers() []*pb.Sneaker {
+	return []*pb.Sneaker{
+		{
+			SneakerId: 1, Article: "ART-001", SneakerName: "Air Max 90", Brand: "Nike",
+			Price:          &pb.Money{CurrencyCode: "RUB", Units: 12990},
+			EffectivePrice: &pb.Money{CurrencyCode: "RUB", Units: 9990, Nanos: 500_000_000},
+			Size:           42.5, SizeSystem: "EU",
+			SizeEquivalents: []*pb.SneakerSize{{System: "EU", Value: 42.5}, {System: "US_M", Value: 9}},
+		},
+		{
+			SneakerId: 2, Article: "ART-002", SneakerName: "Samba <OG>", Brand: "Adidas",
+			Price:          &pb.Money{CurrencyCode: "RUB", Units: 10990},
+			EffectivePrice: &pb.Money{CurrencyCode: "RUB", Units: 10990},
+			Size:           9, SizeSystem: "UK",
+		},
+	}
+}
+
+func keyboard(t *testing.T, call telegramtest.Call) [][]tgbotapi.InlineKeyboardButton {
+	t.Helper()
+	var markup tgbotapi.InlineKeyboardMarkup
+	require.NoError(t, json.Unmarshal([]byte(call.Params.Get("reply_markup")), &markup))
+	return markup.InlineKeyboard
+}
+
+// /catalog показывает первую карточку с кнопкой "вперёд", кнопка листает на следующую.
+func TestCatalog_Paging(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: testSneakers()}, zap.NewNop(), bot.Settings{})
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(42, "/catalog"))
+
+	// --- Assert ---
+	sent := server.Calls("sendMessage")
+	require.Len(sent, 1)
+	require.Equal("42", sent[0].Params.Get("chat_id"))
+	require.Equal("HTML", sent[0].Params.Get("parse_mode"))
+	text := sent[0].Params.Get("text")
+	require.Contains(text, "<b>Air Max 90</b>")
+	require.Contains(text, "<s>12990.00 ₽</s> <b>9990.50 ₽</b>")
+	require.Contains(text, "42.5 EU (9 US M)")
+	buttons := keyboard(t, sent[0])
+	require.Len(buttons, 2)
+	require.Len(buttons[0], 1)
+	require.Equal("catalog:1", *buttons[0][0].CallbackData)
+	require.Equal("cart:add:1", *buttons[1][0].CallbackData)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Callback(42, 101, "catalog:1"))
+
+	// --- Assert ---
+	edits := server.Calls("editMessageText")
+	require.Len(edits, 1)
+	require.Equal("101", edits[0].Params.Get("message_id"))
+	text = edits[0].Params.Get("text")
+	require.Contains(text, "Samba &lt;OG&gt;")
+	require.Contains(text, "10990.00 ₽")
+	require.NotContains(text, "<s>")
+	require.Contains(text, "9 UK")
+	buttons = keyboard(t, edits[0])
+	require.Len(buttons[0], 1)
+	require.Equal("catalog:0", *buttons[0][0].CallbackData)
+	require.Equal("cart:add:2", *buttons[1][0].CallbackData)
+	require.Len(server.Calls("answerCallbackQuery"), 1)
+}
+
+func TestCatalog_Empty(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{}, zap.NewNop(), bot.Settings{})
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.Message(42, "/catalog"))
+
+	// --- Assert ---
+	sent := server.Calls("sendMessage")
+	require.Len(sent, 1)
+	require.Equal("Каталог пока пуст", sent[0].Params.Get("text"))
+}
+
+// Бот забирает обновления через getUpdates и отвечает на них.
+func TestRunPolling(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: testSneakers()}, zap.NewNop(), bot.Settings{})
+	d := dispatch.New(b.HandleUpdate, 4, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		b.RunPolling(ctx, d, time.Second)
+		close(done)
+	}()
+
+	// --- Act ---
+	server.PushUpdate(telegramtest.Message(7, "/catalog"))
+
+	// --- Assert ---
+	sent := server.WaitCalls(t, "sendMessage", 1)
+	require.Equal("7", sent[0].Params.Get("chat_id"))
+	require.Contains(sent[0].Params.Get("text"), "Air Max 90")
+
+	cancel()
+	<-done
+	require.NoError(d.Shutdown(context.Background()))
+}
+
+// Язык берётся из профиля пользователя, неподдерживаемый язык - из настроек бота.
+func TestCatalog_Locale(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{}, zap.NewNop(), bot.Settings{Language: "en"})
+	english := telegramtest.Message(1, "/catalog")
+	english.Message.From.LanguageCode = "en-US"
+	russian := telegramtest.Message(2, "/catalog")
+	russian.Message.From.LanguageCode = "ru"
+	other := telegramtest.Message(3, "/catalog")
+	other.Message.From.LanguageCode = "de"
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), english)
+	b.HandleUpdate(context.Background(), russian)
+	b.HandleUpdate(context.Background(), other)
+
+	// --- Assert ---
+	sent := server.Calls("sendMessage")
+	require.Len(sent, 3)
+	require.Equal("The catalog is empty for now", sent[0].Params.Get("text"))
+	require.Equal("Каталог пока пуст", sent[1].Params.Get("text"))
+	require.Equal("The catalog is empty for now", sent[2].Params.Get("text"))
+}
@@ -0,0 +1,347 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Диалог оформления заказа: имя, телефон, адрес и подтверждение кнопкой.
+const checkoutFlow = "checkout"
+
+const (
+	stateCheckoutName    fsm.State = "checkout.name"
+	stateCheckoutPhone   fsm.State = "checkout.phone"
+	stateCheckoutAddress fsm.State = "checkout.address"
+	stateCheckoutConfirm fsm.State = "checkout.confirm"
+	// stateCheckoutPlacing - заказ создаётся; повторное нажатие "подтвердить"
+	// в этот момент получит конфликт версии и второй заказ не создаст
+	stateCheckoutPlacing fsm.State = "checkout.placing"
+)
+
+const (
+	eventConfirm fsm.Event = "confirm"
+	eventPlaced  fsm.Event = "placed"
+	eventFailed  fsm.Event = "failed"
+)
+
+// checkoutPrefix - префикс callback-данных подтверждения, "checkout:confirm" или "checkout:cancel"
+const checkoutPrefix = "checkout"
+
+// Пределы длины данных покупателя в байтах, как их проверяет inventory_service
+const (
+	maxCustomerName    = 255
+	maxCustomerPhone   = 32
+	maxCustomerAddress = 500
+)
+
+// checkoutDraft - данные покупателя, собранные диалогом.
+type checkoutDraft struct {
+	Name    string `json:"name,omitempty"`
+	Phone   string `json:"phone,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+func checkoutDefinition(settings Settings) fsm.Definition {
+	return fsm.Definition{
+		Flow: checkoutFlow,
+		Transitions: []fsm.Transition{
+			{From: stateCheckoutName, Event: eventAnswer, To: stateCheckoutPhone},
+			{From: stateCheckoutPhone, Event: eventAnswer, To: stateCheckoutAddress},
+			{From: stateCheckoutAddress, Event: eventAnswer, To: stateCheckoutConfirm},
+			{From: stateCheckoutConfirm, Event: eventConfirm, To: stateCheckoutPlacing},
+			{From: stateCheckoutPlacing, Event: eventPlaced, To: fsm.None},
+			{From: stateCheckoutPlacing, Event: eventFailed, To: stateCheckoutConfirm},
+		},
+		Timeout: settings.DialogTimeout,
+	}
+}
+
+func (b *Bot) handleCheckout(ctx context.Context, msg *tgbotapi.Message) error {
+	return b.startCheckout(ctx, msg.Chat.ID, msg.From)
+}
+
+// startCheckout сверяет корзину с inventory и, если ничего не изменилось, начинает диалог.
+// Об изменениях покупатель узнаёт до ввода данных, корзина показывается заново.
+func (b *Bot) startCheckout(ctx context.Context, chatID int64, user *tgbotapi.User) error {
+	t := b.texts(user)
+	items, warnings, err := b.revalidateCart(ctx, chatID, t)
+	if err != nil {
+		return err
+	}
+	if len(warnings) > 0 {
+		b.send(cartMessage(t, chatID, items, strings.Join(warnings, "\n")+"\n"+t.CartCheckAgain))
+		return nil
+	}
+	if len(items) == 0 {
+		b.reply(chatID, t.CartEmpty)
+		return nil
+	}
+	if _, ok := cartCurrency(items); !ok {
+		b.reply(chatID, t.CartMixedCurrencies)
+		return nil
+	}
+
+	if _, err := b.checkoutDialog.Start(ctx, chatID, stateCheckoutName, checkoutDraft{}); err != nil {
+		return b.dialogFailed(chatID, t, err)
+	}
+	b.reply(chatID, t.AskCustomerName)
+	return nil
+}
+
+// handleCheckoutInput принимает имя, телефон или адрес.
+func (b *Bot) handleCheckoutInput(ctx context.Context, msg *tgbotapi.Message, s *fsm.Session[checkoutDraft]) error {
+	t := b.texts(msg.From)
+	chatID := msg.Chat.ID
+	if !b.checkoutDialog.Can(s, eventAnswer) {
+		b.reply(chatID, t.CheckoutConfirmFirst)
+		return nil
+	}
+
+	text := strings.TrimSpace(msg.Text)
+	switch s.State {
+	case stateCheckoutName:
+		if problem := customerFieldProblem(t, text, maxCustomerName); problem != "" {
+			b.reply(chatID, problem+"\n"+t.AskCustomerName)
+			return nil
+		}
+		s.Data.Name = text
+	case stateCheckoutPhone:
+		if msg.Contact != nil {
+			text = msg.Contact.PhoneNumber
+		}
+		phone, ok := parsePhone(text)
+		if !ok || len(phone) > maxCustomerPhone {
+			b.reply(chatID, t.BadPhone)
+			return nil
+		}
+		s.Data.Phone = phone
+	case stateCheckoutAddress:
+		if problem := customerFieldProblem(t, text, maxCustomerAddress); problem != "" {
+			b.reply(chatID, problem+"\n"+t.AskAddress)
+			return nil
+		}
+		s.Data.Address = text
+	}
+
+	if err := b.checkoutDialog.Fire(ctx, s, eventAnswer); err != nil {
+		return b.dialogFailed(chatID, t, err)
+	}
+
+	switch s.State {
+	case stateCheckoutPhone:
+		out := tgbotapi.NewMessage(chatID, t.AskPhone)
+		keyboard := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonContact(t.BtnSharePhone)))
+		keyboard.OneTimeKeyboard = true
+		keyboard.ResizeKeyboard = true
+		out.ReplyMarkup = keyboard
+		b.send(out)
+	case stateCheckoutAddress:
+		out := tgbotapi.NewMessage(chatID, t.AskAddress)
+		out.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+		b.send(out)
+	case stateCheckoutConfirm:
+		items, err := b.cart.Items(ctx, chatID)
+		if err != nil {
+			return fmt.Errorf("load cart: %w", err)
+		}
+		b.send(checkoutSummary(t, chatID, items, s.Data, ""))
+	}
+	return nil
+}
+
+// customerFieldProblem - что не так с ответом покупателя, пустая строка - всё в порядке.
+func customerFieldProblem(t *messages, text string, limit int) string {
+	switch {
+	case text == "":
+		return t.EmptyValue
+	case len(text) > limit:
+		return t.ValueTooLong
+	}
+	return ""
+}
+
+func (b *Bot) handleCheckoutButton(ctx context.Context, query *tgbotapi.CallbackQuery, args string) error {
+	t := b.texts(query.From)
+	if query.Message == nil {
+		b.answer(query.ID, "")
+		return nil
+	}
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	s, ok, err := loadDialog(ctx, b, b.checkoutDialog, chatID, query.From)
+	if err != nil {
+		return err
+	}
+	if !ok || !b.checkoutDialog.Can(s, eventConfirm) {
+		// Кнопка под старым подтверждением: заказ уже оформлен или отменён
+		b.answer(query.ID, "")
+		return nil
+	}
+
+	if args != string(eventConfirm) {
+		if err := b.checkoutDialog.Finish(ctx, chatID); err != nil {
+			return err
+		}
+		b.answer(query.ID, "")
+		b.send(tgbotapi.NewEditMessageText(chatID, messageID, t.Cancelled))
+		return nil
+	}
+	return b.placeOrder(ctx, query, s)
+}
+
+// placeOrder ещё раз сверяет корзину и создаёт заказ. Если цены или наличие
+// изменились, покупатель видит новую сводку и подтверждает её заново.
+func (b *Bot) placeOrder(ctx context.Context, query *tgbotapi.CallbackQuery, s *fsm.Session[checkoutDraft]) error {
+	t := b.texts(query.From)
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+
+	items, warnings, err := b.revalidateCart(ctx, chatID, t)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		if err := b.checkoutDialog.Finish(ctx, chatID); err != nil {
+			return err
+		}
+		b.answer(query.ID, "")
+		b.send(tgbotapi.NewEditMessageText(chatID, messageID, strings.TrimSpace(strings.Join(warnings, "\n")+"\n"+t.CartEmpty)))
+		return nil
+	}
+	currency, ok := cartCurrency(items)
+	if len(warnings) > 0 || !ok {
+		if !ok {
+			warnings = append(warnings, t.CartMixedCurrencies)
+		}
+		b.answer(query.ID, "")
+		b.send(checkoutSummary(t, chatID, items, s.Data, strings.Join(warnings, "\n")))
+		return nil
+	}
+
+	if err := b.checkoutDialog.Fire(ctx, s, eventConfirm); err != nil {
+		b.answer(query.ID, "")
+		return b.dialogFailed(chatID, t, err)
+	}
+
+	order, err := b.orders.Create(ctx, newOrder(chatID, s.Data, items, currency))
+	if err != nil {
+		// Возвращаемся к подтверждению, чтобы заказ можно было отправить ещё раз
+		if err := b.checkoutDialog.Fire(ctx, s, eventFailed); err != nil {
+			b.log.Error("ERROR: reopen checkout", zap.Int64("chat_id", chatID), zap.Error(err))
+		}
+		// Цена поменялась между проверкой корзины и заказом: повторное
+		// подтверждение снова сверит корзину и покажет изменения
+		if errors.Is(err, orders.ErrConflict) {
+			b.log.Warn("order rejected", zap.Int64("chat_id", chatID), zap.Error(err))
+			b.answer(query.ID, "")
+			b.send(tgbotapi.NewMessage(chatID, t.OrderConflict))
+			return nil
+		}
+		return fmt.Errorf("create order: %w", err)
+	}
+	b.answer(query.ID, "")
+
+	if err := b.checkoutDialog.Fire(ctx, s, eventPlaced); err != nil {
+		b.log.Error("ERROR: finish checkout", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+	if err := b.cart.Clear(ctx, chatID); err != nil {
+		b.log.Error("ERROR: clear cart", zap.Int64("chat_id", chatID), zap.Error(err))
+	}
+
+	total := formatAmount(order.Total, order.Currency)
+	if b.payments == nil {
+		b.send(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf(t.OrderPlaced, order.ID, total, order.Customer.Phone)))
+		b.notifyAdmins(order)
+		return nil
+	}
+
+	b.send(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf(t.OrderAwaitingPayment, order.ID, total)))
+	b.notifyAdmins(order)
+	if err := b.sendInvoice(ctx, chatID, t, order); err != nil {
+		// Заказ уже создан, его оплатят после звонка менеджера
+		b.log.Error("ERROR: send invoice", zap.Int64("order_id", order.ID), zap.Error(err))
+		b.reply(chatID, fmt.Sprintf(t.InvoiceFailed, order.Customer.Phone))
+	}
+	return nil
+}
+
+// notifyAdmins пересылает новый заказ администраторам магазина.
+func (b *Bot) notifyAdmins(order orders.Order) {
+	t := b.textsFor("")
+	text := fmt.Sprintf(t.AdminNewOrder, order.ID) + "\n" + renderItems(t, orderItems(order)) + "\n\n" +
+		fmt.Sprintf(t.CheckoutCustomer, order.Customer.Name, order.Customer.Phone, order.Customer.Address)
+	for _, admin := range b.settings.Admins {
+		b.reply(admin, text)
+	}
+}
+
+// checkoutSummary - заказ перед подтверждением, с предупреждениями над ним.
+func checkoutSummary(t *messages, chatID int64, items []cart.Item, draft checkoutDraft, notice string) tgbotapi.MessageConfig {
+	text := t.CheckoutSummary + "\n" + renderItems(t, items) + "\n\n" +
+		fmt.Sprintf(t.CheckoutCustomer, draft.Name, draft.Phone, draft.Address)
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+	out := tgbotapi.NewMessage(chatID, text)
+	out.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnConfirmOrder, checkoutPrefix+":"+string(eventConfirm)),
+		tgbotapi.NewInlineKeyboardButtonData(t.BtnCancel, checkoutPrefix+":cancel"),
+	))
+	return out
+}
+
+// newOrder - заказ из проверенной корзины с ценами на момент оформления.
+func newOrder(chatID int64, draft checkoutDraft, items []cart.Item, currency string) orders.Order {
+	order := orders.Order{
+		Customer: orders.Customer{ChatID: chatID, Name: draft.Name, Phone: draft.Phone, Address: draft.Address},
+		Currency: currency,
+		Total:    decimal.Zero,
+	}
+	for _, item := range items {
+		order.Lines = append(order.Lines, orders.Line{
+			SneakerID:  item.SneakerID,
+			Article:    item.Article,
+			Name:       item.Name,
+			Size:       item.Size,
+			SizeSystem: item.SizeSystem,
+			Quantity:   item.Quantity,
+			Price:      item.Price,
+		})
+		order.Total = order.Total.Add(item.Total())
+	}
+	return order
+}
+
+// orderItems - позиции заказа в виде корзины, чтобы показать их тем же renderItems.
+func orderItems(order orders.Order) []cart.Item {
+	items := make([]cart.Item, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		items = append(items, cart.Item{
+			SneakerID: line.SneakerID, Article: line.Article, Name: line.Name,
+			Size: line.Size, SizeSystem: line.SizeSystem, Quantity: line.Quantity,
+			Price: line.Price, Currency: order.Currency,
+		})
+	}
+	return items
+}
+
+// cartCurrency - валюта корзины; false - товары в разных валютах, одним заказом их не оформить.
+func cartCurrency(items []cart.Item) (string, bool) {
+	if len(items) == 0 {
+		return "", false
+	}
+	for _, item := range items[1:] {
+		if item.Currency != items[0].Currency {
+			return "", false
+		}
+	}
+	return items[0].Currency, true
+}
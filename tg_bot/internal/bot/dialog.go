@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"go.uber.org/zap"
+)
+
+// Диалог управления каталогом: /add проходит шаги add.*, /edit - выбор поля и значение.
+const catalogFlow = "catalog"
+
+const (
+	stateAddArticle fsm.State = "add." + stepArticle
+	stateAddName    fsm.State = "add." + stepName
+	stateAddBrand   fsm.State = "add." + stepBrand
+	stateAddPrice   fsm.State = "add." + stepPrice
+	stateAddSize    fsm.State = "add." + stepSize
+	stateAddPhoto   fsm.State = "add." + stepPhoto
+
+	stateEditChoose fsm.State = "edit.choose"
+	stateEditValue  fsm.State = "edit.value"
+)
+
+const (
+	// eventAnswer - принят ответ на вопрос шага
+	eventAnswer fsm.Event = "answer"
+	// eventSkip - необязательный шаг пропущен через /skip
+	eventSkip fsm.Event = "skip"
+	// eventChoose - в /edit выбрано поле
+	eventChoose fsm.Event = "choose"
+)
+
+// catalogDraft - данные диалога: черновик товара и, в /edit, выбранное поле.
+type catalogDraft struct {
+	Field   string      `json:"field,omitempty"`
+	Sneaker *pb.Sneaker `json:"sneaker"`
+}
+
+func catalogDefinition(settings Settings) fsm.Definition {
+	return fsm.Definition{
+		Flow: catalogFlow,
+		Transitions: []fsm.Transition{
+			{From: stateAddArticle, Event: eventAnswer, To: stateAddName},
+			{From: stateAddName, Event: eventAnswer, To: stateAddBrand},
+			{From: stateAddBrand, Event: eventAnswer, To: stateAddPrice},
+			{From: stateAddPrice, Event: eventAnswer, To: stateAddSize},
+			{From: stateAddSize, Event: eventAnswer, To: stateAddPhoto},
+			{From: stateAddPhoto, Event: eventAnswer, To: fsm.None},
+			{From: stateAddPhoto, Event: eventSkip, To: fsm.None},
+
+			{From: stateEditChoose, Event: eventChoose, To: stateEditValue},
+			{From: stateEditValue, Event: eventAnswer, To: fsm.None},
+		},
+		Timeout: settings.DialogTimeout,
+	}
+}
+
+// field - поле товара, которое ждёт шаг диалога.
+func (d catalogDraft) field(state fsm.State) string {
+	if state == stateEditValue {
+		return d.Field
+	}
+	step, _ := strings.CutPrefix(string(state), "add.")
+	return step
+}
+
+// handleDialogMessage передаёт обычное сообщение в открытый диалог чата.
+// Без диалога сообщение игнорируется.
+func (b *Bot) handleDialogMessage(ctx context.Context, msg *tgbotapi.Message) error {
+	rec, err := b.dialogs.Load(ctx, msg.Chat.ID)
+	if errors.Is(err, fsm.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load dialog: %w", err)
+	}
+
+	switch rec.Flow {
+	case catalogFlow:
+		s, ok, err := b.loadCatalogDialog(ctx, msg.Chat.ID, msg.From)
+		if err != nil || !ok {
+			return err
+		}
+		return b.handleDialogInput(ctx, msg, s)
+	case checkoutFlow:
+		s, ok, err := loadDialog(ctx, b, b.checkoutDialog, msg.Chat.ID, msg.From)
+		if err != nil || !ok {
+			return err
+		}
+		return b.handleCheckoutInput(ctx, msg, s)
+	}
+	return nil
+}
+
+func (b *Bot) loadCatalogDialog(ctx context.Context, chatID int64, user *tgbotapi.User) (*fsm.Session[catalogDraft], bool, error) {
+	return loadDialog(ctx, b, b.catalogDialog, chatID, user)
+}
+
+// loadDialog загружает диалог чата; ok=false - диалога нет,
+// а если он истёк, пользователь об этом уже предупреждён.
+func loadDialog[D any](ctx context.Context, b *Bot, m *fsm.Machine[D], chatID int64, user *tgbotapi.User) (*fsm.Session[D], bool, error) {
+	s, err := m.Load(ctx, chatID)
+	switch {
+	case err == nil:
+		return s, true, nil
+	case errors.Is(err, fsm.ErrNotFound):
+		return nil, false, nil
+	case errors.Is(err, fsm.ErrExpired):
+		b.reply(chatID, b.texts(user).DialogExpired)
+		return nil, false, nil
+	}
+	return nil, false, err
+}
+
+// dialogFailed сообщает о неудачной записи диалога. Конфликт - не сбой:
+// этот же чат параллельно обработала другая реплика, ответ нужно повторить.
+func (b *Bot) dialogFailed(chatID int64, t *messages, err error) error {
+	if errors.Is(err, fsm.ErrConflict) {
+		b.log.Warn("dialog changed concurrently", zap.Int64("chat_id", chatID))
+		b.reply(chatID, t.DialogConflict)
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+const (
+	// inlinePageSize - результатов в одном ответе, Telegram принимает до 50
+	inlinePageSize = 20
+	// inlineCacheSize - сколько страниц поиска держать в памяти
+	inlineCacheSize = 1000
+)
+
+// searchPage - страница результатов поиска
+type searchPage struct {
+	sneakers []*pb.Sneaker
+	hasNext  bool
+}
+
+// handleInlineQuery отвечает на "@bot air max 42": ищет товары по подсказкам
+// из текста и листает результаты через offset.
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) error {
+	// offset приходит от Telegram из нашего же next_offset, мусор считаем началом
+	offset, err := strconv.Atoi(query.Offset)
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	page, err := b.search(ctx, parseSearchQuery(query.Query), offset)
+	if err != nil {
+		return err
+	}
+
+	t := b.texts(query.From)
+	results := make([]interface{}, 0, len(page.sneakers))
+	for _, s := range page.sneakers {
+		results = append(results, b.inlineResult(t, s))
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     int(b.settings.InlineCacheTime.Seconds()),
+		// Подписи карточек на языке пользователя
+		IsPersonal: true,
+	}
+	if page.hasNext {
+		answer.NextOffset = strconv.Itoa(offset + len(page.sneakers))
+	}
+	if len(results) == 0 && offset == 0 {
+		answer.SwitchPMText = t.InlineNothingFound
+		answer.SwitchPMParameter = catalogPrefix
+	}
+	b.send(answer)
+	return nil
+}
+
+// search запрашивает страницу поиска, повторные запросы берутся из кэша:
+// Telegram шлёт inline-запрос на каждое нажатие клавиши.
+func (b *Bot) search(ctx context.Context, q searchQuery, offset int) (searchPage, error) {
+	key := q.cacheKey(offset)
+	if page, ok := b.searchCache.Get(key); ok {
+		return page, nil
+	}
+
+	// Лишний товар сверх страницы показывает, есть ли следующая
+	resp, err := b.inventory.GetSneakers(ctx, q.request(offset, inlinePageSize+1))
+	if err != nil {
+		return searchPage{}, fmt.Errorf("search sneakers: %w", err)
+	}
+
+	var page searchPage
+	switch resp.GetStatusCode() {
+	case http.StatusOK:
+		page.sneakers = resp.GetSneakers()
+		if len(page.sneakers) > inlinePageSize {
+			page.sneakers, page.hasNext = page.sneakers[:inlinePageSize], true
+		}
+	case http.StatusBadRequest:
+		// Фильтр, который inventory не принял, ничего и не находит
+	default:
+		return searchPage{}, fmt.Errorf("search sneakers: status %d: %s", resp.GetStatusCode(), resp.GetErrorMessage())
+	}
+
+	b.searchCache.Set(key, page)
+	return page, nil
+}
+
+// inlineResult - фото, если оно есть у товара, иначе текстовая карточка.
+func (b *Bot) inlineResult(t *messages, s *pb.Sneaker) interface{} {
+	id := strconv.Itoa(int(s.GetSneakerId()))
+	card := b.renderCard(t, s)
+	description := inlineDescription(t, s)
+
+	picture := s.GetPicture()
+	switch {
+	case strings.HasPrefix(picture, "https://") || strings.HasPrefix(picture, "http://"):
+		photo := tgbotapi.NewInlineQueryResultPhotoWithThumb(id, picture, picture)
+		photo.Title = s.GetSneakerName()
+		photo.Description = description
+		photo.Caption = card
+		photo.ParseMode = tgbotapi.ModeHTML
+		return photo
+	case picture != "":
+		// Фото загружено администратором через бота, это file_id Telegram
+		photo := tgbotapi.NewInlineQueryResultCachedPhoto(id, picture)
+		photo.Title = s.GetSneakerName()
+		photo.Description = description
+		photo.Caption = card
+		photo.ParseMode = tgbotapi.ModeHTML
+		return photo
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticleHTML(id, s.GetSneakerName(), card)
+	article.Description = description
+	return article
+}
+
+// inlineDescription - строка под названием в списке результатов: бренд, цена, размер.
+func inlineDescription(t *messages, s *pb.Sneaker) string {
+	price := s.GetEffectivePrice()
+	if price == nil {
+		price = s.GetPrice()
+	}
+
+	parts := []string{s.GetBrand(), formatMoney(price)}
+	if s.GetSize() > 0 {
+		parts = append(parts, formatSize(t, s.GetSize(), s.GetSizeSystem()))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// cacheKey - ключ кэша поиска; одинаковые по смыслу запросы дают один ключ.
+func (q searchQuery) cacheKey(offset int) string {
+	money := func(m *pb.Money) string {
+		if m == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d.%09d %s", m.GetUnits(), m.GetNanos(), m.GetCurrencyCode())
+	}
+	return fmt.Sprintf("%s|%s|%g %s|%s|%s|%d",
+		q.Brand, q.Name, q.Size, q.SizeSystem, money(q.MinPrice), money(q.MaxPrice), offset)
+}
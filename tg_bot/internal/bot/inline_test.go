@@ -0,0 +1,170 @@
+package bot_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type inlineResult struct {
+	Type        string `json:"type"`
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PhotoURL    string `json:"photo_url"`
+	ThumbURL    string `json:"thumb_url"`
+	PhotoFileID string `json:"photo_file_id"`
+	Caption     string `json:"caption"`
+}
+
+func inlineResults(t *testing.T, call telegramtest.Call) []inlineResult {
+	t.Helper()
+	var results []inlineResult
+	require.NoError(t, json.Unmarshal([]byte(call.Params.Get("results")), &results))
+	return results
+}
+
+// Бренд, размер и цена из текста запроса уходят в inventory фильтрами, остальное - в название.
+func TestInline_QueryHints(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *pb.GetSneakersRequest
+	}{
+		{"air max 42", &pb.GetSneakersRequest{Name: "air max", Size: 42, SizeSystem: "EU"}},
+		{"Nike Air Max 90", &pb.GetSneakersRequest{Brand: "Nike", Name: "air max 90"}},
+		{"new balance 9.5 us до 15000", &pb.GetSneakersRequest{
+			Brand: "New Balance", Size: 9.5, SizeSystem: "US_M",
+			MaxPrice: &pb.Money{CurrencyCode: "RUB", Units: 15000},
+		}},
+		{"adidas samba 10 us w от 100 $", &pb.GetSneakersRequest{
+			Brand: "Adidas", Name: "samba", Size: 10, SizeSystem: "US_W",
+			MinPrice: &pb.Money{CurrencyCode: "USD", Units: 100},
+		}},
+		{"air force 1 42eu 10000-12999,99", &pb.GetSneakersRequest{
+			Name: "air force 1", Size: 42, SizeSystem: "EU",
+			MinPrice: &pb.Money{CurrencyCode: "RUB", Units: 10000},
+			MaxPrice: &pb.Money{CurrencyCode: "RUB", Units: 12999, Nanos: 990_000_000},
+		}},
+		{"gel-kayano <20000", &pb.GetSneakersRequest{
+			Name:     "gel-kayano",
+			MaxPrice: &pb.Money{CurrencyCode: "RUB", Units: 20000},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			// --- Arrange ---
+			require := require.New(t)
+			inventory := &fakeInventory{}
+			server := telegramtest.NewServer(t)
+			b := bot.New(server.BotAPI(t), inventory, zap.NewNop(), bot.Settings{})
+
+			// --- Act ---
+			b.HandleUpdate(context.Background(), telegramtest.InlineQuery(1, tt.query, ""))
+
+			// --- Assert ---
+			require.Len(inventory.searches, 1)
+			got := inventory.searches[0]
+			require.Equal(tt.want.GetBrand(), got.GetBrand())
+			require.Equal(tt.want.GetName(), got.GetName())
+			require.Equal(tt.want.GetSize(), got.GetSize())
+			require.Equal(tt.want.GetSizeSystem(), got.GetSizeSystem())
+			require.Equal(tt.want.GetMinPrice(), got.GetMinPrice())
+			require.Equal(tt.want.GetMaxPrice(), got.GetMaxPrice())
+		})
+	}
+}
+
+// Результаты листаются через next_offset, повтор запроса берётся из кэша.
+func TestInline_PagingAndCache(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	var sneakers []*pb.Sneaker
+	for i := 1; i <= 25; i++ {
+		sneakers = append(sneakers, &pb.Sneaker{
+			SneakerId: int32(i), Article: fmt.Sprintf("ART-%03d", i), SneakerName: fmt.Sprintf("Air Max %d", i), Brand: "Nike",
+			Price: &pb.Money{CurrencyCode: "RUB", Units: 9990}, Size: 42, SizeSystem: "EU",
+		})
+	}
+	inventory := &fakeInventory{sneakers: sneakers}
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), inventory, zap.NewNop(), bot.Settings{})
+	ctx := context.Background()
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.InlineQuery(1, "nike air", ""))
+	b.HandleUpdate(ctx, telegramtest.InlineQuery(2, "Nike  AIR", ""))
+
+	// --- Assert ---
+	answers := server.Calls("answerInlineQuery")
+	require.Len(answers, 2)
+	require.Len(inventory.searches, 1)
+	first := inlineResults(t, answers[0])
+	require.Len(first, 20)
+	require.Equal("20", answers[0].Params.Get("next_offset"))
+	require.Equal("60", answers[0].Params.Get("cache_time"))
+	require.Equal("article", first[0].Type)
+	require.Equal("1", first[0].ID)
+	require.Equal("Air Max 1", first[0].Title)
+	require.Equal("Nike · 9990.00 ₽ · 42 EU", first[0].Description)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.InlineQuery(1, "nike air", "20"))
+
+	// --- Assert ---
+	answers = server.Calls("answerInlineQuery")
+	last := answers[len(answers)-1]
+	require.Len(inlineResults(t, last), 5)
+	require.Empty(last.Params.Get("next_offset"))
+	require.Equal(int32(20), inventory.searches[1].GetOffset())
+}
+
+// Фото товара показывается результатом-фото: по ссылке с миниатюрой или по file_id.
+func TestInline_Photos(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	sneakers := testSneakers()
+	sneakers[0].Picture = "https://cdn.example.com/art-001.jpg"
+	sneakers[1].Picture = "AgACAgIAAxkBAAI"
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: sneakers}, zap.NewNop(), bot.Settings{})
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.InlineQuery(1, "", ""))
+
+	// --- Assert ---
+	results := inlineResults(t, server.Calls("answerInlineQuery")[0])
+	require.Len(results, 2)
+	require.Equal("photo", results[0].Type)
+	require.Equal("https://cdn.example.com/art-001.jpg", results[0].PhotoURL)
+	require.Equal("https://cdn.example.com/art-001.jpg", results[0].ThumbURL)
+	require.Contains(results[0].Caption, "<b>Air Max 90</b>")
+	require.Equal("photo", results[1].Type)
+	require.Equal("AgACAgIAAxkBAAI", results[1].PhotoFileID)
+}
+
+// Если ничего не нашлось, Telegram предлагает открыть каталог в личке с ботом.
+func TestInline_NothingFound(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	server := telegramtest.NewServer(t)
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: testSneakers()}, zap.NewNop(), bot.Settings{})
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.InlineQuery(1, "puma", ""))
+	b.HandleUpdate(context.Background(), telegramtest.Message(1, "/start catalog"))
+
+	// --- Assert ---
+	answer := server.Calls("answerInlineQuery")[0]
+	require.Empty(inlineResults(t, answer))
+	require.Equal("Ничего не нашлось, открыть каталог", answer.Params.Get("switch_pm_text"))
+	require.Equal("catalog", answer.Params.Get("switch_pm_parameter"))
+	require.Contains(lastText(t, server, "sendMessage"), "Air Max 90")
+}
@@ -0,0 +1,169 @@
+package bot_test
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/inventory"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeInventory - inventory в памяти; изменяющие запросы запоминаются для проверок.
+type fakeInventory struct {
+	mu       sync.Mutex
+	sneakers []*pb.Sneaker
+	deleted  []*pb.Sneaker
+
+	// reject - ответ на изменяющие запросы вместо успешного
+	reject *pb.Response
+	// onSearch вызывается на каждый GetSneakers до выборки, ошибка возвращается вместо ответа
+	onSearch func(in *pb.GetSneakersRequest) error
+
+	searches []*pb.GetSneakersRequest
+	created  []*pb.CreateSneakersRequest
+	updated  []*pb.UpdateSneakersRequest
+	// actors - пользователь из inventory.WithActor у каждого UpdateSneakers
+	actors   []string
+	removed  []*pb.DeleteSneakersRequest
+	restored []*pb.RestoreSneakersRequest
+}
+
+func (f *fakeInventory) GetSneakers(ctx context.Context, in *pb.GetSneakersRequest, opts ...grpc.CallOption) (*pb.GetSneakersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.searches = append(f.searches, in)
+	if f.onSearch != nil {
+		if err := f.onSearch(in); err != nil {
+			return nil, err
+		}
+	}
+
+	var found []*pb.Sneaker
+	for _, s := range f.sneakers {
+		if in.GetBrand() != "" && !strings.EqualFold(in.GetBrand(), s.GetBrand()) {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(s.GetSneakerName()), strings.ToLower(in.GetName())) {
+			continue
+		}
+		if len(in.GetSneakerId()) > 0 && !slices.Contains(in.GetSneakerId(), s.GetSneakerId()) {
+			continue
+		}
+		if len(in.GetArticles()) > 0 && !slices.Contains(in.GetArticles(), s.GetArticle()) {
+			continue
+		}
+		if in.GetUpdatedSince() != "" && !changedAfter(s, in.GetUpdatedSince(), in.GetAfterId()) {
+			continue
+		}
+		found = append(found, proto.Clone(s).(*pb.Sneaker))
+	}
+	if in.GetUpdatedSince() != "" {
+		slices.SortStableFunc(found, func(a, b *pb.Sneaker) int {
+			return cmp.Or(updatedAt(a).Compare(updatedAt(b)), cmp.Compare(a.GetSneakerId(), b.GetSneakerId()))
+		})
+	}
+
+	from := min(int(in.GetOffset()), len(found))
+	to := min(from+int(in.GetPartition()), len(found))
+	return &pb.GetSneakersResponse{StatusCode: http.StatusOK, Sneakers: found[from:to]}, nil
+}
+
+func (f *fakeInventory) CreateSneakers(ctx context.Context, in *pb.CreateSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, in)
+	if f.reject != nil {
+		return f.reject, nil
+	}
+
+	resp := &pb.Response{Status: pb.Response_SUCCESS, StatusCode: http.StatusCreated}
+	for _, s := range in.GetSneakers() {
+		s = proto.Clone(s).(*pb.Sneaker)
+		s.SneakerId = int32(len(f.sneakers) + len(f.deleted) + 1)
+		f.sneakers = append(f.sneakers, s)
+		resp.SneakerIds = append(resp.SneakerIds, s.GetSneakerId())
+	}
+	return resp, nil
+}
+
+func (f *fakeInventory) UpdateSneakers(ctx context.Context, in *pb.UpdateSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updated = append(f.updated, in)
+	f.actors = append(f.actors, inventory.Actor(ctx))
+	if f.reject != nil {
+		return f.reject, nil
+	}
+
+	for _, s := range in.GetSneakers() {
+		for i, old := range f.sneakers {
+			if old.GetArticle() == s.GetArticle() {
+				f.sneakers[i] = proto.Clone(s).(*pb.Sneaker)
+			}
+		}
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS, StatusCode: http.StatusOK}, nil
+}
+
+func (f *fakeInventory) DeleteSneakers(ctx context.Context, in *pb.DeleteSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.removed = append(f.removed, in)
+	if f.reject != nil {
+		return f.reject, nil
+	}
+
+	f.sneakers = slices.DeleteFunc(f.sneakers, func(s *pb.Sneaker) bool {
+		if slices.Contains(in.GetSneakerIds(), s.GetSneakerId()) {
+			f.deleted = append(f.deleted, s)
+			return true
+		}
+		return false
+	})
+	return &pb.Response{Status: pb.Response_SUCCESS, StatusCode: http.StatusOK}, nil
+}
+
+func (f *fakeInventory) RestoreSneakers(ctx context.Context, in *pb.RestoreSneakersRequest, opts ...grpc.CallOption) (*pb.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.restored = append(f.restored, in)
+	if f.reject != nil {
+		return f.reject, nil
+	}
+
+	before := len(f.deleted)
+	f.deleted = slices.DeleteFunc(f.deleted, func(s *pb.Sneaker) bool {
+		if slices.Contains(in.GetArticles(), s.GetArticle()) {
+			f.sneakers = append(f.sneakers, s)
+			return true
+		}
+		return false
+	})
+	if len(f.deleted) == before {
+		return &pb.Response{Status: pb.Response_VALIDATION_ERROR, StatusCode: http.StatusNotFound, ErrorMessage: "sneakers not found"}, nil
+	}
+	return &pb.Response{Status: pb.Response_SUCCESS, StatusCode: http.StatusOK}, nil
+}
+
+func updatedAt(s *pb.Sneaker) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s.GetUpdatedAt())
+	return t
+}
+
+// changedAfter повторяет условие inventory_service: updated_at >= since,
+// а с afterID - (updated_at, id) > (since, afterID).
+func changedAfter(s *pb.Sneaker, since string, afterID int32) bool {
+	sinceTime, _ := time.Parse(time.RFC3339Nano, since)
+	c := updatedAt(s).Compare(sinceTime)
+	if afterID == 0 {
+		return c >= 0
+	}
+	return c > 0 || (c == 0 && s.GetSneakerId() > afterID)
+}
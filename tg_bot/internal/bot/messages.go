@@ -0,0 +1,406 @@
+package bot
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// messages - тексты бота на одном языке
+type messages struct {
+	Start          string
+	UnknownCommand string
+	InternalError  string
+	CatalogEmpty   string
+	CatalogEnd     string
+
+	InlineNothingFound string
+
+	BtnPrev string
+	BtnNext string
+
+	CardBrand   string
+	CardPrice   string
+	CardSize    string
+	CardArticle string
+	PriceUntil  string
+	SizeCM      string
+
+	// Команды администратора
+	AdminOnly       string
+	Cancelled       string
+	NothingToCancel string
+	UsageEdit       string
+	UsagePrice      string
+	UsageDelete     string
+	UsageRestore    string
+	NotFound        string
+	Rejected        string
+	DialogExpired   string
+	DialogConflict  string
+
+	AskArticle     string
+	AskName        string
+	AskBrand       string
+	AskDescription string
+	AskPrice       string
+	AskSize        string
+	AskPhoto       string
+	EmptyValue     string
+	ValueTooLong   string
+	BadPrice       string
+	BadSize        string
+
+	Created       string
+	Updated       string
+	EditChoose    string
+	ConfirmDelete string
+	Deleted       string
+	Restored      string
+
+	BtnDelete string
+	BtnCancel string
+
+	FieldName        string
+	FieldBrand       string
+	FieldDescription string
+	FieldPrice       string
+	FieldSize        string
+	FieldPhoto       string
+
+	// Подписки
+	UsageSubscribe       string
+	Subscribed           string
+	SubscribedSize       string
+	SubscribedPrice      string
+	SubscribedBelow      string
+	AlreadySubscribed    string
+	ThresholdChanged     string
+	AlreadyAvailable     string
+	AlreadyCheaper       string
+	TooManySubscriptions string
+	SubscriptionsEmpty   string
+	SubscriptionsTitle   string
+	SubscriptionStock    string
+	SubscriptionPrice    string
+	SubscriptionBelow    string
+	UsageUnsubscribe     string
+	Unsubscribed         string
+	SubscriptionNotFound string
+	NotifyBackInStock    string
+	NotifyPriceDrop      string
+
+	// Корзина и оформление заказа
+	BtnAddToCart         string
+	CartAdded            string
+	CartUnavailableNow   string
+	CartLimit            string
+	CartEmpty            string
+	CartTitle            string
+	CartTotal            string
+	CartUnavailable      string
+	CartPriceUp          string
+	CartCheckAgain       string
+	CartMixedCurrencies  string
+	BtnCheckout          string
+	BtnClearCart         string
+	AskCustomerName      string
+	AskPhone             string
+	BtnSharePhone        string
+	BadPhone             string
+	AskAddress           string
+	CheckoutSummary      string
+	CheckoutCustomer     string
+	CheckoutConfirmFirst string
+	BtnConfirmOrder      string
+	OrderPlaced          string
+	OrderConflict        string
+	AdminNewOrder        string
+
+	// Оплата в Telegram Payments
+	OrderAwaitingPayment string
+	InvoiceTitle         string
+	InvoiceDescription   string
+	InvoiceFailed        string
+	PaymentUnavailable   string
+	PaymentAmountChanged string
+	PaymentDeclined      string
+	PaymentReceived      string
+	PaymentFailed        string
+	PaymentRefunded      string
+	PaymentRefundPending string
+	AdminOrderPaid       string
+	AdminRefundFailed    string
+}
+
+var locales = map[string]*messages{
+	"ru": {
+		Start:          "Привет! Я помогу выбрать кроссовки.\n/catalog - каталог\n/cart - корзина\n/subscribe - узнать о поступлении или снижении цены",
+		UnknownCommand: "Неизвестная команда. /catalog - каталог",
+		InternalError:  "Что-то пошло не так, попробуйте позже",
+		CatalogEmpty:   "Каталог пока пуст",
+		CatalogEnd:     "Дальше товаров нет",
+
+		InlineNothingFound: "Ничего не нашлось, открыть каталог",
+
+		BtnPrev: "◀ Назад",
+		BtnNext: "Вперёд ▶",
+
+		CardBrand:   "Бренд",
+		CardPrice:   "Цена",
+		CardSize:    "Размер",
+		CardArticle: "Артикул",
+		PriceUntil:  "до",
+		SizeCM:      "см",
+
+		AdminOnly:       "Команда доступна только администраторам",
+		Cancelled:       "Отменено",
+		NothingToCancel: "Нечего отменять",
+		UsageEdit:       "Использование: /edit <артикул>",
+		UsagePrice:      "Использование: /price <артикул> <цена>, например /price ART-001 12990",
+		UsageDelete:     "Использование: /delete <артикул>",
+		UsageRestore:    "Использование: /restore <артикул>",
+		NotFound:        "Товар %s не найден",
+		Rejected:        "Inventory отклонил запрос: %s",
+		DialogExpired:   "Время на ответ вышло, начните заново",
+		DialogConflict:  "Не успел обработать ответ, отправьте его ещё раз",
+
+		AskArticle:     "Артикул товара:",
+		AskName:        "Название модели:",
+		AskBrand:       "Бренд:",
+		AskDescription: "Описание:",
+		AskPrice:       "Цена, например 12990 или 149.99 USD:",
+		AskSize:        "Размер с системой: 42.5 EU, 9 US_M, 10 US_W, 8 UK или 27 CM:",
+		AskPhoto:       "Пришлите фото товара или /skip",
+		EmptyValue:     "Значение не может быть пустым",
+		ValueTooLong:   "Слишком длинно, сократите, пожалуйста",
+		BadPrice:       "Не понял цену. Пример: 12990 или 149.99 USD",
+		BadSize:        "Укажите размер и систему (EU, US_M, US_W, UK, CM), например 42.5 EU",
+
+		Created:       "Товар добавлен, ID %d",
+		Updated:       "Изменения сохранены",
+		EditChoose:    "Что изменить?",
+		ConfirmDelete: "Удалить этот товар?",
+		Deleted:       "Товар %s удалён. Вернуть: /restore %s",
+		Restored:      "Товар %s восстановлен",
+
+		BtnDelete: "Удалить",
+		BtnCancel: "Отмена",
+
+		FieldName:        "Название",
+		FieldBrand:       "Бренд",
+		FieldDescription: "Описание",
+		FieldPrice:       "Цена",
+		FieldSize:        "Размер",
+		FieldPhoto:       "Фото",
+
+		UsageSubscribe: "Использование:\n" +
+			"/subscribe <артикул> - сообщить, когда товар появится\n" +
+			"/subscribe <артикул> 42.5 EU - когда появится в этом размере\n" +
+			"/subscribe <артикул> цена [порог] - когда цена снизится, при необходимости ниже порога",
+		Subscribed:           "Сообщу, когда %s появится в наличии. Подписки: /subscriptions",
+		SubscribedSize:       "Сообщу, когда %s появится в размере %s. Подписки: /subscriptions",
+		SubscribedPrice:      "Сообщу, когда цена %s станет ниже %s. Подписки: /subscriptions",
+		SubscribedBelow:      "Сообщу, когда цена %s опустится до %s. Подписки: /subscriptions",
+		AlreadySubscribed:    "Такая подписка уже есть",
+		ThresholdChanged:     "Порог подписки изменён. %s",
+		AlreadyAvailable:     "Товар %s уже в наличии: /catalog",
+		AlreadyCheaper:       "Товар %s уже стоит %s",
+		TooManySubscriptions: "Можно подписаться не больше чем на %d товаров, лишние удалите через /unsubscribe",
+		SubscriptionsEmpty:   "Подписок нет. /subscribe - подписаться",
+		SubscriptionsTitle:   "Подписки (удалить: /unsubscribe <номер>):",
+		SubscriptionStock:    "появление в наличии",
+		SubscriptionPrice:    "снижение цены",
+		SubscriptionBelow:    "цена до %s",
+		UsageUnsubscribe:     "Использование: /unsubscribe <номер из /subscriptions>",
+		Unsubscribed:         "Подписка удалена",
+		SubscriptionNotFound: "Подписка %s не найдена",
+		NotifyBackInStock:    "Снова в наличии:",
+		NotifyPriceDrop:      "Цена снизилась: %s → %s. Отписаться: /unsubscribe %d",
+
+		BtnAddToCart:         "🛒 В корзину",
+		CartAdded:            "В корзине: %d шт. /cart - корзина",
+		CartUnavailableNow:   "Этого товара уже нет в продаже",
+		CartLimit:            "Не больше %d пар одного товара",
+		CartEmpty:            "Корзина пуста. /catalog - каталог",
+		CartTitle:            "Корзина:",
+		CartTotal:            "Итого",
+		CartUnavailable:      "%s больше нет в продаже, товар убран из корзины",
+		CartPriceUp:          "%s подорожал: %s → %s",
+		CartCheckAgain:       "Проверьте корзину и нажмите «Оформить заказ» ещё раз",
+		CartMixedCurrencies:  "В корзине товары в разных валютах, оформите их отдельными заказами",
+		BtnCheckout:          "Оформить заказ",
+		BtnClearCart:         "Очистить",
+		AskCustomerName:      "Как к вам обращаться? /cancel - отменить оформление",
+		AskPhone:             "Телефон для связи: отправьте номер или нажмите кнопку",
+		BtnSharePhone:        "📱 Отправить мой номер",
+		BadPhone:             "Не понял номер. Пример: +7 999 123-45-67",
+		AskAddress:           "Адрес доставки:",
+		CheckoutSummary:      "Проверьте заказ:",
+		CheckoutCustomer:     "Получатель: %s\nТелефон: %s\nАдрес: %s",
+		CheckoutConfirmFirst: "Подтвердите заказ кнопкой под ним или отмените: /cancel",
+		BtnConfirmOrder:      "Подтвердить",
+		OrderPlaced:          "Заказ №%d на %s оформлен. Мы позвоним по номеру %s, чтобы подтвердить доставку",
+		OrderConflict:        "Пока вы оформляли заказ, изменились цены или наличие. Нажмите «Подтвердить» ещё раз, чтобы увидеть изменения",
+		AdminNewOrder:        "Новый заказ №%d",
+
+		OrderAwaitingPayment: "Заказ №%d на %s оформлен. Оплатите его по счёту ниже",
+		InvoiceTitle:         "Заказ №%d",
+		InvoiceDescription:   "Кроссовки с доставкой по адресу: %s",
+		InvoiceFailed:        "Не удалось выставить счёт. Мы позвоним по номеру %s, чтобы договориться об оплате",
+		PaymentUnavailable:   "Этот заказ уже оплачен или отменён",
+		PaymentAmountChanged: "Сумма счёта не совпадает с заказом, оформите заказ заново",
+		PaymentDeclined:      "Платёж отклонён, деньги не списаны. Попробуйте другую карту",
+		PaymentReceived:      "Оплата заказа №%d на %s получена. Мы позвоним по номеру %s, чтобы согласовать доставку",
+		PaymentFailed:        "Платёж по заказу №%d не прошёл, деньги возвращены. Мы позвоним, чтобы договориться об оплате",
+		PaymentRefunded:      "Заказ №%d отменён, оплата возвращена",
+		PaymentRefundPending: "Оплату заказа №%d не удалось вернуть автоматически. Менеджер вернёт её и позвонит вам",
+		AdminOrderPaid:       "Заказ №%d оплачен: %s",
+		AdminRefundFailed:    "Заказ №%d: не удалось вернуть оплату %s (платёж %s), верните её вручную",
+	},
+	"en": {
+		Start:          "Hi! I'll help you pick sneakers.\n/catalog - catalog\n/cart - cart\n/subscribe - get notified about restocks and price drops",
+		UnknownCommand: "Unknown command. /catalog - catalog",
+		InternalError:  "Something went wrong, please try again later",
+		CatalogEmpty:   "The catalog is empty for now",
+		CatalogEnd:     "No more items",
+
+		InlineNothingFound: "Nothing found, open the catalog",
+
+		BtnPrev: "◀ Back",
+		BtnNext: "Next ▶",
+
+		CardBrand:   "Brand",
+		CardPrice:   "Price",
+		CardSize:    "Size",
+		CardArticle: "Article",
+		PriceUntil:  "until",
+		SizeCM:      "cm",
+
+		AdminOnly:       "This command is for administrators only",
+		Cancelled:       "Cancelled",
+		NothingToCancel: "Nothing to cancel",
+		UsageEdit:       "Usage: /edit <article>",
+		UsagePrice:      "Usage: /price <article> <price>, e.g. /price ART-001 12990",
+		UsageDelete:     "Usage: /delete <article>",
+		UsageRestore:    "Usage: /restore <article>",
+		NotFound:        "Item %s not found",
+		Rejected:        "Inventory rejected the request: %s",
+		DialogExpired:   "Time to answer is up, please start over",
+		DialogConflict:  "Could not process the answer, please send it again",
+
+		AskArticle:     "Article:",
+		AskName:        "Model name:",
+		AskBrand:       "Brand:",
+		AskDescription: "Description:",
+		AskPrice:       "Price, e.g. 12990 or 149.99 USD:",
+		AskSize:        "Size with a system: 42.5 EU, 9 US_M, 10 US_W, 8 UK or 27 CM:",
+		AskPhoto:       "Send a photo of the item or /skip",
+		EmptyValue:     "The value must not be empty",
+		ValueTooLong:   "Too long, please shorten it",
+		BadPrice:       "Could not read the price. Example: 12990 or 149.99 USD",
+		BadSize:        "Give a size and a system (EU, US_M, US_W, UK, CM), e.g. 42.5 EU",
+
+		Created:       "Item added, ID %d",
+		Updated:       "Changes saved",
+		EditChoose:    "What do you want to change?",
+		ConfirmDelete: "Delete this item?",
+		Deleted:       "Item %s deleted. Undo: /restore %s",
+		Restored:      "Item %s restored",
+
+		BtnDelete: "Delete",
+		BtnCancel: "Cancel",
+
+		FieldName:        "Name",
+		FieldBrand:       "Brand",
+		FieldDescription: "Description",
+		FieldPrice:       "Price",
+		FieldSize:        "Size",
+		FieldPhoto:       "Photo",
+
+		UsageSubscribe: "Usage:\n" +
+			"/subscribe <article> - notify when the item is back in stock\n" +
+			"/subscribe <article> 42.5 EU - when it is back in this size\n" +
+			"/subscribe <article> price [threshold] - when the price drops, optionally below a threshold",
+		Subscribed:           "I'll let you know when %s is back in stock. Subscriptions: /subscriptions",
+		SubscribedSize:       "I'll let you know when %s is back in size %s. Subscriptions: /subscriptions",
+		SubscribedPrice:      "I'll let you know when %s gets cheaper than %s. Subscriptions: /subscriptions",
+		SubscribedBelow:      "I'll let you know when %s drops to %s. Subscriptions: /subscriptions",
+		AlreadySubscribed:    "You are already subscribed",
+		ThresholdChanged:     "Threshold updated. %s",
+		AlreadyAvailable:     "%s is already in stock: /catalog",
+		AlreadyCheaper:       "%s already costs %s",
+		TooManySubscriptions: "You can follow at most %d items, remove some with /unsubscribe",
+		SubscriptionsEmpty:   "No subscriptions. /subscribe - subscribe",
+		SubscriptionsTitle:   "Subscriptions (remove: /unsubscribe <number>):",
+		SubscriptionStock:    "back in stock",
+		SubscriptionPrice:    "price drop",
+		SubscriptionBelow:    "price down to %s",
+		UsageUnsubscribe:     "Usage: /unsubscribe <number from /subscriptions>",
+		Unsubscribed:         "Subscription removed",
+		SubscriptionNotFound: "Subscription %s not found",
+		NotifyBackInStock:    "Back in stock:",
+		NotifyPriceDrop:      "Price dropped: %s → %s. Unsubscribe: /unsubscribe %d",
+
+		BtnAddToCart:         "🛒 Add to cart",
+		CartAdded:            "In your cart: %d. /cart - cart",
+		CartUnavailableNow:   "This item is no longer available",
+		CartLimit:            "At most %d pairs of one item",
+		CartEmpty:            "Your cart is empty. /catalog - catalog",
+		CartTitle:            "Cart:",
+		CartTotal:            "Total",
+		CartUnavailable:      "%s is no longer available and was removed from the cart",
+		CartPriceUp:          "%s got more expensive: %s → %s",
+		CartCheckAgain:       "Please review the cart and press \"Check out\" again",
+		CartMixedCurrencies:  "The cart has items in different currencies, please order them separately",
+		BtnCheckout:          "Check out",
+		BtnClearCart:         "Clear",
+		AskCustomerName:      "What is your name? /cancel - stop checkout",
+		AskPhone:             "Contact phone: type the number or press the button",
+		BtnSharePhone:        "📱 Share my number",
+		BadPhone:             "Could not read the number. Example: +1 202 555-01-23",
+		AskAddress:           "Delivery address:",
+		CheckoutSummary:      "Please check your order:",
+		CheckoutCustomer:     "Recipient: %s\nPhone: %s\nAddress: %s",
+		CheckoutConfirmFirst: "Confirm the order with the button below it or cancel: /cancel",
+		BtnConfirmOrder:      "Confirm",
+		OrderPlaced:          "Order #%d for %s is placed. We will call %s to confirm delivery",
+		OrderConflict:        "Prices or availability changed while you were checking out. Press \"Confirm\" again to see what changed",
+		AdminNewOrder:        "New order #%d",
+
+		OrderAwaitingPayment: "Order #%d for %s is placed. Please pay the invoice below",
+		InvoiceTitle:         "Order #%d",
+		InvoiceDescription:   "Sneakers delivered to: %s",
+		InvoiceFailed:        "Could not issue the invoice. We will call %s to arrange payment",
+		PaymentUnavailable:   "This order is already paid or cancelled",
+		PaymentAmountChanged: "The invoice does not match the order, please check out again",
+		PaymentDeclined:      "The payment was declined, you were not charged. Try another card",
+		PaymentReceived:      "Payment for order #%d of %s is received. We will call %s to arrange delivery",
+		PaymentFailed:        "Payment for order #%d did not go through and has been refunded. We will call you to arrange payment",
+		PaymentRefunded:      "Order #%d was cancelled, the payment has been refunded",
+		PaymentRefundPending: "The payment for order #%d could not be refunded automatically. A manager will refund it and call you",
+		AdminOrderPaid:       "Order #%d is paid: %s",
+		AdminRefundFailed:    "Order #%d: could not refund %s (charge %s), please refund it manually",
+	},
+}
+
+// texts - сообщения на языке пользователя или на языке бота по умолчанию.
+// Telegram передаёт язык как IETF-тег (en-US), учитывается только основной язык.
+func (b *Bot) texts(user *tgbotapi.User) *messages {
+	if user == nil {
+		return b.textsFor("")
+	}
+	return b.textsFor(user.LanguageCode)
+}
+
+// textsFor - сообщения на языке language, например запомненном в подписке.
+func (b *Bot) textsFor(language string) *messages {
+	lang, _, _ := strings.Cut(strings.ToLower(language), "-")
+	if t, ok := locales[lang]; ok {
+		return t
+	}
+	if t, ok := locales[b.settings.Language]; ok {
+		return t
+	}
+	return locales[defaultLanguage]
+}
@@ -0,0 +1,263 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+	"go.uber.org/zap"
+)
+
+// notifyPageSize - сколько изменённых товаров читается из inventory за запрос
+const notifyPageSize = 100
+
+// changeOverlap - насколько раньше отметки перечитываются изменения. Транзакция,
+// начатая до отметки, может закоммититься после неё; повторы отсекают Claim*.
+const changeOverlap = 5 * time.Second
+
+// RunNotifier раз в interval проверяет изменения каталога и рассылает уведомления
+// по подпискам, пока не отменён ctx.
+func (b *Bot) RunNotifier(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.pollAsLeader(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollAsLeader проверяет изменения, только если рассылку сейчас не ведёт другая
+// реплика: Throttle ограничивает темп в одном процессе, и две рассылки разом
+// превысили бы лимит Telegram на бота.
+func (b *Bot) pollAsLeader(ctx context.Context) {
+	unlock, ok, err := b.subscriptions.LockNotifier(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			b.log.Error("ERROR: lock notifier", zap.Error(err))
+		}
+		return
+	}
+	if !ok {
+		b.log.Debug("notifier is busy on another replica")
+		return
+	}
+	defer unlock()
+
+	if err := b.PollChanges(ctx); err != nil && ctx.Err() == nil {
+		b.log.Error("ERROR: poll inventory changes", zap.Error(err))
+	}
+}
+
+// PollChanges читает товары, изменённые с прошлой проверки, и уведомляет подписчиков.
+// Появление товара - это восстановление или создание строки, изменение цены тоже
+// обновляет updated_at, как и начало и конец плановой скидки: воркер цен пишет её
+// в строку товара. Страницы читаются по ключу (updated_at, id), поэтому строка,
+// изменённая во время обхода, уходит в конец выборки и не сдвигает остальные.
+func (b *Bot) PollChanges(ctx context.Context) error {
+	since, err := b.subscriptions.Watermark(ctx)
+	if err != nil {
+		return fmt.Errorf("load watermark: %w", err)
+	}
+	if since.IsZero() {
+		// Первый запуск: об изменениях до появления подписок не уведомляем
+		return b.subscriptions.SetWatermark(ctx, time.Now())
+	}
+	if err := b.sendDeferred(ctx); err != nil {
+		return err
+	}
+
+	after, afterID := since.Add(-changeOverlap), int32(0)
+	for {
+		resp, err := b.inventory.GetSneakers(ctx, &pb.GetSneakersRequest{
+			UpdatedSince: after.Format(time.RFC3339Nano),
+			AfterId:      afterID,
+			Partition:    notifyPageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("get changed sneakers: %w", err)
+		}
+		if resp.GetStatusCode() != http.StatusOK {
+			return fmt.Errorf("get changed sneakers: %d %s", resp.GetStatusCode(), resp.GetErrorMessage())
+		}
+
+		sneakers := resp.GetSneakers()
+		if err := b.notifyChanges(ctx, sneakers); err != nil {
+			return err
+		}
+		if len(sneakers) == 0 {
+			return nil
+		}
+
+		// Отметка сдвигается только до последней разобранной строки: если следующая
+		// страница не прочитается, проверка продолжится с этого места
+		last := sneakers[len(sneakers)-1]
+		if after, err = time.Parse(time.RFC3339Nano, last.GetUpdatedAt()); err != nil {
+			return fmt.Errorf("parse updated_at of sneaker %d: %w", last.GetSneakerId(), err)
+		}
+		afterID = last.GetSneakerId()
+		if err := b.subscriptions.SetWatermark(ctx, after); err != nil {
+			return fmt.Errorf("save watermark: %w", err)
+		}
+		if len(sneakers) < notifyPageSize {
+			return nil
+		}
+	}
+}
+
+func (b *Bot) notifyChanges(ctx context.Context, sneakers []*pb.Sneaker) error {
+	if len(sneakers) == 0 {
+		return nil
+	}
+	articles := make([]string, 0, len(sneakers))
+	for _, s := range sneakers {
+		articles = append(articles, s.GetArticle())
+	}
+	subs, err := b.subscriptions.ListByArticles(ctx, articles)
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+
+	byArticle := make(map[string][]subscriptions.Subscription)
+	for _, sub := range subs {
+		byArticle[sub.Article] = append(byArticle[sub.Article], sub)
+	}
+	for _, s := range sneakers {
+		for _, sub := range byArticle[s.GetArticle()] {
+			if err := b.notify(ctx, sub, s); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				// Одно неотправленное уведомление не останавливает рассылку
+				b.log.Error("ERROR: notify subscriber", zap.Int64("chat_id", sub.ChatID), zap.Int64("subscription_id", sub.ID), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
+
+// notice - уведомление по занятой подписке.
+type notice struct {
+	sub subscriptions.Subscription
+	msg tgbotapi.MessageConfig
+}
+
+// notify проверяет, сработала ли подписка на изменённый товар, и отправляет уведомление.
+// Подписка занимается через Claim* до отправки, чтобы реплики не отправили его дважды.
+func (b *Bot) notify(ctx context.Context, sub subscriptions.Subscription, s *pb.Sneaker) error {
+	t := b.textsFor(sub.Language)
+	var text string
+
+	switch sub.Kind {
+	case subscriptions.KindStock:
+		if !sizeMatches(sub, s) {
+			return nil
+		}
+		claimed, err := b.subscriptions.ClaimStock(ctx, sub.ID)
+		if err != nil || !claimed {
+			return err
+		}
+		text = t.NotifyBackInStock
+
+	case subscriptions.KindPrice:
+		price := currentPrice(s)
+		if price.GetCurrencyCode() != sub.Currency {
+			return nil
+		}
+		amount := moneyAmount(price)
+		if amount.GreaterThan(sub.LastPrice) {
+			return b.subscriptions.RaisePrice(ctx, sub.ID, amount)
+		}
+		if !amount.LessThan(sub.LastPrice) || (sub.Threshold != nil && amount.GreaterThan(*sub.Threshold)) {
+			return nil
+		}
+		claimed, err := b.subscriptions.ClaimPriceDrop(ctx, sub.ID, amount)
+		if err != nil || !claimed {
+			return err
+		}
+		text = fmt.Sprintf(t.NotifyPriceDrop, formatAmount(sub.LastPrice, sub.Currency), formatMoney(price), sub.ID)
+	}
+
+	out := tgbotapi.NewMessage(sub.ChatID, text+"\n\n"+b.renderCard(t, s))
+	out.ParseMode = tgbotapi.ModeHTML
+	return b.sendNotice(ctx, notice{sub: sub, msg: out})
+}
+
+// sendNotice отправляет уведомление, если у чата осталась квота, иначе откладывает
+// его до следующей проверки. Новое снижение цены заменяет отложенное по той же подписке.
+// Отложенные уведомления хранятся в памяти процесса и теряются при его остановке.
+func (b *Bot) sendNotice(ctx context.Context, n notice) error {
+	if !b.notifyQuota.Allow(n.sub.ChatID) {
+		b.log.Warn("notification deferred: chat quota exceeded", zap.Int64("chat_id", n.sub.ChatID), zap.Int64("subscription_id", n.sub.ID))
+		b.deferredMu.Lock()
+		b.deferred[n.sub.ID] = n
+		b.deferredMu.Unlock()
+		return nil
+	}
+	return b.deliver(ctx, n.sub.ChatID, n.msg)
+}
+
+// sendDeferred отправляет отложенные уведомления чатам, у которых восстановилась квота.
+func (b *Bot) sendDeferred(ctx context.Context) error {
+	b.deferredMu.Lock()
+	pending := slices.SortedFunc(maps.Values(b.deferred), func(a, b notice) int { return cmp.Compare(a.sub.ID, b.sub.ID) })
+	b.deferredMu.Unlock()
+
+	for _, n := range pending {
+		if !b.notifyQuota.Allow(n.sub.ChatID) {
+			continue
+		}
+		b.deferredMu.Lock()
+		delete(b.deferred, n.sub.ID)
+		b.deferredMu.Unlock()
+
+		if err := b.deliver(ctx, n.sub.ChatID, n.msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			b.log.Error("ERROR: notify subscriber", zap.Int64("chat_id", n.sub.ChatID), zap.Int64("subscription_id", n.sub.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// deliver отправляет сообщение без запроса пользователя: с учётом лимитов Telegram,
+// одним повтором после 429 и удалением подписок чата, заблокировавшего бота.
+func (b *Bot) deliver(ctx context.Context, chatID int64, c tgbotapi.Chattable) error {
+	for retried := false; ; retried = true {
+		if err := b.throttle.Wait(ctx, chatID); err != nil {
+			return err
+		}
+		_, err := b.api.Request(c)
+
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) {
+			return err
+		}
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests && apiErr.RetryAfter > 0 && !retried:
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(apiErr.RetryAfter) * time.Second):
+			}
+		case apiErr.Code == http.StatusForbidden:
+			b.log.Info("bot blocked by chat, subscriptions removed", zap.Int64("chat_id", chatID))
+			return b.subscriptions.RemoveChat(ctx, chatID)
+		default:
+			return err
+		}
+	}
+}
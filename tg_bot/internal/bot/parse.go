@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+// defaultCurrency - валюта новых товаров, если администратор её не указал
+const defaultCurrency = "RUB"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// decimalCommaPattern - запятая как десятичный разделитель: "12990,5", "12990,50"
+var decimalCommaPattern = regexp.MustCompile(`^[0-9]+,[0-9]{1,2}$`)
+
+// thousandsCommaPattern - запятые между разрядами: "12,990", "1,299,000.50"
+var thousandsCommaPattern = regexp.MustCompile(`^[0-9]{1,3}(,[0-9]{3})+(\.[0-9]+)?$`)
+
+// phonePattern - номер в международном или местном формате без разделителей
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{10,15}$`)
+
+// sizeSystems - системы размеров inventory_service
+var sizeSystems = map[string]bool{"EU": true, "US_M": true, "US_W": true, "UK": true, "CM": true}
+
+var (
+	errBadPrice = errors.New("bad price")
+	errBadSize  = errors.New("bad size")
+)
+
+// parsePrice разбирает "12990", "12 990,50", "12,990" или "149.99 USD". Запятая
+// считается десятичной, только если за ней в конце одна-две цифры; запятые между
+// разрядами отбрасываются, остальные записи неоднозначны и не принимаются.
+// Точность валюты проверяет inventory_service.
+func parsePrice(text, currency string) (*pb.Money, error) {
+	fields := strings.Fields(text)
+	if len(fields) > 1 {
+		if code := strings.ToUpper(fields[len(fields)-1]); currencyPattern.MatchString(code) {
+			currency = code
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	number := strings.Join(fields, "")
+	switch {
+	case decimalCommaPattern.MatchString(number):
+		number = strings.Replace(number, ",", ".", 1)
+	case thousandsCommaPattern.MatchString(number):
+		number = strings.ReplaceAll(number, ",", "")
+	case strings.Contains(number, ","):
+		return nil, errBadPrice
+	}
+
+	amount, err := decimal.NewFromString(number)
+	if err != nil || !amount.IsPositive() {
+		return nil, errBadPrice
+	}
+
+	units := amount.IntPart()
+	nanos := amount.Sub(decimal.NewFromInt(units)).Shift(9).IntPart()
+	return &pb.Money{CurrencyCode: currency, Units: units, Nanos: int32(nanos)}, nil
+}
+
+// parseSize разбирает "42.5 EU", "9 us m" или "27,5 cm". Размер без системы
+// неоднозначен и не принимается.
+func parseSize(text string) (float32, string, error) {
+	fields := strings.Fields(strings.ReplaceAll(text, ",", "."))
+	if len(fields) < 2 {
+		return 0, "", errBadSize
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 32)
+	if err != nil || value <= 0 {
+		return 0, "", errBadSize
+	}
+
+	system := strings.ToUpper(strings.Join(fields[1:], "_"))
+	if system == "СМ" {
+		system = "CM"
+	}
+	if !sizeSystems[system] {
+		return 0, "", errBadSize
+	}
+	return float32(value), system, nil
+}
+
+// parsePhone разбирает "+7 999 123-45-67" или "8 (999) 123 45 67", разделители отбрасываются.
+func parsePhone(text string) (string, bool) {
+	phone := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(" -()", r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(text))
+	return phone, phonePattern.MatchString(phone)
+}
@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/kripst/krosovka/tg_bot/internal/payments"
+	"go.uber.org/zap"
+)
+
+// invoiceDescriptionLimit - длина описания счёта по документации sendInvoice.
+const invoiceDescriptionLimit = 255
+
+// authorizeTimeout - сколько ждать провайдера в pre_checkout_query: Telegram ждёт
+// ответа бота не дольше 10 секунд.
+const authorizeTimeout = 7 * time.Second
+
+// sendInvoice выставляет счёт на заказ и отправляет его покупателю. Номер заказа
+// и счёт провайдера уходят в payload и возвращаются в pre_checkout_query и
+// successful_payment.
+func (b *Bot) sendInvoice(ctx context.Context, chatID int64, t *messages, order orders.Order) error {
+	invoice, err := b.payments.CreateInvoice(ctx, payments.InvoiceRequest{
+		OrderID:  order.ID,
+		Amount:   order.Total,
+		Currency: order.Currency,
+	})
+	if err != nil {
+		return fmt.Errorf("create invoice: %w", err)
+	}
+
+	prices := make([]tgbotapi.LabeledPrice, 0, len(order.Lines))
+	for _, item := range orderItems(order) {
+		prices = append(prices, tgbotapi.LabeledPrice{
+			Label:  fmt.Sprintf("%s × %d", itemTitle(t, item), item.Quantity),
+			Amount: payments.MinorUnits(item.Total(), order.Currency),
+		})
+	}
+	description := []rune(fmt.Sprintf(t.InvoiceDescription, order.Customer.Address))
+	if len(description) > invoiceDescriptionLimit {
+		description = append(description[:invoiceDescriptionLimit-1], '…')
+	}
+
+	out := tgbotapi.NewInvoice(chatID, fmt.Sprintf(t.InvoiceTitle, order.ID), string(description),
+		invoicePayload(invoice), invoice.ProviderToken, "", order.Currency, prices)
+	// tgbotapi отправляет пустой список чаевых как null, а Telegram его отклоняет
+	out.SuggestedTipAmounts = []int{}
+	if _, err := b.api.Request(out); err != nil {
+		return fmt.Errorf("send invoice: %w", err)
+	}
+	return nil
+}
+
+// handlePreCheckout разрешает оплату, только если заказ ещё ждёт её, сумма не
+// изменилась и провайдер её примет. Отказ здесь - последний момент, когда деньги
+// ещё не списаны.
+func (b *Bot) handlePreCheckout(ctx context.Context, query *tgbotapi.PreCheckoutQuery) error {
+	t := b.texts(query.From)
+	orderID, invoiceID, ok := parseInvoicePayload(query.InvoicePayload)
+	if !ok || b.payments == nil {
+		b.answerPreCheckout(query.ID, t.PaymentUnavailable)
+		return nil
+	}
+
+	order, err := b.orders.Get(ctx, orderID)
+	switch {
+	case errors.Is(err, orders.ErrNotFound):
+		b.answerPreCheckout(query.ID, t.PaymentUnavailable)
+		return nil
+	case err != nil:
+		b.answerPreCheckout(query.ID, t.InternalError)
+		return fmt.Errorf("get order %d: %w", orderID, err)
+	}
+
+	switch {
+	case order.Status != orders.StatusCreated:
+		b.answerPreCheckout(query.ID, t.PaymentUnavailable)
+		return nil
+	case query.Currency != order.Currency || query.TotalAmount != payments.MinorUnits(order.Total, order.Currency):
+		b.log.Warn("pre-checkout amount mismatch", zap.Int64("order_id", orderID),
+			zap.Int("total_amount", query.TotalAmount), zap.String("currency", query.Currency))
+		b.answerPreCheckout(query.ID, t.PaymentAmountChanged)
+		return nil
+	}
+
+	authCtx, cancel := context.WithTimeout(ctx, authorizeTimeout)
+	defer cancel()
+	err = b.payments.Authorize(authCtx, payments.Checkout{InvoiceID: invoiceID, Amount: order.Total, Currency: order.Currency})
+	switch {
+	case errors.Is(err, payments.ErrDeclined):
+		b.log.Warn("payment declined", zap.Int64("order_id", orderID), zap.String("invoice_id", invoiceID), zap.Error(err))
+		b.answerPreCheckout(query.ID, t.PaymentDeclined)
+	case err != nil:
+		b.answerPreCheckout(query.ID, t.InternalError)
+		return fmt.Errorf("authorize payment for order %d: %w", orderID, err)
+	default:
+		b.answerPreCheckout(query.ID, "")
+	}
+	return nil
+}
+
+// handleSuccessfulPayment передаёт оплату провайдеру и, если он её подтвердил,
+// переводит заказ в paid. Деньги к этому моменту уже списаны, поэтому платёж,
+// не принятый провайдером, и оплата заказа, который успели отменить, возвращаются.
+func (b *Bot) handleSuccessfulPayment(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	chatID, p := msg.Chat.ID, msg.SuccessfulPayment
+
+	orderID, invoiceID, ok := parseInvoicePayload(p.InvoicePayload)
+	if !ok {
+		return fmt.Errorf("unexpected invoice payload %q", p.InvoicePayload)
+	}
+	if b.payments == nil {
+		return errors.New("payment received, but payments are disabled")
+	}
+
+	payment, err := b.payments.HandleCallback(ctx, payments.Callback{
+		InvoiceID:        invoiceID,
+		Amount:           payments.FromMinorUnits(p.TotalAmount, p.Currency),
+		Currency:         p.Currency,
+		TelegramChargeID: p.TelegramPaymentChargeID,
+		ProviderChargeID: p.ProviderPaymentChargeID,
+	})
+	if err != nil {
+		return fmt.Errorf("handle payment callback: %w", err)
+	}
+	if payment.Status != payments.StatusSucceeded {
+		b.log.Warn("payment rejected after charge", zap.Int64("order_id", orderID), zap.String("invoice_id", invoiceID),
+			zap.String("reason", payment.FailureReason))
+		b.refund(ctx, chatID, t, orderID, payment, fmt.Sprintf(t.PaymentFailed, orderID))
+		return nil
+	}
+
+	order, err := b.orders.Transition(ctx, orderID, orders.StatusCreated, orders.StatusPaid, "payment "+payment.ChargeID)
+	if errors.Is(err, orders.ErrConflict) {
+		return b.paymentConflict(ctx, chatID, t, orderID, payment)
+	}
+	if err != nil {
+		return fmt.Errorf("mark order %d paid: %w", orderID, err)
+	}
+
+	b.reply(chatID, fmt.Sprintf(t.PaymentReceived, order.ID, formatAmount(order.Total, order.Currency), order.Customer.Phone))
+	admin := b.textsFor("")
+	for _, id := range b.settings.Admins {
+		b.reply(id, fmt.Sprintf(admin.AdminOrderPaid, order.ID, formatAmount(payment.Amount, payment.Currency)))
+	}
+	return nil
+}
+
+// paymentConflict разбирает оплату заказа, который уже не ждёт её: повторное
+// уведомление по оплаченному заказу пропускается, за отменённый заказ деньги
+// возвращаются.
+func (b *Bot) paymentConflict(ctx context.Context, chatID int64, t *messages, orderID int64, payment payments.Payment) error {
+	order, err := b.orders.Get(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("get order %d: %w", orderID, err)
+	}
+	if order.Status == orders.StatusPaid {
+		b.log.Info("payment already applied", zap.Int64("order_id", orderID), zap.String("charge_id", payment.ChargeID))
+		return nil
+	}
+
+	b.log.Warn("payment for closed order", zap.Int64("order_id", orderID), zap.String("status", string(order.Status)))
+	b.refund(ctx, chatID, t, orderID, payment, fmt.Sprintf(t.PaymentRefunded, orderID))
+	return nil
+}
+
+// refund возвращает деньги за платёж, который нельзя засчитать заказу, и отвечает
+// покупателю done. Если провайдер деньги не вернул, возврат поручается администраторам.
+func (b *Bot) refund(ctx context.Context, chatID int64, t *messages, orderID int64, payment payments.Payment, done string) {
+	if _, err := b.payments.Refund(ctx, payment.InvoiceID); err != nil {
+		b.log.Error("ERROR: refund payment", zap.Int64("order_id", orderID), zap.String("invoice_id", payment.InvoiceID),
+			zap.String("charge_id", payment.ChargeID), zap.Error(err))
+		admin := b.textsFor("")
+		for _, id := range b.settings.Admins {
+			b.reply(id, fmt.Sprintf(admin.AdminRefundFailed, orderID, formatAmount(payment.Amount, payment.Currency), payment.ChargeID))
+		}
+		b.reply(chatID, fmt.Sprintf(t.PaymentRefundPending, orderID))
+		return
+	}
+	b.reply(chatID, done)
+}
+
+func (b *Bot) answerPreCheckout(queryID, errorMessage string) {
+	b.send(tgbotapi.PreCheckoutConfig{
+		PreCheckoutQueryID: queryID,
+		OK:                 errorMessage == "",
+		ErrorMessage:       errorMessage,
+	})
+}
+
+// invoicePayload - "<номер заказа>:<счёт провайдера>", Telegram допускает до 128 байт.
+func invoicePayload(invoice payments.Invoice) string {
+	return strconv.FormatInt(invoice.OrderID, 10) + ":" + invoice.ID
+}
+
+func parseInvoicePayload(payload string) (orderID int64, invoiceID string, ok bool) {
+	id, invoiceID, ok := strings.Cut(payload, ":")
+	if !ok || invoiceID == "" {
+		return 0, "", false
+	}
+	orderID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || orderID <= 0 {
+		return 0, "", false
+	}
+	return orderID, invoiceID, true
+}
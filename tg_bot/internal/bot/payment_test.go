@@ -0,0 +1,191 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/kripst/krosovka/tg_bot/internal/payments"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newPayingBot - бот с оплатой через provider и оформленным заказом №1 на две пары Air Max 90.
+func newPayingBot(t *testing.T, provider payments.Provider) (*bot.Bot, *telegramtest.Server, *orders.MemoryService) {
+	t.Helper()
+	server := telegramtest.NewServer(t)
+	service := orders.NewMemoryService()
+	b := bot.New(server.BotAPI(t), &fakeInventory{sneakers: testSneakers()}, zap.NewNop(), bot.Settings{
+		Admins:   []int64{adminID},
+		Orders:   service,
+		Payments: provider,
+	})
+
+	ctx := context.Background()
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 5, "cart:add:1"))
+	for _, text := range []string{"/checkout", "Иван", "89991234567", "Москва"} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+	}
+	b.HandleUpdate(ctx, telegramtest.Callback(7, 110, "checkout:confirm"))
+	require.Len(t, service.Orders(), 1)
+	return b, server, service
+}
+
+// lastPreCheckout - последний ответ на pre_checkout_query. tgbotapi не передаёт ok=false,
+// Bot API считает отсутствующий ok отказом.
+func lastPreCheckout(t *testing.T, server *telegramtest.Server) (ok bool, errorMessage string) {
+	t.Helper()
+	calls := server.Calls("answerPreCheckoutQuery")
+	require.NotEmpty(t, calls)
+	params := calls[len(calls)-1].Params
+	return params.Get("ok") == "true", params.Get("error_message")
+}
+
+// Оформленный заказ получает счёт, подтверждённая оплата переводит его в paid.
+func TestPayment_Flow(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	provider := payments.NewFakeProvider(payments.FakeConfig{ProviderToken: "test-token"})
+	b, server, service := newPayingBot(t, provider)
+	ctx := context.Background()
+
+	// --- Assert ---
+	require.Equal("Заказ №1 на 19981.00 ₽ оформлен. Оплатите его по счёту ниже", lastText(t, server, "editMessageText"))
+	invoices := server.Calls("sendInvoice")
+	require.Len(invoices, 1)
+	params := invoices[0].Params
+	require.Equal("7", params.Get("chat_id"))
+	require.Equal("Заказ №1", params.Get("title"))
+	require.Equal("Кроссовки с доставкой по адресу: Москва", params.Get("description"))
+	require.Equal("1:fake-1", params.Get("payload"))
+	require.Equal("test-token", params.Get("provider_token"))
+	require.Equal("RUB", params.Get("currency"))
+	require.JSONEq(`[{"label":"Air Max 90, 42.5 EU (ART-001) × 2","amount":1998100}]`, params.Get("prices"))
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.PreCheckoutQuery(7, "1:fake-1", "RUB", 999050))
+
+	// --- Assert ---
+	ok, message := lastPreCheckout(t, server)
+	require.False(ok)
+	require.Equal("Сумма счёта не совпадает с заказом, оформите заказ заново", message)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.PreCheckoutQuery(7, "1:fake-1", "RUB", 1998100))
+	b.HandleUpdate(ctx, telegramtest.SuccessfulPayment(7, "1:fake-1", "RUB", 1998100))
+
+	// --- Assert ---
+	ok, _ = lastPreCheckout(t, server)
+	require.True(ok)
+	require.Equal(orders.StatusPaid, service.Orders()[0].Status)
+	customer := sentTo(server, "7")
+	require.Equal("Оплата заказа №1 на 19981.00 ₽ получена. Мы позвоним по номеру 89991234567, чтобы согласовать доставку",
+		customer[len(customer)-1])
+	require.Contains(sentTo(server, "100"), "Заказ №1 оплачен: 19981.00 ₽")
+
+	// --- Act ---
+	sent := len(server.Calls("sendMessage"))
+	b.HandleUpdate(ctx, telegramtest.SuccessfulPayment(7, "1:fake-1", "RUB", 1998100))
+	b.HandleUpdate(ctx, telegramtest.PreCheckoutQuery(7, "1:fake-1", "RUB", 1998100))
+
+	// --- Assert ---
+	require.Len(server.Calls("sendMessage"), sent, "повторное уведомление об оплате не дублирует сообщения")
+	payment, _ := provider.Payment("fake-1")
+	require.Equal(payments.StatusSucceeded, payment.Status)
+	ok, message = lastPreCheckout(t, server)
+	require.False(ok)
+	require.Equal("Этот заказ уже оплачен или отменён", message)
+}
+
+// refundFailing - провайдер, у которого не проходит возврат денег.
+type refundFailing struct {
+	*payments.FakeProvider
+}
+
+func (refundFailing) Refund(ctx context.Context, invoiceID string) (payments.Payment, error) {
+	return payments.Payment{}, errors.New("gateway is unavailable")
+}
+
+// Отказ провайдера приходит в ответ на pre_checkout_query, до списания денег.
+func TestPayment_Declined(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	provider := payments.NewFakeProvider(payments.FakeConfig{Outcome: payments.OutcomeFailure})
+	b, server, service := newPayingBot(t, provider)
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.PreCheckoutQuery(7, "1:fake-1", "RUB", 1998100))
+
+	// --- Assert ---
+	ok, message := lastPreCheckout(t, server)
+	require.False(ok)
+	require.Equal("Платёж отклонён, деньги не списаны. Попробуйте другую карту", message)
+	require.Equal(orders.StatusCreated, service.Orders()[0].Status)
+	_, paid := provider.Payment("fake-1")
+	require.False(paid)
+}
+
+// Списанный платёж, который провайдер не принял, возвращается покупателю.
+func TestPayment_RejectedAfterChargeRefunded(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	provider := payments.NewFakeProvider(payments.FakeConfig{})
+	b, server, service := newPayingBot(t, provider)
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.SuccessfulPayment(7, "1:fake-1", "RUB", 999050))
+
+	// --- Assert ---
+	require.Equal(orders.StatusCreated, service.Orders()[0].Status)
+	payment, ok := provider.Payment("fake-1")
+	require.True(ok)
+	require.Equal(payments.StatusRefunded, payment.Status)
+	require.Equal("Платёж по заказу №1 не прошёл, деньги возвращены. Мы позвоним, чтобы договориться об оплате",
+		lastText(t, server, "sendMessage"))
+	require.Len(sentTo(server, "100"), 1, "администратор получает только сам заказ")
+}
+
+// Если вернуть деньги не удалось, возврат поручается администраторам.
+func TestPayment_RefundFailureEscalated(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	provider := refundFailing{payments.NewFakeProvider(payments.FakeConfig{})}
+	b, server, service := newPayingBot(t, provider)
+
+	// --- Act ---
+	b.HandleUpdate(context.Background(), telegramtest.SuccessfulPayment(7, "1:fake-1", "RUB", 999050))
+
+	// --- Assert ---
+	require.Equal(orders.StatusCreated, service.Orders()[0].Status)
+	customer := sentTo(server, "7")
+	require.Equal("Оплату заказа №1 не удалось вернуть автоматически. Менеджер вернёт её и позвонит вам",
+		customer[len(customer)-1])
+	admin := sentTo(server, "100")
+	require.Len(admin, 2)
+	require.Equal("Заказ №1: не удалось вернуть оплату 9990.50 ₽ (платёж provider-charge-1:fake-1), верните её вручную", admin[1])
+}
+
+// За заказ, отменённый во время оплаты, деньги возвращаются.
+func TestPayment_CancelledOrderRefunded(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	provider := payments.NewFakeProvider(payments.FakeConfig{})
+	b, server, service := newPayingBot(t, provider)
+	ctx := context.Background()
+	_, err := service.Transition(ctx, 1, orders.StatusCreated, orders.StatusCancelled, "out of stock")
+	require.NoError(err)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.SuccessfulPayment(7, "1:fake-1", "RUB", 1998100))
+
+	// --- Assert ---
+	require.Equal(orders.StatusCancelled, service.Orders()[0].Status)
+	payment, ok := provider.Payment("fake-1")
+	require.True(ok)
+	require.Equal(payments.StatusRefunded, payment.Status)
+	require.Equal("Заказ №1 отменён, оплата возвращена", lastText(t, server, "sendMessage"))
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+var currencySymbols = map[string]string{
+	"RUB": "₽",
+	"USD": "$",
+	"EUR": "€",
+	"KZT": "₸",
+}
+
+var sizeSystemNames = map[string]string{
+	"EU":   "EU",
+	"US_M": "US M",
+	"US_W": "US W",
+	"UK":   "UK",
+}
+
+// renderCard - карточка товара в HTML-разметке Telegram.
+func (b *Bot) renderCard(t *messages, s *pb.Sneaker) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<b>%s</b>\n", html.EscapeString(s.GetSneakerName()))
+	fmt.Fprintf(&sb, "%s: %s\n", t.CardBrand, html.EscapeString(s.GetBrand()))
+	fmt.Fprintf(&sb, "%s: %s\n", t.CardPrice, b.renderPrice(t, s))
+	if size := renderSize(t, s); size != "" {
+		fmt.Fprintf(&sb, "%s: %s\n", t.CardSize, size)
+	}
+	fmt.Fprintf(&sb, "%s: <code>%s</code>", t.CardArticle, html.EscapeString(s.GetArticle()))
+	if s.GetSneakerDescription() != "" {
+		fmt.Fprintf(&sb, "\n\n%s", html.EscapeString(s.GetSneakerDescription()))
+	}
+	return sb.String()
+}
+
+// renderPrice показывает цену со скидкой рядом с зачёркнутой базовой.
+func (b *Bot) renderPrice(t *messages, s *pb.Sneaker) string {
+	price := formatMoney(s.GetPrice())
+	effective := formatMoney(s.GetEffectivePrice())
+	if s.GetEffectivePrice() == nil || effective == price {
+		return price
+	}
+
+	out := fmt.Sprintf("<s>%s</s> <b>%s</b>", price, effective)
+	if endsAt, err := time.Parse(time.RFC3339, s.GetEffectivePriceEndsAt()); err == nil {
+		out += " " + t.PriceUntil + " " + endsAt.In(b.settings.Location).Format("02.01 15:04")
+	}
+	return out
+}
+
+// formatMoney - сумма с двумя знаками после запятой и символом валюты.
+func formatMoney(m *pb.Money) string {
+	if m == nil {
+		return "—"
+	}
+	return formatAmount(moneyAmount(m), m.GetCurrencyCode())
+}
+
+func formatAmount(amount decimal.Decimal, currency string) string {
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		symbol = currency
+	}
+	return amount.StringFixed(2) + " " + symbol
+}
+
+// moneyAmount - сумма pb.Money десятичным числом.
+func moneyAmount(m *pb.Money) decimal.Decimal {
+	return decimal.New(m.GetUnits(), 0).Add(decimal.New(int64(m.GetNanos()), -9))
+}
+
+// renderSize - размер в его системе и эквиваленты по таблице бренда.
+func renderSize(t *messages, s *pb.Sneaker) string {
+	if s.GetSize() == 0 {
+		return ""
+	}
+	out := formatSize(t, s.GetSize(), s.GetSizeSystem())
+
+	var equivalents []string
+	for _, eq := range s.GetSizeEquivalents() {
+		if eq.GetSystem() == s.GetSizeSystem() {
+			continue
+		}
+		equivalents = append(equivalents, formatSize(t, eq.GetValue(), eq.GetSystem()))
+	}
+	if len(equivalents) > 0 {
+		out += " (" + strings.Join(equivalents, ", ") + ")"
+	}
+	return out
+}
+
+func formatSize(t *messages, value float32, system string) string {
+	name, ok := sizeSystemNames[system]
+	if system == "CM" {
+		name = t.SizeCM
+	} else if !ok {
+		name = system
+	}
+	return strings.TrimSpace(strconv.FormatFloat(float64(value), 'f', -1, 32) + " " + name)
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+)
+
+// Размеры EU без системы: число в этих границах в запросе считается размером,
+// остальные числа ("air max 90", "air force 1") остаются частью названия
+const (
+	minBareSize = 16
+	maxBareSize = 52
+)
+
+// searchBrands - бренды, которые узнаются в тексте запроса, в написании каталога
+var searchBrands = map[string]string{
+	"nike":        "Nike",
+	"jordan":      "Jordan",
+	"adidas":      "Adidas",
+	"asics":       "Asics",
+	"new balance": "New Balance",
+	"nb":          "New Balance",
+	"puma":        "Puma",
+	"reebok":      "Reebok",
+	"converse":    "Converse",
+	"vans":        "Vans",
+	"salomon":     "Salomon",
+}
+
+// searchCurrencies - обозначения валют в запросе
+var searchCurrencies = map[string]string{
+	"rub": "RUB", "руб": "RUB", "р": "RUB", "₽": "RUB",
+	"usd": "USD", "$": "USD",
+	"eur": "EUR", "€": "EUR",
+}
+
+// searchSystems - обозначения систем размеров в запросе
+var searchSystems = map[string]string{
+	"eu": "EU", "us": "US_M", "usm": "US_M", "us_m": "US_M", "usw": "US_W", "us_w": "US_W",
+	"uk": "UK", "cm": "CM", "см": "CM",
+}
+
+// Слова перед ценой: "до 15000", "от 10000". "max" сюда не входит: это "Air Max 90"
+var (
+	maxPriceWords = map[string]bool{"до": true, "<": true, "<=": true, "under": true}
+	minPriceWords = map[string]bool{"от": true, ">": true, ">=": true, "from": true}
+)
+
+// searchQuery - фильтры, извлечённые из текста inline-запроса.
+type searchQuery struct {
+	Brand      string
+	Name       string
+	Size       float32
+	SizeSystem string
+	MinPrice   *pb.Money
+	MaxPrice   *pb.Money
+}
+
+// parseSearchQuery разбирает "air max 42 до 15000": бренд, размер и границы
+// цены узнаются по словам, всё остальное ищется в названии.
+func parseSearchQuery(text string) searchQuery {
+	tokens := splitSearchTokens(text)
+	var q searchQuery
+
+	// Валюта относится ко всем ценам запроса, поэтому ищется заранее
+	currency := defaultCurrency
+	var rest []string
+	for _, tok := range tokens {
+		if code, ok := searchCurrencies[tok]; ok {
+			currency = code
+			continue
+		}
+		rest = append(rest, tok)
+	}
+	tokens = rest
+
+	var name []string
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		var next string
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		if brand, ok := searchBrands[tok+" "+next]; ok && next != "" && q.Brand == "" {
+			q.Brand = brand
+			i++
+			continue
+		}
+		if brand, ok := searchBrands[tok]; ok && q.Brand == "" {
+			q.Brand = brand
+			continue
+		}
+
+		if maxPriceWords[tok] || minPriceWords[tok] {
+			if price, err := parsePrice(next, currency); err == nil {
+				if maxPriceWords[tok] {
+					q.MaxPrice = price
+				} else {
+					q.MinPrice = price
+				}
+				i++
+				continue
+			}
+		}
+		if from, to, ok := strings.Cut(tok, "-"); ok {
+			minPrice, errMin := parsePrice(from, currency)
+			maxPrice, errMax := parsePrice(to, currency)
+			if errMin == nil && errMax == nil {
+				q.MinPrice, q.MaxPrice = minPrice, maxPrice
+				continue
+			}
+		}
+
+		if value, err := strconv.ParseFloat(tok, 32); err == nil && value > 0 && q.SizeSystem == "" {
+			// "9 us w" - система из двух слов
+			if i+2 < len(tokens) {
+				if system, ok := searchSystems[next+"_"+tokens[i+2]]; ok {
+					q.Size, q.SizeSystem = float32(value), system
+					i += 2
+					continue
+				}
+			}
+			if system, ok := searchSystems[next]; ok {
+				q.Size, q.SizeSystem = float32(value), system
+				i++
+				continue
+			}
+			if value >= minBareSize && value <= maxBareSize {
+				q.Size, q.SizeSystem = float32(value), "EU"
+				continue
+			}
+		}
+
+		name = append(name, tok)
+	}
+	q.Name = strings.Join(name, " ")
+	return q
+}
+
+// splitSearchTokens приводит запрос к нижнему регистру и отделяет знаки
+// сравнения и валют от чисел: "<15000$" -> "<", "15000", "$".
+func splitSearchTokens(text string) []string {
+	text = strings.ToLower(strings.ReplaceAll(text, ",", "."))
+	for _, sign := range []string{"<=", ">=", "<", ">", "$", "€", "₽"} {
+		text = strings.ReplaceAll(text, sign, " "+sign+" ")
+	}
+	// "< =" после замены выше склеивается обратно
+	text = strings.NewReplacer("<  =", "<=", ">  =", ">=").Replace(text)
+
+	var tokens []string
+	for _, f := range strings.Fields(text) {
+		tokens = append(tokens, splitSizeSuffix(f)...)
+	}
+	return tokens
+}
+
+// splitSizeSuffix отделяет систему, написанную вплотную к размеру: "42eu", "9us".
+func splitSizeSuffix(token string) []string {
+	for suffix := range searchSystems {
+		if number, ok := strings.CutSuffix(token, suffix); ok && number != "" {
+			if _, err := strconv.ParseFloat(number, 32); err == nil {
+				return []string{number, suffix}
+			}
+		}
+	}
+	return []string{token}
+}
+
+// request - запрос к inventory с фильтрами поиска.
+func (q searchQuery) request(offset, limit int) *pb.GetSneakersRequest {
+	return &pb.GetSneakersRequest{
+		Brand:      q.Brand,
+		Name:       q.Name,
+		Size:       q.Size,
+		SizeSystem: q.SizeSystem,
+		MinPrice:   q.MinPrice,
+		MaxPrice:   q.MaxPrice,
+		Offset:     int32(offset),
+		Partition:  int32(limit),
+	}
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+)
+
+// maxSubscriptions - сколько подписок может быть у одного чата
+const maxSubscriptions = 20
+
+// sizeTolerance - погрешность сравнения размеров из таблиц брендов (42.5 и 42.49)
+const sizeTolerance = 0.05
+
+// priceWords - второй аргумент /subscribe, включающий подписку на цену
+var priceWords = map[string]bool{"price": true, "цена": true}
+
+// handleSubscribe подписывает чат на появление товара или снижение цены:
+// /subscribe <артикул> [размер система | price [порог]].
+func (b *Bot) handleSubscribe(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	fields := strings.Fields(msg.CommandArguments())
+	if len(fields) == 0 {
+		b.reply(msg.Chat.ID, t.UsageSubscribe)
+		return nil
+	}
+	article, rest := fields[0], fields[1:]
+
+	sub := subscriptions.Subscription{ChatID: msg.Chat.ID, Kind: subscriptions.KindStock, Article: article}
+	if msg.From != nil {
+		sub.Language = msg.From.LanguageCode
+	}
+	if len(rest) > 0 && priceWords[strings.ToLower(rest[0])] {
+		sub.Kind = subscriptions.KindPrice
+	} else if len(rest) > 0 {
+		size, system, err := parseSize(strings.Join(rest, " "))
+		if err != nil {
+			b.reply(msg.Chat.ID, t.BadSize)
+			return nil
+		}
+		sub.Size, sub.SizeSystem = size, system
+	}
+
+	sneaker, err := b.findByArticle(ctx, article)
+	if err != nil {
+		return err
+	}
+
+	var reply string
+	switch sub.Kind {
+	case subscriptions.KindStock:
+		if sneaker != nil && sizeMatches(sub, sneaker) {
+			b.reply(msg.Chat.ID, fmt.Sprintf(t.AlreadyAvailable, article))
+			return nil
+		}
+		reply = fmt.Sprintf(t.Subscribed, article)
+		if sub.Size != 0 {
+			reply = fmt.Sprintf(t.SubscribedSize, article, formatSize(t, sub.Size, sub.SizeSystem))
+		}
+
+	case subscriptions.KindPrice:
+		// Снижение считается от текущей цены, поэтому товар должен быть в каталоге
+		if sneaker == nil {
+			b.reply(msg.Chat.ID, fmt.Sprintf(t.NotFound, article))
+			return nil
+		}
+		price := currentPrice(sneaker)
+		sub.LastPrice, sub.Currency = moneyAmount(price), price.GetCurrencyCode()
+		reply = fmt.Sprintf(t.SubscribedPrice, article, formatMoney(price))
+
+		if len(rest) > 1 {
+			threshold, err := parsePrice(strings.Join(rest[1:], " "), sub.Currency)
+			if err != nil || threshold.GetCurrencyCode() != sub.Currency {
+				b.reply(msg.Chat.ID, t.BadPrice)
+				return nil
+			}
+			amount := moneyAmount(threshold)
+			if amount.GreaterThanOrEqual(sub.LastPrice) {
+				b.reply(msg.Chat.ID, fmt.Sprintf(t.AlreadyCheaper, article, formatMoney(price)))
+				return nil
+			}
+			sub.Threshold = &amount
+			reply = fmt.Sprintf(t.SubscribedBelow, article, formatMoney(threshold))
+		}
+	}
+
+	existing, err := b.subscriptions.ListByChat(ctx, msg.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+	// Смена порога у существующей подписки лимит не расходует
+	if len(existing) >= maxSubscriptions && !slices.ContainsFunc(existing, sub.SameTarget) {
+		b.reply(msg.Chat.ID, fmt.Sprintf(t.TooManySubscriptions, maxSubscriptions))
+		return nil
+	}
+
+	_, result, err := b.subscriptions.Add(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("add subscription: %w", err)
+	}
+	switch result {
+	case subscriptions.Exists:
+		reply = t.AlreadySubscribed
+	case subscriptions.ThresholdChanged:
+		reply = fmt.Sprintf(t.ThresholdChanged, reply)
+	}
+	b.reply(msg.Chat.ID, reply)
+	return nil
+}
+
+// handleSubscriptions показывает подписки чата с номерами для /unsubscribe.
+func (b *Bot) handleSubscriptions(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	subs, err := b.subscriptions.ListByChat(ctx, msg.Chat.ID)
+	if err != nil {
+		return fmt.Errorf("list subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		b.reply(msg.Chat.ID, t.SubscriptionsEmpty)
+		return nil
+	}
+
+	lines := []string{t.SubscriptionsTitle}
+	for _, sub := range subs {
+		lines = append(lines, fmt.Sprintf("%d. %s - %s", sub.ID, sub.Article, describeSubscription(t, sub)))
+	}
+	b.reply(msg.Chat.ID, strings.Join(lines, "\n"))
+	return nil
+}
+
+func (b *Bot) handleUnsubscribe(ctx context.Context, msg *tgbotapi.Message) error {
+	t := b.texts(msg.From)
+	arg := strings.TrimPrefix(strings.TrimSpace(msg.CommandArguments()), "#")
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		b.reply(msg.Chat.ID, t.UsageUnsubscribe)
+		return nil
+	}
+
+	removed, err := b.subscriptions.Remove(ctx, msg.Chat.ID, id)
+	if err != nil {
+		return fmt.Errorf("remove subscription %d: %w", id, err)
+	}
+	if !removed {
+		b.reply(msg.Chat.ID, fmt.Sprintf(t.SubscriptionNotFound, arg))
+		return nil
+	}
+	b.reply(msg.Chat.ID, t.Unsubscribed)
+	return nil
+}
+
+func describeSubscription(t *messages, sub subscriptions.Subscription) string {
+	switch {
+	case sub.Kind == subscriptions.KindPrice && sub.Threshold != nil:
+		return fmt.Sprintf(t.SubscriptionBelow, formatAmount(*sub.Threshold, sub.Currency))
+	case sub.Kind == subscriptions.KindPrice:
+		return t.SubscriptionPrice
+	case sub.Size != 0:
+		return t.SubscriptionStock + ", " + formatSize(t, sub.Size, sub.SizeSystem)
+	}
+	return t.SubscriptionStock
+}
+
+// currentPrice - цена, по которой товар продаётся сейчас.
+func currentPrice(s *pb.Sneaker) *pb.Money {
+	if s.GetEffectivePrice() != nil {
+		return s.GetEffectivePrice()
+	}
+	return s.GetPrice()
+}
+
+// sizeMatches - подходит ли товар по размеру подписки: в своей системе или по таблице бренда.
+func sizeMatches(sub subscriptions.Subscription, s *pb.Sneaker) bool {
+	if sub.Size == 0 {
+		return true
+	}
+	same := func(value float32, system string) bool {
+		return system == sub.SizeSystem && math.Abs(float64(value-sub.Size)) < sizeTolerance
+	}
+	if same(s.GetSize(), s.GetSizeSystem()) {
+		return true
+	}
+	for _, eq := range s.GetSizeEquivalents() {
+		if same(eq.GetValue(), eq.GetSystem()) {
+			return true
+		}
+	}
+	return false
+}
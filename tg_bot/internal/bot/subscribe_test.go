@@ -0,0 +1,327 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/bot"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/kripst/krosovka/tg_bot/internal/throttle"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newSubscriptionBot(t *testing.T, inventory *fakeInventory, quota *throttle.Quota) (*bot.Bot, *telegramtest.Server, *subscriptions.MemoryStore) {
+	t.Helper()
+	server := telegramtest.NewServer(t)
+	store := subscriptions.NewMemoryStore()
+	b := bot.New(server.BotAPI(t), inventory, zap.NewNop(), bot.Settings{
+		Subscriptions: store,
+		Throttle:      throttle.New(1000, time.Millisecond),
+		NotifyQuota:   quota,
+	})
+	return b, server, store
+}
+
+// sentTo - тексты сообщений, отправленных в чат chatID.
+func sentTo(server *telegramtest.Server, chatID string) []string {
+	var out []string
+	for _, call := range server.Calls("sendMessage") {
+		if call.Params.Get("chat_id") == chatID {
+			out = append(out, call.Params.Get("text"))
+		}
+	}
+	return out
+}
+
+// /subscribe разбирает артикул, размер и порог цены, /subscriptions и /unsubscribe управляют списком.
+func TestSubscribe_Commands(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	b, server, store := newSubscriptionBot(t, &fakeInventory{sneakers: testSneakers()}, throttle.NewQuota(20, time.Hour))
+	ctx := context.Background()
+
+	// --- Act ---
+	for _, text := range []string{
+		"/subscribe",
+		"/subscribe ART-009",
+		"/subscribe ART-009",
+		"/subscribe ART-001 42.5 EU",
+		"/subscribe ART-001 9 us m",
+		"/subscribe ART-001 44 EU",
+		"/subscribe ART-001 44",
+		"/subscribe ART-001 цена 10000",
+		"/subscribe ART-001 price 8000",
+		"/subscribe ART-009 price",
+		"/subscribe ART-001 price 9000",
+		"/subscribe ART-001 price 8000",
+		"/subscribe ART-001 price 8000",
+	} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+	}
+
+	// --- Assert ---
+	replies := sentTo(server, "7")
+	require.Len(replies, 13)
+	require.Contains(replies[0], "Использование")
+	require.Equal("Сообщу, когда ART-009 появится в наличии. Подписки: /subscriptions", replies[1])
+	require.Equal("Такая подписка уже есть", replies[2])
+	require.Equal("Товар ART-001 уже в наличии: /catalog", replies[3])
+	require.Equal("Товар ART-001 уже в наличии: /catalog", replies[4], "размер по таблице бренда")
+	require.Equal("Сообщу, когда ART-001 появится в размере 44 EU. Подписки: /subscriptions", replies[5])
+	require.Contains(replies[6], "Укажите размер и систему")
+	require.Equal("Товар ART-001 уже стоит 9990.50 ₽", replies[7])
+	require.Equal("Сообщу, когда цена ART-001 опустится до 8000.00 ₽. Подписки: /subscriptions", replies[8])
+	require.Equal("Товар ART-009 не найден", replies[9])
+	require.Equal("Порог подписки изменён. Сообщу, когда цена ART-001 опустится до 9000.00 ₽. Подписки: /subscriptions", replies[10])
+	require.Equal("Порог подписки изменён. Сообщу, когда цена ART-001 опустится до 8000.00 ₽. Подписки: /subscriptions", replies[11])
+	require.Equal("Такая подписка уже есть", replies[12])
+
+	subs, err := store.ListByChat(ctx, 7)
+	require.NoError(err)
+	require.Len(subs, 3)
+	require.Equal("9990.5", subs[2].LastPrice.String())
+	require.Equal("RUB", subs[2].Currency)
+
+	// --- Act ---
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/subscriptions"))
+	b.HandleUpdate(ctx, telegramtest.Message(8, "/unsubscribe 1"))
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/unsubscribe 1"))
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/unsubscribe abc"))
+
+	// --- Assert ---
+	replies = sentTo(server, "7")
+	require.Equal("Подписки (удалить: /unsubscribe <номер>):\n"+
+		"1. ART-009 - появление в наличии\n"+
+		"2. ART-001 - появление в наличии, 44 EU\n"+
+		"3. ART-001 - цена до 8000.00 ₽", replies[13])
+	require.Equal("Подписка 1 не найдена", sentTo(server, "8")[0], "чужую подписку удалить нельзя")
+	require.Equal("Подписка удалена", replies[14])
+	require.Contains(replies[15], "Использование: /unsubscribe")
+	subs, err = store.ListByChat(ctx, 7)
+	require.NoError(err)
+	require.Len(subs, 2)
+}
+
+// Уведомление о появлении и снижении цены отправляется один раз, на языке подписчика.
+func TestNotifier_BackInStockAndPriceDrop(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{sneakers: testSneakers()}
+	b, server, store := newSubscriptionBot(t, inventory, throttle.NewQuota(20, time.Hour))
+	ctx := context.Background()
+
+	english := telegramtest.Message(8, "/subscribe ART-001 price")
+	english.Message.From.LanguageCode = "en"
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/subscribe ART-009 43 EU"))
+	b.HandleUpdate(ctx, english)
+	b.HandleUpdate(ctx, telegramtest.Message(9, "/subscribe ART-001 price 9000"))
+
+	// Первый проход только запоминает момент, с которого смотреть изменения
+	require.NoError(b.PollChanges(ctx))
+	watermark, err := store.Watermark(ctx)
+	require.NoError(err)
+	require.False(watermark.IsZero())
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	inventory.sneakers = append(inventory.sneakers, &pb.Sneaker{
+		SneakerId: 3, Article: "ART-009", SneakerName: "Gel-Lyte III", Brand: "Asics",
+		Price: &pb.Money{CurrencyCode: "RUB", Units: 11990},
+		Size:  9.5, SizeSystem: "US_M", UpdatedAt: now,
+		SizeEquivalents: []*pb.SneakerSize{{System: "US_M", Value: 9.5}, {System: "EU", Value: 43}},
+	})
+	inventory.sneakers[0].EffectivePrice = &pb.Money{CurrencyCode: "RUB", Units: 9490}
+	inventory.sneakers[0].UpdatedAt = now
+
+	// --- Act ---
+	require.NoError(b.PollChanges(ctx))
+	require.NoError(b.PollChanges(ctx))
+
+	// --- Assert ---
+	stock := sentTo(server, "7")
+	require.Len(stock, 2)
+	require.True(strings.HasPrefix(stock[1], "Снова в наличии:\n\n<b>Gel-Lyte III</b>"), stock[1])
+
+	drop := sentTo(server, "8")
+	require.Len(drop, 2)
+	require.True(strings.HasPrefix(drop[1], "Price dropped: 9990.50 ₽ → 9490.00 ₽. Unsubscribe: /unsubscribe 2"), drop[1])
+
+	require.Len(sentTo(server, "9"), 1, "цена ещё выше порога")
+
+	// Сработавшая подписка на наличие удаляется, на цену - остаётся с новой ценой
+	subs, err := store.ListByArticles(ctx, []string{"ART-001", "ART-009"})
+	require.NoError(err)
+	require.Len(subs, 2)
+	require.Equal("9490", subs[0].LastPrice.String())
+}
+
+// Уведомление сверх квоты чата откладывается до следующей проверки, подписки чата,
+// заблокировавшего бота, удаляются.
+func TestNotifier_QuotaAndBlocked(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{}
+	b, server, store := newSubscriptionBot(t, inventory, throttle.NewQuota(1, 200*time.Millisecond))
+	ctx := context.Background()
+
+	for _, text := range []string{"/subscribe ART-101", "/subscribe ART-102"} {
+		b.HandleUpdate(ctx, telegramtest.Message(7, text))
+		b.HandleUpdate(ctx, telegramtest.Message(9, text))
+	}
+	require.NoError(store.SetWatermark(ctx, time.Now().Add(-time.Hour)))
+	server.Block(9)
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for i, article := range []string{"ART-101", "ART-102"} {
+		inventory.sneakers = append(inventory.sneakers, &pb.Sneaker{
+			SneakerId: int32(i + 1), Article: article, SneakerName: article, Brand: "Nike",
+			Price: &pb.Money{CurrencyCode: "RUB", Units: 9990}, UpdatedAt: now,
+		})
+	}
+
+	// --- Act ---
+	require.NoError(b.PollChanges(ctx))
+
+	// --- Assert ---
+	require.Len(sentTo(server, "7"), 3, "два ответа на /subscribe и одно уведомление")
+	left, err := store.ListByChat(ctx, 7)
+	require.NoError(err)
+	require.Empty(left, "обе подписки заняты, уведомление без квоты отложено")
+
+	blocked, err := store.ListByChat(ctx, 9)
+	require.NoError(err)
+	require.Empty(blocked)
+
+	// --- Act ---
+	time.Sleep(250 * time.Millisecond)
+	require.NoError(b.PollChanges(ctx))
+
+	// --- Assert ---
+	sent := sentTo(server, "7")
+	require.Len(sent, 4, "отложенное уведомление ушло, когда восстановилась квота")
+	require.Contains(sent[2], "ART-101")
+	require.Contains(sent[3], "ART-102")
+}
+
+// Пока рассылку ведёт другая реплика, бот изменения не проверяет и уведомлений не шлёт.
+func TestNotifier_SingleReplica(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{}
+	b, server, store := newSubscriptionBot(t, inventory, throttle.NewQuota(20, time.Hour))
+	ctx := context.Background()
+
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/subscribe ART-101"))
+	require.NoError(store.SetWatermark(ctx, time.Now().Add(-time.Hour)))
+	inventory.sneakers = append(inventory.sneakers, &pb.Sneaker{
+		SneakerId: 1, Article: "ART-101", SneakerName: "ART-101", Brand: "Nike",
+		Price: &pb.Money{CurrencyCode: "RUB", Units: 9990}, UpdatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	unlock, ok, err := store.LockNotifier(ctx)
+	require.NoError(err)
+	require.True(ok)
+
+	// --- Act ---
+	run, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	b.RunNotifier(run, 10*time.Millisecond)
+	cancel()
+
+	// --- Assert ---
+	require.Len(sentTo(server, "7"), 1, "только ответ на /subscribe")
+
+	// --- Act ---
+	unlock()
+	run, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+	b.RunNotifier(run, 10*time.Millisecond)
+	cancel()
+
+	// --- Assert ---
+	sent := sentTo(server, "7")
+	require.Len(sent, 2)
+	require.Contains(sent[1], "ART-101")
+}
+
+// catalog - count товаров с артикулами ART-001... и updated_at от base с шагом step.
+func catalog(count int, base time.Time, step time.Duration) []*pb.Sneaker {
+	sneakers := make([]*pb.Sneaker, 0, count)
+	for i := 1; i <= count; i++ {
+		article := fmt.Sprintf("ART-%03d", i)
+		sneakers = append(sneakers, &pb.Sneaker{
+			SneakerId: int32(i), Article: article, SneakerName: article, Brand: "Nike",
+			Price:     &pb.Money{CurrencyCode: "RUB", Units: 9990},
+			UpdatedAt: base.Add(time.Duration(i) * step).Format(time.RFC3339Nano),
+		})
+	}
+	return sneakers
+}
+
+// Товар, изменённый между страницами, уходит в конец выборки и не сдвигает остальные:
+// товар на границе страниц не пропускается.
+func TestNotifier_ChangedDuringPoll(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	inventory := &fakeInventory{}
+	b, server, store := newSubscriptionBot(t, inventory, throttle.NewQuota(20, time.Hour))
+	ctx := context.Background()
+
+	b.HandleUpdate(ctx, telegramtest.Message(7, "/subscribe ART-101"))
+	require.NoError(store.SetWatermark(ctx, time.Now().Add(-time.Hour)))
+	inventory.sneakers = catalog(150, time.Now().UTC(), 0)
+	pages := 0
+	inventory.onSearch = func(in *pb.GetSneakersRequest) error {
+		if pages++; pages == 2 {
+			inventory.sneakers[0].UpdatedAt = time.Now().UTC().Add(time.Second).Format(time.RFC3339Nano)
+		}
+		return nil
+	}
+
+	// --- Act ---
+	require.NoError(b.PollChanges(ctx))
+
+	// --- Assert ---
+	sent := sentTo(server, "7")
+	require.Len(sent, 2)
+	require.Contains(sent[1], "ART-101")
+}
+
+// Если следующая страница не прочиталась, отметка остаётся на последнем разобранном товаре.
+func TestNotifier_WatermarkOnFailedPage(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	base := time.Now().UTC().Add(-time.Minute)
+	inventory := &fakeInventory{sneakers: catalog(150, base, time.Millisecond)}
+	b, _, store := newSubscriptionBot(t, inventory, throttle.NewQuota(20, time.Hour))
+	ctx := context.Background()
+
+	require.NoError(store.SetWatermark(ctx, base.Add(-time.Hour)))
+	inventory.onSearch = func(in *pb.GetSneakersRequest) error {
+		if in.GetAfterId() != 0 {
+			return errors.New("inventory unavailable")
+		}
+		return nil
+	}
+
+	// --- Act ---
+	err := b.PollChanges(ctx)
+
+	// --- Assert ---
+	require.Error(err)
+	watermark, err := store.Watermark(ctx)
+	require.NoError(err)
+	require.True(watermark.Equal(base.Add(100*time.Millisecond)), watermark)
+
+	// --- Act ---
+	inventory.onSearch = nil
+	require.NoError(b.PollChanges(ctx))
+
+	// --- Assert ---
+	watermark, err = store.Watermark(ctx)
+	require.NoError(err)
+	require.True(watermark.Equal(base.Add(150*time.Millisecond)), watermark)
+}
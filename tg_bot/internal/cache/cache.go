@@ -0,0 +1,81 @@
+// Package cache - небольшой кэш в памяти с временем жизни записей.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTL хранит не больше size записей, каждая живёт ttl.
+// При переполнении сначала удаляются истёкшие записи, затем самые старые.
+type TTL[K comparable, V any] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	size  int
+	items map[K]entry[V]
+	now   func() time.Time
+}
+
+func New[K comparable, V any](ttl time.Duration, size int) *TTL[K, V] {
+	return &TTL[K, V]{ttl: ttl, size: max(size, 1), items: make(map[K]entry[V]), now: time.Now}
+}
+
+// SetClock подменяет часы, для тестов.
+func (c *TTL[K, V]) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok || !c.now().Before(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *TTL[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.items[key]; !ok && len(c.items) >= c.size {
+		c.evict(now)
+	}
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *TTL[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
+
+// evict освобождает место хотя бы под одну запись.
+func (c *TTL[K, V]) evict(now time.Time) {
+	var (
+		oldest    K
+		oldestAt  time.Time
+		hasOldest bool
+	)
+	for key, e := range c.items {
+		if !now.Before(e.expiresAt) {
+			delete(c.items, key)
+			continue
+		}
+		if !hasOldest || e.expiresAt.Before(oldestAt) {
+			oldest, oldestAt, hasOldest = key, e.expiresAt, true
+		}
+	}
+	if len(c.items) >= c.size && hasOldest {
+		delete(c.items, oldest)
+	}
+}
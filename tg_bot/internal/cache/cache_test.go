@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/cache"
+	"github.com/stretchr/testify/require"
+)
+
+// Запись недоступна после истечения ttl.
+func TestTTL_Expire(t *testing.T) {
+	require := require.New(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := cache.New[string, int](time.Minute, 10)
+	c.SetClock(func() time.Time { return now })
+
+	c.Set("a", 1)
+	v, ok := c.Get("a")
+	require.True(ok)
+	require.Equal(1, v)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	require.False(ok)
+}
+
+// При переполнении вытесняются истёкшие записи, затем самая старая.
+func TestTTL_Evict(t *testing.T) {
+	require := require.New(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := cache.New[string, int](time.Minute, 2)
+	c.SetClock(func() time.Time { return now })
+
+	c.Set("a", 1)
+	now = now.Add(time.Second)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	require.Equal(2, c.Len())
+	_, ok := c.Get("a")
+	require.False(ok)
+	_, ok = c.Get("b")
+	require.True(ok)
+
+	now = now.Add(time.Hour)
+	c.Set("d", 4)
+	require.Equal(1, c.Len())
+}
@@ -0,0 +1,47 @@
+// Package cart хранит корзины покупателей до оформления заказа.
+package cart
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxQuantity - сколько пар одного товара можно положить в корзину
+const MaxQuantity = 10
+
+// Item - товар в корзине. Товар inventory - это артикул в одном размере,
+// поэтому позиция определяется SneakerID.
+type Item struct {
+	SneakerID  int32
+	Article    string
+	Name       string
+	Size       float32
+	SizeSystem string
+	Quantity   int
+	// Price, Currency - цена за пару, которую покупатель видел; при оформлении
+	// сверяется с inventory, чтобы предупредить о подорожании
+	Price    decimal.Decimal
+	Currency string
+	AddedAt  time.Time
+}
+
+// Total - стоимость позиции.
+func (i Item) Total() decimal.Decimal {
+	return i.Price.Mul(decimal.NewFromInt(int64(i.Quantity)))
+}
+
+// Store хранит корзины по чатам.
+type Store interface {
+	// Items - корзина чата в порядке добавления.
+	Items(ctx context.Context, chatID int64) ([]Item, error)
+	// Add кладёт товар в корзину или добавляет item.Quantity к уже лежащему,
+	// не больше MaxQuantity; цена и название обновляются. Возвращает итоговое количество.
+	Add(ctx context.Context, chatID int64, item Item) (int, error)
+	// SetQuantity меняет количество, 0 убирает товар; false - такого товара в корзине нет.
+	SetQuantity(ctx context.Context, chatID int64, sneakerID int32, quantity int) (bool, error)
+	// Reprice записывает актуальную цену после проверки в inventory.
+	Reprice(ctx context.Context, chatID int64, sneakerID int32, price decimal.Decimal, currency string) error
+	Clear(ctx context.Context, chatID int64) error
+}
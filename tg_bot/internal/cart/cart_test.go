@@ -0,0 +1,92 @@
+package cart_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/cart"
+	"github.com/kripst/krosovka/tg_bot/internal/postgres"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, cart.NewMemoryStore())
+}
+
+// PostgreSQL проверяется, только если задан TG_TEST_POSTGRES_DSN.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TG_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TG_TEST_POSTGRES_DSN is not set")
+	}
+	pool, err := postgres.Connect(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	testStore(t, cart.NewPostgresStore(pool))
+}
+
+// testStore - общие требования к любому Store.
+func testStore(t *testing.T, store cart.Store) {
+	require := require.New(t)
+	ctx := context.Background()
+	chatID := time.Now().UnixNano()
+	t.Cleanup(func() { _ = store.Clear(ctx, chatID) })
+	item := func(id int32, quantity int, price string) cart.Item {
+		return cart.Item{
+			SneakerID: id, Article: "ART-00" + string(rune('0'+id)), Name: "Air Max 90",
+			Size: 42.5, SizeSystem: "EU", Quantity: quantity,
+			Price: decimal.RequireFromString(price), Currency: "RUB",
+		}
+	}
+
+	// --- Добавление и повтор ---
+	n, err := store.Add(ctx, chatID, item(1, 1, "9990.5"))
+	require.NoError(err)
+	require.Equal(1, n)
+	n, err = store.Add(ctx, chatID, item(2, 2, "10990"))
+	require.NoError(err)
+	require.Equal(2, n)
+	n, err = store.Add(ctx, chatID, item(1, 1, "9490"))
+	require.NoError(err)
+	require.Equal(2, n, "повтор увеличивает количество")
+
+	items, err := store.Items(ctx, chatID)
+	require.NoError(err)
+	require.Len(items, 2)
+	require.Equal(int32(1), items[0].SneakerID, "порядок добавления")
+	require.Equal(float32(42.5), items[0].Size)
+	require.True(decimal.RequireFromString("9490").Equal(items[0].Price), "цена обновляется")
+	require.True(decimal.RequireFromString("18980").Equal(items[0].Total()))
+
+	// --- Количество ограничено ---
+	n, err = store.Add(ctx, chatID, item(2, 100, "10990"))
+	require.NoError(err)
+	require.Equal(cart.MaxQuantity, n)
+
+	ok, err := store.SetQuantity(ctx, chatID, 2, 3)
+	require.NoError(err)
+	require.True(ok)
+	ok, err = store.SetQuantity(ctx, chatID, 1, 0)
+	require.NoError(err)
+	require.True(ok)
+	ok, err = store.SetQuantity(ctx, chatID, 1, 1)
+	require.NoError(err)
+	require.False(ok)
+
+	require.NoError(store.Reprice(ctx, chatID, 2, decimal.RequireFromString("11990"), "RUB"))
+	items, err = store.Items(ctx, chatID)
+	require.NoError(err)
+	require.Len(items, 1)
+	require.Equal(3, items[0].Quantity)
+	require.True(decimal.RequireFromString("11990").Equal(items[0].Price))
+
+	// --- Очистка ---
+	require.NoError(store.Clear(ctx, chatID))
+	items, err = store.Items(ctx, chatID)
+	require.NoError(err)
+	require.Empty(items)
+}
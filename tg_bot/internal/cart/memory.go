@@ -0,0 +1,82 @@
+package cart
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MemoryStore хранит корзины в памяти процесса: годится для одной реплики и тестов.
+type MemoryStore struct {
+	mu    sync.Mutex
+	carts map[int64][]Item
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{carts: make(map[int64][]Item)}
+}
+
+func (s *MemoryStore) Items(ctx context.Context, chatID int64) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.carts[chatID]), nil
+}
+
+func (s *MemoryStore) Add(ctx context.Context, chatID int64, item Item) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := s.carts[chatID]
+	if i := s.index(chatID, item.SneakerID); i >= 0 {
+		item.Quantity = min(items[i].Quantity+item.Quantity, MaxQuantity)
+		item.AddedAt = items[i].AddedAt
+		items[i] = item
+		return item.Quantity, nil
+	}
+
+	item.Quantity = min(item.Quantity, MaxQuantity)
+	item.AddedAt = time.Now()
+	s.carts[chatID] = append(items, item)
+	return item.Quantity, nil
+}
+
+func (s *MemoryStore) SetQuantity(ctx context.Context, chatID int64, sneakerID int32, quantity int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(chatID, sneakerID)
+	if i < 0 {
+		return false, nil
+	}
+	if quantity <= 0 {
+		s.carts[chatID] = slices.Delete(s.carts[chatID], i, i+1)
+		return true, nil
+	}
+	s.carts[chatID][i].Quantity = min(quantity, MaxQuantity)
+	return true, nil
+}
+
+func (s *MemoryStore) Reprice(ctx context.Context, chatID int64, sneakerID int32, price decimal.Decimal, currency string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := s.index(chatID, sneakerID); i >= 0 {
+		s.carts[chatID][i].Price = price
+		s.carts[chatID][i].Currency = currency
+	}
+	return nil
+}
+
+func (s *MemoryStore) Clear(ctx context.Context, chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.carts, chatID)
+	return nil
+}
+
+func (s *MemoryStore) index(chatID int64, sneakerID int32) int {
+	return slices.IndexFunc(s.carts[chatID], func(i Item) bool { return i.SneakerID == sneakerID })
+}
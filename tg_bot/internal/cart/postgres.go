@@ -0,0 +1,97 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// PostgresStore хранит корзины в PostgreSQL. Схему создаёт пакет migrate.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) Items(ctx context.Context, chatID int64) ([]Item, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT sneaker_id, article, name, size, size_system, quantity, price, currency, added_at
+		FROM cart_items WHERE chat_id = $1 ORDER BY added_at, sneaker_id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("select cart items: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Item
+	for rows.Next() {
+		var (
+			item Item
+			size decimal.Decimal
+		)
+		if err := rows.Scan(&item.SneakerID, &item.Article, &item.Name, &size, &item.SizeSystem,
+			&item.Quantity, &item.Price, &item.Currency, &item.AddedAt); err != nil {
+			return nil, fmt.Errorf("scan cart item: %w", err)
+		}
+		item.Size = float32(size.InexactFloat64())
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStore) Add(ctx context.Context, chatID int64, item Item) (int, error) {
+	var quantity int
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO cart_items (chat_id, sneaker_id, article, name, size, size_system, quantity, price, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, LEAST($7::int, $10::int), $8, $9)
+		ON CONFLICT (chat_id, sneaker_id) DO UPDATE SET
+			article = EXCLUDED.article,
+			name = EXCLUDED.name,
+			size = EXCLUDED.size,
+			size_system = EXCLUDED.size_system,
+			quantity = LEAST(cart_items.quantity + $7::int, $10::int),
+			price = EXCLUDED.price,
+			currency = EXCLUDED.currency
+		RETURNING quantity`,
+		chatID, item.SneakerID, item.Article, item.Name, float64(item.Size), item.SizeSystem,
+		item.Quantity, item.Price, item.Currency, MaxQuantity,
+	).Scan(&quantity)
+	if err != nil {
+		return 0, fmt.Errorf("add cart item %d: %w", item.SneakerID, err)
+	}
+	return quantity, nil
+}
+
+func (s *PostgresStore) SetQuantity(ctx context.Context, chatID int64, sneakerID int32, quantity int) (bool, error) {
+	query := `UPDATE cart_items SET quantity = LEAST($3::int, $4::int) WHERE chat_id = $1 AND sneaker_id = $2`
+	args := []any{chatID, sneakerID, quantity, MaxQuantity}
+	if quantity <= 0 {
+		query, args = `DELETE FROM cart_items WHERE chat_id = $1 AND sneaker_id = $2`, args[:2]
+	}
+
+	tag, err := s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("set quantity of cart item %d: %w", sneakerID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) Reprice(ctx context.Context, chatID int64, sneakerID int32, price decimal.Decimal, currency string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE cart_items SET price = $3, currency = $4 WHERE chat_id = $1 AND sneaker_id = $2`,
+		chatID, sneakerID, price, currency)
+	if err != nil {
+		return fmt.Errorf("reprice cart item %d: %w", sneakerID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) Clear(ctx context.Context, chatID int64) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM cart_items WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("clear cart of chat %d: %w", chatID, err)
+	}
+	return nil
+}
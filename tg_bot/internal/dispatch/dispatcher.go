@@ -0,0 +1,183 @@
+// Package dispatch раздаёт обновления Telegram обработчику: чаты обрабатываются
+// параллельно, обновления одного чата - строго по очереди.
+package dispatch
+
+import (
+	"context"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// seenWindow - сколько последних update_id помнится для отсева повторов.
+// Telegram повторяет доставку, если вебхук не ответил вовремя.
+const seenWindow = 10000
+
+// ChatQueueLimit - сколько обновлений может ждать в очереди одного чата. Сверх него
+// обновления отбрасываются: один чат, засыпающий бота сообщениями, не копит их в памяти.
+const ChatQueueLimit = 100
+
+// Handler обрабатывает одно обновление.
+type Handler func(ctx context.Context, update tgbotapi.Update)
+
+type Dispatcher struct {
+	handle Handler
+	log    *zap.Logger
+	// sem ограничивает число одновременно обрабатываемых обновлений
+	sem chan struct{}
+
+	// ctx обработчиков отменяется, только если Shutdown не дождался их завершения
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// queues - обновления, ждущие своей очереди в чате;
+	// наличие ключа означает, что у чата уже есть обработчик
+	queues map[int64][]tgbotapi.Update
+	seen   map[int]struct{}
+	order  []int
+	next   int
+	closed bool
+}
+
+func New(handle Handler, workers int, log *zap.Logger) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		handle: handle,
+		log:    log,
+		sem:    make(chan struct{}, max(workers, 1)),
+		ctx:    ctx,
+		cancel: cancel,
+		queues: make(map[int64][]tgbotapi.Update),
+		seen:   make(map[int]struct{}, seenWindow),
+		order:  make([]int, seenWindow),
+	}
+}
+
+// Dispatch ставит обновление в очередь его чата. Возвращает false для повторного
+// update_id, при переполненной очереди чата и после Shutdown.
+func (d *Dispatcher) Dispatch(update tgbotapi.Update) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return false
+	}
+	key := ChatKey(update)
+	if len(d.queues[key]) >= ChatQueueLimit {
+		d.log.Warn("chat queue is full, update dropped", zap.Int64("chat_id", key), zap.Int("update_id", update.UpdateID))
+		return false
+	}
+	if !d.remember(update.UpdateID) {
+		return false
+	}
+
+	if queue, busy := d.queues[key]; busy {
+		d.queues[key] = append(queue, update)
+		return true
+	}
+	d.queues[key] = nil
+	d.wg.Add(1)
+	go d.run(key, update)
+	return true
+}
+
+// remember запоминает update_id, вытесняя самый старый; false - уже обрабатывали.
+func (d *Dispatcher) remember(id int) bool {
+	if _, ok := d.seen[id]; ok {
+		return false
+	}
+	if old := d.order[d.next]; old != 0 {
+		delete(d.seen, old)
+	}
+	d.order[d.next] = id
+	d.next = (d.next + 1) % seenWindow
+	d.seen[id] = struct{}{}
+	return true
+}
+
+// run обрабатывает обновления чата, пока его очередь не опустеет.
+func (d *Dispatcher) run(key int64, update tgbotapi.Update) {
+	defer d.wg.Done()
+	for {
+		select {
+		case d.sem <- struct{}{}:
+		case <-d.ctx.Done():
+			// Shutdown не дождался очереди, её обновления уже отброшены
+			d.mu.Lock()
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		d.safeHandle(update)
+		<-d.sem
+
+		d.mu.Lock()
+		queue := d.queues[key]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			d.mu.Unlock()
+			return
+		}
+		update = queue[0]
+		d.queues[key] = queue[1:]
+		d.mu.Unlock()
+	}
+}
+
+func (d *Dispatcher) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.log.Error("ERROR: panic in update handler", zap.Int("update_id", update.UpdateID), zap.Any("panic", r), zap.Stack("stack"))
+		}
+	}()
+	d.handle(d.ctx, update)
+}
+
+// Shutdown перестаёт принимать обновления и ждёт обработки уже принятых.
+// Если ctx истёк раньше, контекст обработчиков отменяется, ещё не начатые
+// обновления отбрасываются, и Shutdown возвращается, не дожидаясь обработчиков.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		d.mu.Lock()
+		dropped := 0
+		for key, queue := range d.queues {
+			dropped += len(queue)
+			d.queues[key] = nil
+		}
+		d.mu.Unlock()
+		if dropped > 0 {
+			d.log.Warn("shutdown timed out, queued updates dropped", zap.Int("dropped", dropped))
+		}
+		return ctx.Err()
+	}
+}
+
+// ChatKey - ключ очереди обновления: чат, а для обновлений без чата
+// (inline-запросы, pre_checkout_query) - пользователь.
+func ChatKey(update tgbotapi.Update) int64 {
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+package dispatch_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kripst/krosovka/tg_bot/internal/dispatch"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func update(id int, chatID int64) tgbotapi.Update {
+	u := telegramtest.Message(chatID, "hello")
+	u.UpdateID = id
+	return u
+}
+
+// Обновления одного чата идут строго по порядку, разные чаты - параллельно.
+func TestDispatcher_PerChatOrder(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	var mu sync.Mutex
+	got := make(map[int64][]int)
+	release := make(chan struct{})
+	started := make(chan int64, 10)
+
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		chatID := u.Message.Chat.ID
+		if u.UpdateID == 1 {
+			// Первое обновление чата 1 висит, пока не обработается чат 2
+			started <- chatID
+			<-release
+		}
+		mu.Lock()
+		got[chatID] = append(got[chatID], u.UpdateID)
+		mu.Unlock()
+		if chatID == 2 {
+			started <- chatID
+		}
+	}, 4, zap.NewNop())
+
+	// --- Act ---
+	require.True(d.Dispatch(update(1, 1)))
+	require.Equal(int64(1), <-started)
+	require.True(d.Dispatch(update(2, 1)))
+	require.True(d.Dispatch(update(3, 2)))
+	require.True(d.Dispatch(update(4, 1)))
+
+	// --- Assert ---
+	require.Equal(int64(2), <-started, "чат 2 не ждёт занятый чат 1")
+	close(release)
+	require.NoError(d.Shutdown(context.Background()))
+
+	require.Equal([]int{1, 2, 4}, got[1])
+	require.Equal([]int{3}, got[2])
+}
+
+// Повторно доставленный update_id не обрабатывается.
+func TestDispatcher_Deduplicates(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	var mu sync.Mutex
+	calls := 0
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	}, 1, zap.NewNop())
+
+	// --- Act ---
+	first := d.Dispatch(update(10, 1))
+	second := d.Dispatch(update(10, 1))
+	require.NoError(d.Shutdown(context.Background()))
+
+	// --- Assert ---
+	require.True(first)
+	require.False(second)
+	require.Equal(1, calls)
+	require.False(d.Dispatch(update(11, 1)), "после Shutdown обновления не принимаются")
+}
+
+// Shutdown ждёт обработчики до дедлайна, затем отменяет их контекст.
+func TestDispatcher_ShutdownTimeout(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	started := make(chan struct{})
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		close(started)
+		<-ctx.Done()
+	}, 1, zap.NewNop())
+	d.Dispatch(update(1, 1))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// --- Act ---
+	err := d.Shutdown(ctx)
+
+	// --- Assert ---
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
+
+// Очередь чата ограничена ChatQueueLimit, лишние обновления отбрасываются,
+// другие чаты от этого не страдают.
+func TestDispatcher_ChatQueueLimit(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		if u.UpdateID == 1 {
+			started <- struct{}{}
+			<-release
+		}
+	}, 2, zap.NewNop())
+	require.True(d.Dispatch(update(1, 1)))
+	<-started
+
+	// --- Act ---
+	for id := 2; id <= dispatch.ChatQueueLimit+1; id++ {
+		require.True(d.Dispatch(update(id, 1)))
+	}
+	overflow := d.Dispatch(update(dispatch.ChatQueueLimit+2, 1))
+	other := d.Dispatch(update(dispatch.ChatQueueLimit+3, 2))
+
+	// --- Assert ---
+	require.False(overflow)
+	require.True(other)
+	close(release)
+	require.NoError(d.Shutdown(context.Background()))
+}
+
+// По истечении дедлайна Shutdown возвращается сразу, даже если обработчик не
+// смотрит на ctx, а обновления из очереди уже не обрабатываются.
+func TestDispatcher_ShutdownDropsQueue(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handled := make(chan int, 2)
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		if u.UpdateID == 1 {
+			started <- struct{}{}
+			<-release
+		}
+		handled <- u.UpdateID
+	}, 1, zap.NewNop())
+	d.Dispatch(update(1, 1))
+	<-started
+	d.Dispatch(update(2, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// --- Act ---
+	err := d.Shutdown(ctx)
+	close(release)
+
+	// --- Assert ---
+	require.ErrorIs(err, context.DeadlineExceeded)
+	require.Equal(1, <-handled)
+	select {
+	case id := <-handled:
+		require.Failf("queued update handled after shutdown", "update_id %d", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// Паника в обработчике не роняет бота и не блокирует очередь чата.
+func TestDispatcher_RecoversPanic(t *testing.T) {
+	require := require.New(t)
+	handled := make(chan int, 1)
+	d := dispatch.New(func(ctx context.Context, u tgbotapi.Update) {
+		if u.UpdateID == 1 {
+			panic("boom")
+		}
+		handled <- u.UpdateID
+	}, 1, zap.NewNop())
+
+	d.Dispatch(update(1, 1))
+	d.Dispatch(update(2, 1))
+
+	require.Equal(2, <-handled)
+	require.NoError(d.Shutdown(context.Background()))
+}
@@ -0,0 +1,254 @@
+// Package fsm хранит многошаговые диалоги бота: у каждого чата не больше одного
+// активного диалога, переходы между шагами описаны заранее, у шагов есть таймаут.
+// Состояние лежит во внешнем Store, поэтому диалог переживает рестарт и
+// продолжается на любой реплике бота.
+package fsm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// State - шаг диалога. None - диалога нет, переход в None завершает диалог.
+type State string
+
+// Event - то, что произошло в шаге: принят ответ, нажата кнопка и т.п.
+type Event string
+
+const None State = ""
+
+var (
+	// ErrNotFound - у чата нет активного диалога этого вида
+	ErrNotFound = errors.New("fsm: no active session")
+	// ErrExpired - диалог был, но истёк таймаут шага; запись уже удалена
+	ErrExpired = errors.New("fsm: session expired")
+	// ErrConflict - запись изменили параллельно (другая реплика или повтор обновления)
+	ErrConflict = errors.New("fsm: session was changed concurrently")
+	// ErrInvalidTransition - из текущего шага нет перехода по этому событию
+	ErrInvalidTransition = errors.New("fsm: invalid transition")
+)
+
+// Record - состояние диалога чата в хранилище.
+type Record struct {
+	ChatID int64
+	// Flow - вид диалога, у каждой Machine свой
+	Flow  string
+	State State
+	Data  json.RawMessage
+	// Version растёт при каждой записи, 0 - записи ещё нет
+	Version int64
+	// ExpiresAt - когда шаг истекает, нулевое значение - без таймаута
+	ExpiresAt time.Time
+}
+
+// Store хранит по одной записи на чат.
+type Store interface {
+	// Load возвращает запись чата или ErrNotFound.
+	Load(ctx context.Context, chatID int64) (Record, error)
+	// Save записывает rec с версией rec.Version+1, если в хранилище сейчас
+	// версия rec.Version (0 - записи нет), иначе возвращает ErrConflict.
+	Save(ctx context.Context, rec Record) error
+	// Delete удаляет запись чата; отсутствие записи не ошибка.
+	Delete(ctx context.Context, chatID int64) error
+	// DeleteExpired удаляет записи, истёкшие к моменту now, и возвращает их число.
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// Transition - переход From --Event--> To.
+type Transition struct {
+	From  State
+	Event Event
+	To    State
+}
+
+// Definition описывает диалог.
+type Definition struct {
+	Flow        string
+	Transitions []Transition
+	// Timeout - время на ответ в любом шаге, если для шага не задано своё; 0 - без таймаута
+	Timeout  time.Duration
+	Timeouts map[State]time.Duration
+}
+
+// Session - диалог конкретного чата с данными типа D.
+type Session[D any] struct {
+	ChatID    int64
+	State     State
+	Data      D
+	ExpiresAt time.Time
+
+	version int64
+}
+
+// Machine ведёт диалоги одного вида поверх Store.
+type Machine[D any] struct {
+	def   Definition
+	store Store
+	next  map[State]map[Event]State
+	now   func() time.Time
+}
+
+// New проверяет описание диалога. Ошибка в описании - ошибка программиста, поэтому паника.
+func New[D any](store Store, def Definition) *Machine[D] {
+	if def.Flow == "" {
+		panic("fsm: empty flow name")
+	}
+
+	next := make(map[State]map[Event]State)
+	for _, t := range def.Transitions {
+		if t.From == None {
+			panic(fmt.Sprintf("fsm: %s: transition %q from None", def.Flow, t.Event))
+		}
+		if next[t.From] == nil {
+			next[t.From] = make(map[Event]State)
+		}
+		if _, ok := next[t.From][t.Event]; ok {
+			panic(fmt.Sprintf("fsm: %s: duplicate transition %s --%s-->", def.Flow, t.From, t.Event))
+		}
+		next[t.From][t.Event] = t.To
+	}
+
+	return &Machine[D]{def: def, store: store, next: next, now: time.Now}
+}
+
+// SetClock подменяет часы, для тестов таймаутов.
+func (m *Machine[D]) SetClock(now func() time.Time) {
+	m.now = now
+}
+
+// Flow - вид диалога.
+func (m *Machine[D]) Flow() string {
+	return m.def.Flow
+}
+
+// Start начинает диалог в шаге state. Активный диалог чата, любого вида, заменяется.
+func (m *Machine[D]) Start(ctx context.Context, chatID int64, state State, data D) (*Session[D], error) {
+	if _, ok := m.next[state]; !ok {
+		return nil, fmt.Errorf("%w: %s has no state %q", ErrInvalidTransition, m.def.Flow, state)
+	}
+
+	var version int64
+	rec, err := m.store.Load(ctx, chatID)
+	switch {
+	case err == nil:
+		version = rec.Version
+	case !errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+
+	s := &Session[D]{ChatID: chatID, State: state, Data: data, version: version}
+	if err := m.save(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load возвращает активный диалог чата. ErrNotFound - диалога этого вида нет,
+// ErrExpired - он истёк (запись при этом удаляется).
+func (m *Machine[D]) Load(ctx context.Context, chatID int64) (*Session[D], error) {
+	rec, err := m.store.Load(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("load session: %w", err)
+	}
+	if rec.Flow != m.def.Flow {
+		return nil, ErrNotFound
+	}
+
+	if !rec.ExpiresAt.IsZero() && !m.now().Before(rec.ExpiresAt) {
+		if err := m.store.Delete(ctx, chatID); err != nil {
+			return nil, fmt.Errorf("delete expired session: %w", err)
+		}
+		return nil, ErrExpired
+	}
+
+	s := &Session[D]{ChatID: chatID, State: rec.State, ExpiresAt: rec.ExpiresAt, version: rec.Version}
+	if err := json.Unmarshal(rec.Data, &s.Data); err != nil {
+		return nil, fmt.Errorf("decode %s session data: %w", m.def.Flow, err)
+	}
+	return s, nil
+}
+
+// Fire переводит диалог по событию и сохраняет его вместе с s.Data.
+// Переход в None завершает диалог.
+func (m *Machine[D]) Fire(ctx context.Context, s *Session[D], event Event) error {
+	to, ok := m.next[s.State][event]
+	if !ok {
+		return fmt.Errorf("%w: %s: %s --%s-->", ErrInvalidTransition, m.def.Flow, s.State, event)
+	}
+
+	if to == None {
+		s.State = None
+		return m.Finish(ctx, s.ChatID)
+	}
+
+	prev := s.State
+	s.State = to
+	if err := m.save(ctx, s); err != nil {
+		s.State = prev
+		return err
+	}
+	return nil
+}
+
+// Can сообщает, есть ли из текущего шага переход по событию.
+func (m *Machine[D]) Can(s *Session[D], event Event) bool {
+	_, ok := m.next[s.State][event]
+	return ok
+}
+
+// Save сохраняет s.Data без смены шага; таймаут шага отсчитывается заново.
+func (m *Machine[D]) Save(ctx context.Context, s *Session[D]) error {
+	return m.save(ctx, s)
+}
+
+// Finish завершает диалог чата.
+func (m *Machine[D]) Finish(ctx context.Context, chatID int64) error {
+	if err := m.store.Delete(ctx, chatID); err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	return nil
+}
+
+func (m *Machine[D]) save(ctx context.Context, s *Session[D]) error {
+	data, err := json.Marshal(s.Data)
+	if err != nil {
+		return fmt.Errorf("encode %s session data: %w", m.def.Flow, err)
+	}
+
+	var expiresAt time.Time
+	if timeout := m.timeout(s.State); timeout > 0 {
+		expiresAt = m.now().Add(timeout)
+	}
+
+	rec := Record{
+		ChatID:    s.ChatID,
+		Flow:      m.def.Flow,
+		State:     s.State,
+		Data:      data,
+		Version:   s.version,
+		ExpiresAt: expiresAt,
+	}
+	if err := m.store.Save(ctx, rec); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return err
+		}
+		return fmt.Errorf("save session: %w", err)
+	}
+
+	s.version++
+	s.ExpiresAt = expiresAt
+	return nil
+}
+
+func (m *Machine[D]) timeout(state State) time.Duration {
+	if timeout, ok := m.def.Timeouts[state]; ok {
+		return timeout
+	}
+	return m.def.Timeout
+}
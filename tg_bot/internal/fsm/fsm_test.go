@@ -0,0 +1,219 @@
+package fsm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/fsm"
+	"github.com/kripst/krosovka/tg_bot/internal/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	stateName  fsm.State = "name"
+	statePrice fsm.State = "price"
+
+	eventNext   fsm.Event = "next"
+	eventCancel fsm.Event = "cancel"
+)
+
+type draft struct {
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+func testDefinition() fsm.Definition {
+	return fsm.Definition{
+		Flow: "add",
+		Transitions: []fsm.Transition{
+			{From: stateName, Event: eventNext, To: statePrice},
+			{From: statePrice, Event: eventNext, To: fsm.None},
+			{From: statePrice, Event: eventCancel, To: fsm.None},
+		},
+		Timeout:  time.Minute,
+		Timeouts: map[fsm.State]time.Duration{statePrice: time.Hour},
+	}
+}
+
+// Диалог проходит шаги по событиям, данные сохраняются между загрузками.
+func TestMachine_Transitions(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	store := fsm.NewMemoryStore()
+	m := fsm.New[draft](store, testDefinition())
+
+	// --- Act ---
+	s, err := m.Start(ctx, 1, stateName, draft{})
+	require.NoError(err)
+	s.Data.Name = "Samba"
+	require.NoError(m.Fire(ctx, s, eventNext))
+
+	// --- Assert ---
+	loaded, err := m.Load(ctx, 1)
+	require.NoError(err)
+	require.Equal(statePrice, loaded.State)
+	require.Equal("Samba", loaded.Data.Name)
+
+	// Перехода по next из шага, которого нет в описании, не бывает
+	err = m.Fire(ctx, &fsm.Session[draft]{ChatID: 1, State: "unknown"}, eventNext)
+	require.ErrorIs(err, fsm.ErrInvalidTransition)
+
+	// --- Act ---
+	require.NoError(m.Fire(ctx, loaded, eventNext))
+
+	// --- Assert ---
+	require.Equal(fsm.None, loaded.State)
+	_, err = m.Load(ctx, 1)
+	require.ErrorIs(err, fsm.ErrNotFound)
+}
+
+// Истёкший шаг не продолжается, таймаут у каждого шага свой.
+func TestMachine_Timeout(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := fsm.New[draft](fsm.NewMemoryStore(), testDefinition())
+	m.SetClock(func() time.Time { return now })
+
+	s, err := m.Start(ctx, 1, stateName, draft{})
+	require.NoError(err)
+	require.Equal(now.Add(time.Minute), s.ExpiresAt)
+	_, err = m.Start(ctx, 2, statePrice, draft{})
+	require.NoError(err)
+
+	// --- Act ---
+	now = now.Add(2 * time.Minute)
+
+	// --- Assert ---
+	_, err = m.Load(ctx, 1)
+	require.ErrorIs(err, fsm.ErrExpired)
+	_, err = m.Load(ctx, 1)
+	require.ErrorIs(err, fsm.ErrNotFound)
+	_, err = m.Load(ctx, 2)
+	require.NoError(err)
+}
+
+// Из двух параллельных изменений одного диалога проходит только первое.
+func TestMachine_Conflict(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	m := fsm.New[draft](fsm.NewMemoryStore(), testDefinition())
+	_, err := m.Start(ctx, 1, stateName, draft{})
+	require.NoError(err)
+	first, err := m.Load(ctx, 1)
+	require.NoError(err)
+	second, err := m.Load(ctx, 1)
+	require.NoError(err)
+
+	// --- Act ---
+	require.NoError(m.Fire(ctx, first, eventNext))
+	err = m.Fire(ctx, second, eventNext)
+
+	// --- Assert ---
+	require.ErrorIs(err, fsm.ErrConflict)
+	require.Equal(stateName, second.State)
+}
+
+// У чата один активный диалог: новый заменяет старый, чужой вид не виден.
+func TestMachine_Flows(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	store := fsm.NewMemoryStore()
+	add := fsm.New[draft](store, testDefinition())
+	other := testDefinition()
+	other.Flow = "edit"
+	edit := fsm.New[draft](store, other)
+
+	// --- Act ---
+	_, err := add.Start(ctx, 1, stateName, draft{Name: "old"})
+	require.NoError(err)
+	_, err = edit.Start(ctx, 1, statePrice, draft{Name: "new"})
+	require.NoError(err)
+
+	// --- Assert ---
+	_, err = add.Load(ctx, 1)
+	require.ErrorIs(err, fsm.ErrNotFound)
+	s, err := edit.Load(ctx, 1)
+	require.NoError(err)
+	require.Equal("new", s.Data.Name)
+}
+
+func TestNew_InvalidDefinition(t *testing.T) {
+	require := require.New(t)
+	def := testDefinition()
+	def.Transitions = append(def.Transitions, fsm.Transition{From: stateName, Event: eventNext, To: fsm.None})
+
+	require.Panics(func() { fsm.New[draft](fsm.NewMemoryStore(), def) })
+	require.Panics(func() { fsm.New[draft](fsm.NewMemoryStore(), fsm.Definition{}) })
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, fsm.NewMemoryStore())
+}
+
+// PostgreSQL проверяется, только если задан TG_TEST_POSTGRES_DSN.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TG_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TG_TEST_POSTGRES_DSN is not set")
+	}
+	pool, err := postgres.Connect(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	testStore(t, fsm.NewPostgresStore(pool))
+}
+
+// testStore - общие требования к любому Store.
+func testStore(t *testing.T, store fsm.Store) {
+	require := require.New(t)
+	ctx := context.Background()
+	chatID := time.Now().UnixNano()
+	t.Cleanup(func() { _ = store.Delete(ctx, chatID) })
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+
+	_, err := store.Load(ctx, chatID)
+	require.ErrorIs(err, fsm.ErrNotFound)
+
+	rec := fsm.Record{ChatID: chatID, Flow: "add", State: stateName, Data: []byte(`{"name":"a"}`), ExpiresAt: expiresAt}
+	require.NoError(store.Save(ctx, rec))
+	require.ErrorIs(store.Save(ctx, rec), fsm.ErrConflict)
+
+	loaded, err := store.Load(ctx, chatID)
+	require.NoError(err)
+	require.Equal(int64(1), loaded.Version)
+	require.Equal(stateName, loaded.State)
+	require.JSONEq(`{"name":"a"}`, string(loaded.Data))
+	require.True(expiresAt.Equal(loaded.ExpiresAt))
+
+	loaded.State = statePrice
+	loaded.ExpiresAt = time.Time{}
+	require.NoError(store.Save(ctx, loaded))
+	require.True(errors.Is(store.Save(ctx, loaded), fsm.ErrConflict))
+
+	loaded, err = store.Load(ctx, chatID)
+	require.NoError(err)
+	require.Equal(int64(2), loaded.Version)
+	require.True(loaded.ExpiresAt.IsZero())
+
+	// Без таймаута запись не истекает, с истёкшим - удаляется
+	n, err := store.DeleteExpired(ctx, time.Now().Add(24*time.Hour))
+	require.NoError(err)
+	require.Zero(n)
+	loaded.ExpiresAt = time.Now().Add(-time.Second)
+	require.NoError(store.Save(ctx, loaded))
+	n, err = store.DeleteExpired(ctx, time.Now())
+	require.NoError(err)
+	require.GreaterOrEqual(n, 1)
+	_, err = store.Load(ctx, chatID)
+	require.ErrorIs(err, fsm.ErrNotFound)
+
+	require.NoError(store.Delete(ctx, chatID))
+}
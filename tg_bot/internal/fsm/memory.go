@@ -0,0 +1,65 @@
+package fsm
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит диалоги в памяти процесса: годится для одной реплики и тестов.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[int64]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[int64]Record)}
+}
+
+func (s *MemoryStore) Load(ctx context.Context, chatID int64) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.records[chatID]
+	if !ok {
+		return Record{}, ErrNotFound
+	}
+	rec.Data = slices.Clone(rec.Data)
+	return rec, nil
+}
+
+func (s *MemoryStore) Save(ctx context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.records[rec.ChatID].Version != rec.Version {
+		return ErrConflict
+	}
+	rec.Version++
+	rec.Data = slices.Clone(rec.Data)
+	s.records[rec.ChatID] = rec
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, chatID)
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for chatID, rec := range s.records {
+		if !rec.ExpiresAt.IsZero() && !now.Before(rec.ExpiresAt) {
+			delete(s.records, chatID)
+			n++
+		}
+	}
+	return n, nil
+}
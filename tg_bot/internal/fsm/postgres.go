@@ -0,0 +1,89 @@
+package fsm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore хранит диалоги в PostgreSQL, общий для всех реплик бота.
+// Схему создаёт пакет migrate.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) Load(ctx context.Context, chatID int64) (Record, error) {
+	rec := Record{ChatID: chatID}
+	var expiresAt *time.Time
+	err := s.pool.QueryRow(ctx, `
+		SELECT flow, state, data, version, expires_at
+		FROM fsm_sessions WHERE chat_id = $1`, chatID,
+	).Scan(&rec.Flow, &rec.State, &rec.Data, &rec.Version, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Record{}, ErrNotFound
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("select session %d: %w", chatID, err)
+	}
+
+	if expiresAt != nil {
+		rec.ExpiresAt = *expiresAt
+	}
+	return rec, nil
+}
+
+func (s *PostgresStore) Save(ctx context.Context, rec Record) error {
+	var expiresAt *time.Time
+	if !rec.ExpiresAt.IsZero() {
+		expiresAt = &rec.ExpiresAt
+	}
+
+	// Версия проверяется в самом запросе: из двух реплик запишет только одна
+	query := `
+		UPDATE fsm_sessions
+		SET flow = $2, state = $3, data = $4, version = version + 1, expires_at = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE chat_id = $1 AND version = $6`
+	if rec.Version == 0 {
+		query = `
+			INSERT INTO fsm_sessions (chat_id, flow, state, data, version, expires_at)
+			VALUES ($1, $2, $3, $4, 1, $5)
+			ON CONFLICT (chat_id) DO NOTHING`
+	}
+
+	args := []any{rec.ChatID, rec.Flow, string(rec.State), []byte(rec.Data), expiresAt}
+	if rec.Version != 0 {
+		args = append(args, rec.Version)
+	}
+
+	tag, err := s.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("save session %d: %w", rec.ChatID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, chatID int64) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM fsm_sessions WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("delete session %d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM fsm_sessions WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,31 @@
+package fsm
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RunSweeper раз в interval удаляет истёкшие диалоги, пока не отменён ctx.
+// Истёкший диалог и так не продолжится, чистка только не даёт хранилищу расти.
+func RunSweeper(ctx context.Context, store Store, interval time.Duration, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := store.DeleteExpired(ctx, now)
+			if err != nil {
+				log.Error("ERROR: delete expired dialogs", zap.Error(err))
+				continue
+			}
+			if n > 0 {
+				log.Debug("expired dialogs deleted", zap.Int("count", n))
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package inventory
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// APIKeyHeader - metadata, в которой inventory_service ждёт статический ключ.
+const APIKeyHeader = "x-api-key"
+
+// ActorHeader - metadata с пользователем, от имени которого бот вызывает inventory_service.
+// Сервис записывает его в аудит, если ключу бота разрешено forwards_actor.
+const ActorHeader = "x-actor"
+
+type actorKey struct{}
+
+// WithActor помечает RPC, сделанные с ctx, пользователем actor, например "tg:123456789".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor - пользователь из WithActor или пустая строка.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// Dial подключается к inventory_service. Ключ добавляется к каждому RPC, если задан,
+// пользователь из WithActor - в x-actor, а RPC без дедлайна получают cfg.Timeout. Каждый RPC пишет клиентский спан и
+// передаёт контекст трейса серверу.
+func Dial(cfg config.InventoryConfig) (*grpc.ClientConn, pb.InventoryServiceClient, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS.Enabled {
+		tlsConfig, err := loadTLS(cfg.TLS)
+		if err != nil {
+			return nil, nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	interceptors := []grpc.UnaryClientInterceptor{timeoutInterceptor(cfg.Timeout), actorInterceptor()}
+	if cfg.APIKey != "" {
+		interceptors = append(interceptors, apiKeyInterceptor(cfg.APIKey))
+	}
+
+	conn, err := grpc.NewClient(cfg.Addr,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial inventory %s: %w", cfg.Addr, err)
+	}
+	return conn, pb.NewInventoryServiceClient(conn), nil
+}
+
+func loadTLS(cfg config.TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read inventory CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("inventory CA %s: no certificates found", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load inventory client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func timeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func actorInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if actor := Actor(ctx); actor != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, ActorHeader, actor)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func apiKeyInterceptor(apiKey string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, APIKeyHeader, apiKey)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
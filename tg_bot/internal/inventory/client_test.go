@@ -0,0 +1,126 @@
+package inventory_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/config"
+	"github.com/kripst/krosovka/tg_bot/internal/inventory"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tracedInventory запоминает контекст трейса и metadata, с которыми пришёл RPC.
+type tracedInventory struct {
+	pb.UnimplementedInventoryServiceServer
+	got trace.SpanContext
+	md  metadata.MD
+}
+
+func (s *tracedInventory) GetSneakers(ctx context.Context, in *pb.GetSneakersRequest) (*pb.GetSneakersResponse, error) {
+	s.got = trace.SpanContextFromContext(ctx)
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	return &pb.GetSneakersResponse{StatusCode: 200}, nil
+}
+
+// serve запускает inventory_service на свободном порту и подключает к нему клиент.
+func serve(t *testing.T, service *tracedInventory, opts ...grpc.ServerOption) pb.InventoryServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(opts...)
+	pb.RegisterInventoryServiceServer(server, service)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, client, err := inventory.Dial(config.InventoryConfig{Addr: lis.Addr().String(), APIKey: "bot-key", Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return client
+}
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return recorder
+}
+
+// RPC бота пишет клиентский спан, и inventory_service продолжает тот же трейс.
+func TestDial_PropagatesTrace(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	recorder := setupRecorder(t)
+
+	service := &tracedInventory{}
+	client := serve(t, service, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "telegram command /catalog")
+
+	// --- Act ---
+	_, err := client.GetSneakers(ctx, &pb.GetSneakersRequest{})
+	parent.End()
+
+	// --- Assert ---
+	require.NoError(err)
+	traceID := parent.SpanContext().TraceID()
+	require.Equal(traceID, service.got.TraceID(), "сервер получил контекст трейса бота")
+
+	var clientSpan, serverSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.SpanKind() {
+		case trace.SpanKindClient:
+			clientSpan = span
+		case trace.SpanKindServer:
+			serverSpan = span
+		}
+	}
+	require.NotNil(clientSpan)
+	require.Equal("inventoryservice.InventoryService/GetSneakers", clientSpan.Name())
+	require.Equal(traceID, clientSpan.SpanContext().TraceID())
+	require.Equal(parent.SpanContext().SpanID(), clientSpan.Parent().SpanID())
+	require.NotNil(serverSpan)
+	require.Equal(clientSpan.SpanContext().SpanID(), serverSpan.Parent().SpanID())
+}
+
+// Пользователь из WithActor уходит в x-actor рядом с ключом бота, без него заголовка нет.
+func TestDial_ForwardsActor(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	service := &tracedInventory{}
+	client := serve(t, service)
+
+	// --- Act ---
+	_, err := client.GetSneakers(inventory.WithActor(context.Background(), "tg:100"), &pb.GetSneakersRequest{})
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal([]string{"bot-key"}, service.md.Get(inventory.APIKeyHeader))
+	require.Equal([]string{"tg:100"}, service.md.Get(inventory.ActorHeader))
+
+	// --- Act ---
+	_, err = client.GetSneakers(context.Background(), &pb.GetSneakersRequest{})
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Empty(service.md.Get(inventory.ActorHeader))
+}
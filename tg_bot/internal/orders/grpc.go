@@ -0,0 +1,140 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/shopspring/decimal"
+)
+
+// GRPCService оформляет заказы в OrdersService из inventory_service. Цены позиций
+// передаются как ожидаемые, поэтому заказ не пройдёт, если цена успела измениться.
+type GRPCService struct {
+	client pb.OrdersServiceClient
+}
+
+func NewGRPCService(client pb.OrdersServiceClient) *GRPCService {
+	return &GRPCService{client: client}
+}
+
+// CustomerID - ключ покупателя в сервисе заказов.
+func CustomerID(chatID int64) string {
+	return "tg:" + strconv.FormatInt(chatID, 10)
+}
+
+func (s *GRPCService) Create(ctx context.Context, order Order) (Order, error) {
+	req := &pb.CreateOrderRequest{
+		Customer: &pb.Customer{
+			CustomerId: CustomerID(order.Customer.ChatID),
+			Name:       order.Customer.Name,
+			Phone:      order.Customer.Phone,
+			Address:    order.Customer.Address,
+		},
+	}
+	for _, line := range order.Lines {
+		req.Lines = append(req.Lines, &pb.OrderLine{
+			Article:    line.Article,
+			Size:       line.Size,
+			SizeSystem: line.SizeSystem,
+			Quantity:   int32(line.Quantity),
+			Price:      toMoney(line.Price, order.Currency),
+		})
+	}
+
+	resp, err := s.client.CreateOrder(ctx, req)
+	if err != nil {
+		return Order{}, fmt.Errorf("create order: %w", err)
+	}
+	if err := responseError("create order", resp); err != nil {
+		return Order{}, err
+	}
+	created := fromProto(resp.GetOrder())
+	created.Customer = order.Customer
+	return created, nil
+}
+
+func (s *GRPCService) Get(ctx context.Context, id int64) (Order, error) {
+	resp, err := s.client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: id})
+	if err != nil {
+		return Order{}, fmt.Errorf("get order: %w", err)
+	}
+	if err := responseError("get order", resp); err != nil {
+		return Order{}, err
+	}
+	return fromProto(resp.GetOrder()), nil
+}
+
+func (s *GRPCService) Transition(ctx context.Context, id int64, from, to Status, reason string) (Order, error) {
+	resp, err := s.client.TransitionOrder(ctx, &pb.TransitionOrderRequest{
+		OrderId:    id,
+		Status:     string(to),
+		FromStatus: string(from),
+		Reason:     reason,
+	})
+	if err != nil {
+		return Order{}, fmt.Errorf("transition order: %w", err)
+	}
+	if err := responseError("transition order", resp); err != nil {
+		return Order{}, err
+	}
+	return fromProto(resp.GetOrder()), nil
+}
+
+// responseError переводит код ответа сервиса в ошибки пакета.
+func responseError(op string, resp *pb.OrderResponse) error {
+	switch resp.GetStatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, resp.GetErrorMessage())
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, resp.GetErrorMessage())
+	default:
+		return fmt.Errorf("%s: status %d: %s", op, resp.GetStatusCode(), resp.GetErrorMessage())
+	}
+}
+
+// fromProto - заказ из ответа сервиса.
+func fromProto(in *pb.Order) Order {
+	order := Order{
+		ID:       in.GetOrderId(),
+		Customer: customerFromProto(in.GetCustomer()),
+		Currency: in.GetTotal().GetCurrencyCode(),
+		Total:    fromMoney(in.GetTotal()),
+		Status:   Status(in.GetStatus()),
+	}
+	order.CreatedAt, _ = time.Parse(time.RFC3339, in.GetCreatedAt())
+	for _, line := range in.GetLines() {
+		order.Lines = append(order.Lines, Line{
+			SneakerID:  line.GetSneakerId(),
+			Article:    line.GetArticle(),
+			Name:       line.GetSneakerName(),
+			Size:       line.GetSize(),
+			SizeSystem: line.GetSizeSystem(),
+			Quantity:   int(line.GetQuantity()),
+			Price:      fromMoney(line.GetPrice()),
+		})
+	}
+	return order
+}
+
+// customerFromProto - покупатель заказа; ChatID восстанавливается из ключа CustomerID.
+func customerFromProto(in *pb.Customer) Customer {
+	chatID, _ := strconv.ParseInt(strings.TrimPrefix(in.GetCustomerId(), "tg:"), 10, 64)
+	return Customer{ChatID: chatID, Name: in.GetName(), Phone: in.GetPhone(), Address: in.GetAddress()}
+}
+
+func toMoney(amount decimal.Decimal, currency string) *pb.Money {
+	units := amount.IntPart()
+	nanos := amount.Sub(decimal.NewFromInt(units)).Shift(9).IntPart()
+	return &pb.Money{CurrencyCode: currency, Units: units, Nanos: int32(nanos)}
+}
+
+func fromMoney(m *pb.Money) decimal.Decimal {
+	return decimal.New(m.GetUnits(), 0).Add(decimal.New(int64(m.GetNanos()), -9))
+}
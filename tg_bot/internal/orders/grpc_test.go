@@ -0,0 +1,122 @@
+package orders_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	pb "github.com/kripst/krosovka/inventory_service/proto"
+	"github.com/kripst/krosovka/tg_bot/internal/orders"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+type fakeOrdersClient struct {
+	pb.OrdersServiceClient
+	req        *pb.CreateOrderRequest
+	transition *pb.TransitionOrderRequest
+	resp       *pb.OrderResponse
+}
+
+func (f *fakeOrdersClient) CreateOrder(ctx context.Context, in *pb.CreateOrderRequest, opts ...grpc.CallOption) (*pb.OrderResponse, error) {
+	f.req = in
+	return f.resp, nil
+}
+
+func (f *fakeOrdersClient) GetOrder(ctx context.Context, in *pb.GetOrderRequest, opts ...grpc.CallOption) (*pb.OrderResponse, error) {
+	return f.resp, nil
+}
+
+func (f *fakeOrdersClient) TransitionOrder(ctx context.Context, in *pb.TransitionOrderRequest, opts ...grpc.CallOption) (*pb.OrderResponse, error) {
+	f.transition = in
+	return f.resp, nil
+}
+
+func testOrder() orders.Order {
+	return orders.Order{
+		Customer: orders.Customer{ChatID: 7, Name: "Иван", Phone: "+79991234567", Address: "Москва"},
+		Lines: []orders.Line{{SneakerID: 1, Article: "ART-001", Name: "Air Max 90", Size: 42.5, SizeSystem: "EU",
+			Quantity: 2, Price: decimal.RequireFromString("9990.5")}},
+		Currency: "RUB",
+		Total:    decimal.RequireFromString("19981"),
+	}
+}
+
+// Заказ уходит с ожидаемыми ценами, номер и сумма берутся из ответа сервиса.
+func TestGRPCService_Create(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	client := &fakeOrdersClient{resp: &pb.OrderResponse{
+		StatusCode: http.StatusOK,
+		Order: &pb.Order{
+			OrderId:   42,
+			Total:     &pb.Money{CurrencyCode: "RUB", Units: 19981},
+			Status:    "created",
+			CreatedAt: "2026-10-19T12:00:00Z",
+			Lines: []*pb.OrderLine{{Article: "ART-001", Size: 42.5, SizeSystem: "EU", Quantity: 2, SneakerId: 1,
+				SneakerName: "Air Max 90", Price: &pb.Money{CurrencyCode: "RUB", Units: 9990, Nanos: 500_000_000}}},
+		},
+	}}
+
+	// --- Act ---
+	order, err := orders.NewGRPCService(client).Create(context.Background(), testOrder())
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal("tg:7", client.req.GetCustomer().GetCustomerId())
+	require.Equal(&pb.Money{CurrencyCode: "RUB", Units: 9990, Nanos: 500_000_000}, client.req.GetLines()[0].GetPrice())
+	require.Equal(int64(42), order.ID)
+	require.Equal(int64(7), order.Customer.ChatID)
+	require.Equal("19981", order.Total.String())
+	require.Equal("9990.5", order.Lines[0].Price.String())
+	require.Equal(2026, order.CreatedAt.Year())
+}
+
+// 409 от сервиса - расхождение с каталогом, его бот показывает покупателю.
+func TestGRPCService_Conflict(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	client := &fakeOrdersClient{resp: &pb.OrderResponse{
+		StatusCode:   http.StatusConflict,
+		ErrorMessage: "order conflicts with the catalog: price of ART-001 is 10990.00 RUB, not 9990.50 RUB",
+	}}
+
+	// --- Act ---
+	_, err := orders.NewGRPCService(client).Create(context.Background(), testOrder())
+
+	// --- Assert ---
+	require.ErrorIs(err, orders.ErrConflict)
+}
+
+// Смена статуса передаёт исходный статус, покупатель восстанавливается из ключа tg:<chat_id>.
+func TestGRPCService_Transition(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	client := &fakeOrdersClient{resp: &pb.OrderResponse{
+		StatusCode: http.StatusOK,
+		Order: &pb.Order{
+			OrderId:  42,
+			Customer: &pb.Customer{CustomerId: "tg:7", Name: "Иван"},
+			Total:    &pb.Money{CurrencyCode: "RUB", Units: 19981},
+			Status:   "paid",
+		},
+	}}
+	service := orders.NewGRPCService(client)
+
+	// --- Act ---
+	order, err := service.Transition(context.Background(), 42, orders.StatusCreated, orders.StatusPaid, "payment charge-1")
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal(&pb.TransitionOrderRequest{OrderId: 42, Status: "paid", FromStatus: "created", Reason: "payment charge-1"}, client.transition)
+	require.Equal(orders.StatusPaid, order.Status)
+	require.Equal(int64(7), order.Customer.ChatID)
+
+	// --- Act ---
+	client.resp = &pb.OrderResponse{StatusCode: http.StatusNotFound, ErrorMessage: "order not found"}
+	_, err = service.Get(context.Background(), 43)
+
+	// --- Assert ---
+	require.ErrorIs(err, orders.ErrNotFound)
+}
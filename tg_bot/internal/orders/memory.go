@@ -0,0 +1,65 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryService хранит заказы в памяти процесса и не сверяет цены с каталогом:
+// годится для тестов и разработки без сервиса заказов.
+type MemoryService struct {
+	mu     sync.Mutex
+	orders []Order
+}
+
+func NewMemoryService() *MemoryService {
+	return &MemoryService{}
+}
+
+func (s *MemoryService) Create(ctx context.Context, order Order) (Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order.ID = int64(len(s.orders) + 1)
+	order.Status = StatusCreated
+	order.CreatedAt = time.Now()
+	order.Lines = slices.Clone(order.Lines)
+	s.orders = append(s.orders, order)
+	return order, nil
+}
+
+func (s *MemoryService) Get(ctx context.Context, id int64) (Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > int64(len(s.orders)) {
+		return Order{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return s.orders[id-1], nil
+}
+
+// Transition проверяет только исходный статус, граф переходов сверяет сервис заказов.
+func (s *MemoryService) Transition(ctx context.Context, id int64, from, to Status, reason string) (Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > int64(len(s.orders)) {
+		return Order{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	order := &s.orders[id-1]
+	if order.Status != from {
+		return Order{}, fmt.Errorf("%w: order %d is %s, not %s", ErrConflict, id, order.Status, from)
+	}
+	order.Status = to
+	return *order, nil
+}
+
+// Orders - все принятые заказы.
+func (s *MemoryService) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.orders)
+}
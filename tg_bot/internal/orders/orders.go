@@ -0,0 +1,68 @@
+// Package orders - заказы, оформленные в боте.
+package orders
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrNotFound - заказа с таким номером нет.
+	ErrNotFound = errors.New("order not found")
+	// ErrConflict - сервис заказов отклонил запрос: товара больше нет, цена
+	// изменилась после проверки корзины или заказ уже в другом статусе.
+	ErrConflict = errors.New("order conflicts with its current state")
+)
+
+// Status - статус заказа: created -> paid -> shipped -> delivered, до отправки
+// заказ можно отменить (cancelled).
+type Status string
+
+const (
+	StatusCreated   Status = "created"
+	StatusPaid      Status = "paid"
+	StatusCancelled Status = "cancelled"
+)
+
+// Customer - кто и куда заказал.
+type Customer struct {
+	ChatID  int64
+	Name    string
+	Phone   string
+	Address string
+}
+
+// Line - позиция заказа с ценой на момент оформления.
+type Line struct {
+	SneakerID  int32
+	Article    string
+	Name       string
+	Size       float32
+	SizeSystem string
+	Quantity   int
+	Price      decimal.Decimal
+}
+
+type Order struct {
+	ID       int64
+	Customer Customer
+	Lines    []Line
+	Currency string
+	Total    decimal.Decimal
+	// Status и CreatedAt заполняет Service
+	Status    Status
+	CreatedAt time.Time
+}
+
+// Service принимает оформленные заказы и ведёт их статус.
+type Service interface {
+	// Create сохраняет заказ и возвращает его с номером.
+	Create(ctx context.Context, order Order) (Order, error)
+	// Get возвращает заказ по номеру или ErrNotFound.
+	Get(ctx context.Context, id int64) (Order, error)
+	// Transition переводит заказ из статуса from в to. Если заказ уже не в from, возвращает ErrConflict.
+	Transition(ctx context.Context, id int64, from, to Status, reason string) (Order, error)
+}
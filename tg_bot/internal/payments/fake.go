@@ -0,0 +1,184 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Outcome - чем заканчивается оплата у FakeProvider.
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// FakeConfig - поведение FakeProvider.
+type FakeConfig struct {
+	// Outcome - ответ на каждую проверку оплаты до списания, пустой - success
+	Outcome Outcome
+	// Delay - задержка перед каждым ответом, как у сетевого шлюза
+	Delay time.Duration
+	// ProviderToken уходит в sendInvoice; для Telegram подходит тестовый токен из @BotFather
+	ProviderToken string
+}
+
+// FakeProvider - локальный провайдер без настоящего шлюза: хранит счета в памяти
+// и принимает или отклоняет оплату по настройке. Годится для тестов и разработки
+// на одной реплике: счёт, выставленный до перезапуска или другим процессом, ему
+// неизвестен, и оплата по нему не засчитается.
+type FakeProvider struct {
+	mu       sync.Mutex
+	cfg      FakeConfig
+	seq      int
+	invoices map[string]Invoice
+	payments map[string]Payment
+}
+
+func NewFakeProvider(cfg FakeConfig) *FakeProvider {
+	if cfg.Outcome == "" {
+		cfg.Outcome = OutcomeSuccess
+	}
+	return &FakeProvider{
+		cfg:      cfg,
+		invoices: make(map[string]Invoice),
+		payments: make(map[string]Payment),
+	}
+}
+
+// SetOutcome меняет итог следующих оплат.
+func (p *FakeProvider) SetOutcome(outcome Outcome) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg.Outcome = outcome
+}
+
+// SetDelay меняет задержку ответов.
+func (p *FakeProvider) SetDelay(delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cfg.Delay = delay
+}
+
+func (p *FakeProvider) CreateInvoice(ctx context.Context, req InvoiceRequest) (Invoice, error) {
+	if err := p.wait(ctx); err != nil {
+		return Invoice{}, err
+	}
+	if !req.Amount.IsPositive() || req.Currency == "" {
+		return Invoice{}, fmt.Errorf("invalid invoice amount %s %q", req.Amount, req.Currency)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	invoice := Invoice{
+		ID:            fmt.Sprintf("fake-%d", p.seq),
+		OrderID:       req.OrderID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		ProviderToken: p.cfg.ProviderToken,
+	}
+	p.invoices[invoice.ID] = invoice
+	return invoice, nil
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, c Checkout) error {
+	if err := p.wait(ctx); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	invoice, ok := p.invoices[c.InvoiceID]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: %s", ErrUnknownInvoice, c.InvoiceID)
+	case c.Currency != invoice.Currency || !c.Amount.Equal(invoice.Amount):
+		return fmt.Errorf("%w: %s %s, invoice is for %s %s", ErrDeclined, c.Amount, c.Currency, invoice.Amount, invoice.Currency)
+	case p.cfg.Outcome == OutcomeFailure:
+		return fmt.Errorf("%w by the fake provider", ErrDeclined)
+	}
+	return nil
+}
+
+func (p *FakeProvider) HandleCallback(ctx context.Context, cb Callback) (Payment, error) {
+	if err := p.wait(ctx); err != nil {
+		return Payment{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	invoice, ok := p.invoices[cb.InvoiceID]
+	if !ok {
+		return Payment{}, fmt.Errorf("%w: %s", ErrUnknownInvoice, cb.InvoiceID)
+	}
+	if prev, ok := p.payments[cb.InvoiceID]; ok && prev.Status != StatusFailed {
+		return prev, nil
+	}
+
+	payment := Payment{
+		InvoiceID: invoice.ID,
+		OrderID:   invoice.OrderID,
+		Status:    StatusSucceeded,
+		Amount:    cb.Amount,
+		Currency:  cb.Currency,
+		ChargeID:  cb.ProviderChargeID,
+	}
+	if payment.ChargeID == "" {
+		payment.ChargeID = "charge-" + invoice.ID
+	}
+	if cb.Currency != invoice.Currency || !cb.Amount.Equal(invoice.Amount) {
+		payment.Status = StatusFailed
+		payment.FailureReason = fmt.Sprintf("paid %s %s, invoice is for %s %s", cb.Amount, cb.Currency, invoice.Amount, invoice.Currency)
+	}
+	p.payments[invoice.ID] = payment
+	return payment, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, invoiceID string) (Payment, error) {
+	if err := p.wait(ctx); err != nil {
+		return Payment{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.invoices[invoiceID]; !ok {
+		return Payment{}, fmt.Errorf("%w: %s", ErrUnknownInvoice, invoiceID)
+	}
+	payment, ok := p.payments[invoiceID]
+	if !ok || payment.Status == StatusRefunded {
+		return Payment{}, fmt.Errorf("%w: invoice %s", ErrNotRefundable, invoiceID)
+	}
+	payment.Status = StatusRefunded
+	p.payments[invoiceID] = payment
+	return payment, nil
+}
+
+// Payment - итог оплаты счёта invoiceID, false - оплаты ещё не было.
+func (p *FakeProvider) Payment(invoiceID string) (Payment, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[invoiceID]
+	return payment, ok
+}
+
+// wait выдерживает задержку ответа; отмена ctx прерывает ожидание.
+func (p *FakeProvider) wait(ctx context.Context) error {
+	p.mu.Lock()
+	delay := p.cfg.Delay
+	p.mu.Unlock()
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("fake provider: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,145 @@
+package payments_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/payments"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func invoiceFor(t *testing.T, p *payments.FakeProvider, orderID int64, amount string) payments.Invoice {
+	t.Helper()
+	invoice, err := p.CreateInvoice(context.Background(), payments.InvoiceRequest{
+		OrderID: orderID, Amount: decimal.RequireFromString(amount), Currency: "RUB",
+	})
+	require.NoError(t, err)
+	return invoice
+}
+
+func checkout(invoice payments.Invoice) payments.Checkout {
+	return payments.Checkout{InvoiceID: invoice.ID, Amount: invoice.Amount, Currency: invoice.Currency}
+}
+
+func callback(invoice payments.Invoice) payments.Callback {
+	return payments.Callback{InvoiceID: invoice.ID, Amount: invoice.Amount, Currency: invoice.Currency, ProviderChargeID: "prov-1"}
+}
+
+// Оплата счёта проходит, повторное уведомление возвращает тот же платёж, деньги можно вернуть один раз.
+func TestFakeProvider_Success(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	p := payments.NewFakeProvider(payments.FakeConfig{ProviderToken: "test-token"})
+	invoice := invoiceFor(t, p, 42, "19981")
+
+	// --- Act ---
+	authErr := p.Authorize(ctx, checkout(invoice))
+	payment, err := p.HandleCallback(ctx, callback(invoice))
+
+	// --- Assert ---
+	require.NoError(authErr)
+	require.NoError(err)
+	require.Equal("test-token", invoice.ProviderToken)
+	require.Equal(payments.StatusSucceeded, payment.Status)
+	require.Equal(int64(42), payment.OrderID)
+	require.Equal("prov-1", payment.ChargeID)
+
+	// --- Act ---
+	again, err := p.HandleCallback(ctx, callback(invoice))
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal(payment, again)
+
+	// --- Act ---
+	refund, err := p.Refund(ctx, invoice.ID)
+	_, errAgain := p.Refund(ctx, invoice.ID)
+
+	// --- Assert ---
+	require.NoError(err)
+	require.Equal(payments.StatusRefunded, refund.Status)
+	require.ErrorIs(errAgain, payments.ErrNotRefundable)
+}
+
+// Отказ провайдера приходит до списания денег. Списанную не ту сумму провайдер
+// не принимает, и её можно вернуть.
+func TestFakeProvider_Failure(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	ctx := context.Background()
+	p := payments.NewFakeProvider(payments.FakeConfig{Outcome: payments.OutcomeFailure})
+	declined := invoiceFor(t, p, 1, "100")
+	underpaid := invoiceFor(t, p, 2, "100")
+
+	// --- Act ---
+	err := p.Authorize(ctx, checkout(declined))
+
+	// --- Assert ---
+	require.ErrorIs(err, payments.ErrDeclined)
+	_, err = p.Refund(ctx, declined.ID)
+	require.ErrorIs(err, payments.ErrNotRefundable)
+
+	// --- Act ---
+	p.SetOutcome(payments.OutcomeSuccess)
+	short := checkout(underpaid)
+	short.Amount = decimal.RequireFromString("99.99")
+	authErr := p.Authorize(ctx, short)
+	cb := callback(underpaid)
+	cb.Amount = short.Amount
+	payment, err := p.HandleCallback(ctx, cb)
+
+	// --- Assert ---
+	require.ErrorIs(authErr, payments.ErrDeclined)
+	require.NoError(err)
+	require.Equal(payments.StatusFailed, payment.Status)
+	require.Contains(payment.FailureReason, "invoice is for 100 RUB")
+	refund, err := p.Refund(ctx, underpaid.ID)
+	require.NoError(err)
+	require.Equal(payments.StatusRefunded, refund.Status)
+
+	// --- Act ---
+	_, err = p.HandleCallback(ctx, payments.Callback{InvoiceID: "fake-404"})
+
+	// --- Assert ---
+	require.ErrorIs(err, payments.ErrUnknownInvoice)
+	require.ErrorIs(p.Authorize(ctx, payments.Checkout{InvoiceID: "fake-404"}), payments.ErrUnknownInvoice)
+}
+
+// Задержка провайдера выдерживается, но не дольше дедлайна запроса.
+func TestFakeProvider_Delay(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	p := payments.NewFakeProvider(payments.FakeConfig{Delay: 50 * time.Millisecond})
+
+	// --- Act ---
+	start := time.Now()
+	invoice := invoiceFor(t, p, 1, "100")
+
+	// --- Assert ---
+	require.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+
+	// --- Act ---
+	p.SetDelay(time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := p.HandleCallback(ctx, callback(invoice))
+
+	// --- Assert ---
+	require.ErrorIs(err, context.DeadlineExceeded)
+	_, paid := p.Payment(invoice.ID)
+	require.False(paid)
+}
+
+// Telegram передаёт суммы в копейках и центах, а у иены и воны дробной части нет.
+func TestMinorUnits(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal(1998100, payments.MinorUnits(decimal.RequireFromString("19981"), "RUB"))
+	require.Equal(999050, payments.MinorUnits(decimal.RequireFromString("9990.5"), "USD"))
+	require.Equal(1500, payments.MinorUnits(decimal.RequireFromString("1500"), "JPY"))
+	require.Equal("9990.5", payments.FromMinorUnits(999050, "RUB").String())
+	require.Equal("1500", payments.FromMinorUnits(1500, "JPY").String())
+}
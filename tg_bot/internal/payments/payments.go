@@ -0,0 +1,120 @@
+// Package payments - оплата заказов. Счёт выставляет Provider, а платит покупатель
+// в Telegram Payments: бот отправляет счёт через sendInvoice, до списания денег
+// спрашивает провайдера в pre_checkout_query и передаёт ему уведомление successful_payment.
+package payments
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrUnknownInvoice - провайдер не выставлял такой счёт.
+	ErrUnknownInvoice = errors.New("unknown invoice")
+	// ErrNotRefundable - счёт не оплачен или деньги по нему уже вернули.
+	ErrNotRefundable = errors.New("payment is not refundable")
+	// ErrDeclined - провайдер отказал в оплате до списания денег.
+	ErrDeclined = errors.New("payment declined")
+)
+
+// Status - состояние оплаты счёта.
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusRefunded  Status = "refunded"
+)
+
+// InvoiceRequest - на какой заказ и сумму выставить счёт.
+type InvoiceRequest struct {
+	OrderID  int64
+	Amount   decimal.Decimal
+	Currency string
+}
+
+// Invoice - счёт, выставленный провайдером.
+type Invoice struct {
+	ID       string
+	OrderID  int64
+	Amount   decimal.Decimal
+	Currency string
+	// ProviderToken - токен платёжного провайдера от @BotFather для sendInvoice
+	ProviderToken string
+}
+
+// Checkout - оплата, которую покупатель подтвердил, но деньги ещё не списаны,
+// в Telegram Payments - pre_checkout_query.
+type Checkout struct {
+	InvoiceID string
+	Amount    decimal.Decimal
+	Currency  string
+}
+
+// Callback - уведомление об оплате счёта, в Telegram Payments - successful_payment.
+type Callback struct {
+	InvoiceID        string
+	Amount           decimal.Decimal
+	Currency         string
+	TelegramChargeID string
+	ProviderChargeID string
+}
+
+// Payment - итог оплаты счёта.
+type Payment struct {
+	InvoiceID string
+	OrderID   int64
+	Status    Status
+	Amount    decimal.Decimal
+	Currency  string
+	// ChargeID - идентификатор платежа у провайдера, по нему ищут платёж при возврате
+	ChargeID string
+	// FailureReason - почему платёж не прошёл
+	FailureReason string
+}
+
+// Provider - платёжный провайдер.
+type Provider interface {
+	// CreateInvoice выставляет счёт на оплату заказа.
+	CreateInvoice(ctx context.Context, req InvoiceRequest) (Invoice, error)
+	// Authorize проверяет оплату до списания денег; ErrDeclined - провайдер её не примет.
+	Authorize(ctx context.Context, c Checkout) error
+	// HandleCallback проверяет уведомление об оплате и возвращает итог платежа.
+	// Повторное уведомление по оплаченному счёту возвращает тот же платёж.
+	// StatusFailed здесь значит, что деньги списаны, но платёж не принят: их нужно вернуть.
+	HandleCallback(ctx context.Context, cb Callback) (Payment, error)
+	// Refund возвращает покупателю деньги по оплаченному или не принятому после списания счёту.
+	Refund(ctx context.Context, invoiceID string) (Payment, error)
+}
+
+// minorUnitExponents - валюты, у которых в Telegram Payments не два знака после
+// запятой (core.telegram.org/bots/payments/currencies.json).
+var minorUnitExponents = map[string]int32{
+	"CLP": 0,
+	"ISK": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"PYG": 0,
+	"UGX": 0,
+	"VND": 0,
+}
+
+func exponent(currency string) int32 {
+	if exp, ok := minorUnitExponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// MinorUnits - сумма в минимальных единицах валюты (копейках, центах), как её
+// ждёт Telegram в LabeledPrice и присылает в total_amount.
+func MinorUnits(amount decimal.Decimal, currency string) int {
+	return int(amount.Shift(exponent(currency)).Round(0).IntPart())
+}
+
+// FromMinorUnits - сумма из минимальных единиц валюты.
+func FromMinorUnits(amount int, currency string) decimal.Decimal {
+	return decimal.New(int64(amount), -exponent(currency))
+}
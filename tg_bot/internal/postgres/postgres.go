@@ -0,0 +1,29 @@
+// Package postgres подключает бота к PostgreSQL, общему для всех реплик.
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kripst/krosovka/tg_bot/migrate"
+)
+
+// Connect применяет миграции и открывает пул соединений по DSN postgres://...
+func Connect(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+	if err := migrate.RunMigrations(dsn); err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool: %w", err)
+	}
+	// pgxpool подключается лениво, поэтому доступность БД проверяем явно
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,155 @@
+package subscriptions
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MemoryStore хранит подписки в памяти процесса: годится для одной реплики и тестов.
+type MemoryStore struct {
+	mu        sync.Mutex
+	nextID    int64
+	subs      map[int64]Subscription
+	watermark time.Time
+	notifier  sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{subs: make(map[int64]Subscription)}
+}
+
+func (s *MemoryStore) Add(ctx context.Context, sub Subscription) (Subscription, AddResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, old := range s.subs {
+		if !old.SameTarget(sub) {
+			continue
+		}
+		if sub.Kind != KindPrice || sameThreshold(old.Threshold, sub.Threshold) {
+			return old, Exists, nil
+		}
+		old.Threshold = sub.Threshold
+		s.subs[old.ID] = old
+		return old, ThresholdChanged, nil
+	}
+
+	s.nextID++
+	sub.ID = s.nextID
+	sub.CreatedAt = time.Now()
+	s.subs[sub.ID] = sub
+	return sub, Added, nil
+}
+
+func sameThreshold(a, b *decimal.Decimal) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (s *MemoryStore) ListByChat(ctx context.Context, chatID int64) ([]Subscription, error) {
+	return s.list(func(sub Subscription) bool { return sub.ChatID == chatID }), nil
+}
+
+func (s *MemoryStore) ListByArticles(ctx context.Context, articles []string) ([]Subscription, error) {
+	return s.list(func(sub Subscription) bool { return slices.Contains(articles, sub.Article) }), nil
+}
+
+func (s *MemoryStore) list(match func(Subscription) bool) []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []Subscription
+	for _, sub := range s.subs {
+		if match(sub) {
+			out = append(out, sub)
+		}
+	}
+	slices.SortFunc(out, func(a, b Subscription) int { return int(a.ID - b.ID) })
+	return out
+}
+
+func (s *MemoryStore) Remove(ctx context.Context, chatID, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.subs[id]; !ok || sub.ChatID != chatID {
+		return false, nil
+	}
+	delete(s.subs, id)
+	return true, nil
+}
+
+func (s *MemoryStore) RemoveChat(ctx context.Context, chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, sub := range s.subs {
+		if sub.ChatID == chatID {
+			delete(s.subs, id)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ClaimStock(ctx context.Context, id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.subs[id]; !ok || sub.Kind != KindStock {
+		return false, nil
+	}
+	delete(s.subs, id)
+	return true, nil
+}
+
+func (s *MemoryStore) ClaimPriceDrop(ctx context.Context, id int64, price decimal.Decimal) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[id]
+	if !ok || sub.Kind != KindPrice || !price.LessThan(sub.LastPrice) {
+		return false, nil
+	}
+	sub.LastPrice = price
+	s.subs[id] = sub
+	return true, nil
+}
+
+func (s *MemoryStore) RaisePrice(ctx context.Context, id int64, price decimal.Decimal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub, ok := s.subs[id]; ok && sub.Kind == KindPrice && price.GreaterThan(sub.LastPrice) {
+		sub.LastPrice = price
+		s.subs[id] = sub
+	}
+	return nil
+}
+
+func (s *MemoryStore) LockNotifier(ctx context.Context) (func(), bool, error) {
+	if !s.notifier.TryLock() {
+		return nil, false, nil
+	}
+	return s.notifier.Unlock, true, nil
+}
+
+func (s *MemoryStore) Watermark(ctx context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.watermark, nil
+}
+
+func (s *MemoryStore) SetWatermark(ctx context.Context, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.After(s.watermark) {
+		s.watermark = t
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+// notifierName - строка notifier_state с позицией в ленте изменений inventory
+const notifierName = "inventory"
+
+// notifierLock - имя advisory lock, которое держит реплика, рассылающая уведомления
+const notifierLock = "tg_bot.notifier"
+
+// unlockTimeout - сколько ждать снятия advisory lock при освобождении рассылки
+const unlockTimeout = 5 * time.Second
+
+// PostgresStore хранит подписки в PostgreSQL. Схему создаёт пакет migrate.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+const subscriptionColumns = `id, chat_id, kind, article, COALESCE(size, 0), COALESCE(size_system, ''),
+	threshold, COALESCE(last_price, 0), COALESCE(currency, ''), language, created_at`
+
+func (s *PostgresStore) Add(ctx context.Context, sub Subscription) (Subscription, AddResult, error) {
+	var size, system, lastPrice, currency any
+	if sub.Size > 0 {
+		size, system = float64(sub.Size), sub.SizeSystem
+	}
+	if sub.Kind == KindPrice {
+		lastPrice, currency = sub.LastPrice, sub.Currency
+	}
+
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO subscriptions (chat_id, kind, article, size, size_system, threshold, last_price, currency, language)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT DO NOTHING
+		RETURNING `+subscriptionColumns,
+		sub.ChatID, string(sub.Kind), sub.Article, size, system, sub.Threshold, lastPrice, currency, sub.Language,
+	)
+	created, err := scanSubscription(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Такая подписка уже есть
+		return s.changeThreshold(ctx, sub)
+	}
+	if err != nil {
+		return Subscription{}, 0, fmt.Errorf("insert subscription: %w", err)
+	}
+	return created, Added, nil
+}
+
+// changeThreshold ставит новый порог уже существующей подписке на цену.
+func (s *PostgresStore) changeThreshold(ctx context.Context, sub Subscription) (Subscription, AddResult, error) {
+	if sub.Kind != KindPrice {
+		return sub, Exists, nil
+	}
+	row := s.pool.QueryRow(ctx, `
+		UPDATE subscriptions SET threshold = $4
+		WHERE chat_id = $1 AND kind = $2 AND article = $3 AND threshold IS DISTINCT FROM $4
+		RETURNING `+subscriptionColumns,
+		sub.ChatID, string(sub.Kind), sub.Article, sub.Threshold,
+	)
+	updated, err := scanSubscription(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sub, Exists, nil
+	}
+	if err != nil {
+		return Subscription{}, 0, fmt.Errorf("update subscription threshold: %w", err)
+	}
+	return updated, ThresholdChanged, nil
+}
+
+func (s *PostgresStore) ListByChat(ctx context.Context, chatID int64) ([]Subscription, error) {
+	return s.list(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE chat_id = $1 ORDER BY id`, chatID)
+}
+
+func (s *PostgresStore) ListByArticles(ctx context.Context, articles []string) ([]Subscription, error) {
+	return s.list(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE article = ANY($1) ORDER BY id`, articles)
+}
+
+func (s *PostgresStore) list(ctx context.Context, query string, args ...any) ([]Subscription, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		out = append(out, sub)
+	}
+	return out, rows.Err()
+}
+
+func (s *PostgresStore) Remove(ctx context.Context, chatID, id int64) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM subscriptions WHERE id = $1 AND chat_id = $2`, id, chatID)
+	if err != nil {
+		return false, fmt.Errorf("delete subscription %d: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) RemoveChat(ctx context.Context, chatID int64) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM subscriptions WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("delete subscriptions of chat %d: %w", chatID, err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) ClaimStock(ctx context.Context, id int64) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM subscriptions WHERE id = $1 AND kind = 'stock'`, id)
+	if err != nil {
+		return false, fmt.Errorf("claim stock subscription %d: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) ClaimPriceDrop(ctx context.Context, id int64, price decimal.Decimal) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE subscriptions SET last_price = $2
+		WHERE id = $1 AND kind = 'price' AND last_price > $2`, id, price)
+	if err != nil {
+		return false, fmt.Errorf("claim price subscription %d: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (s *PostgresStore) RaisePrice(ctx context.Context, id int64, price decimal.Decimal) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE subscriptions SET last_price = $2
+		WHERE id = $1 AND kind = 'price' AND last_price < $2`, id, price)
+	if err != nil {
+		return fmt.Errorf("raise price of subscription %d: %w", id, err)
+	}
+	return nil
+}
+
+// LockNotifier берёт сессионный advisory lock на отдельном соединении пула. Если
+// реплика упадёт, PostgreSQL снимет блокировку вместе с закрытым соединением.
+func (s *PostgresStore) LockNotifier(ctx context.Context) (func(), bool, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("acquire connection: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, notifierLock).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("lock notifier: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, notifierLock); err != nil {
+			// Соединение с неснятой блокировкой нельзя вернуть в пул
+			_ = conn.Hijack().Close(ctx)
+			return
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+func (s *PostgresStore) Watermark(ctx context.Context) (time.Time, error) {
+	var t time.Time
+	err := s.pool.QueryRow(ctx, `SELECT watermark FROM notifier_state WHERE name = $1`, notifierName).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("select watermark: %w", err)
+	}
+	return t, nil
+}
+
+// SetWatermark только сдвигает позицию вперёд: реплика с устаревшим
+// опросом не откатит позицию, записанную другой.
+func (s *PostgresStore) SetWatermark(ctx context.Context, t time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO notifier_state (name, watermark) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET watermark = GREATEST(notifier_state.watermark, EXCLUDED.watermark)`,
+		notifierName, t)
+	if err != nil {
+		return fmt.Errorf("update watermark: %w", err)
+	}
+	return nil
+}
+
+func scanSubscription(row pgx.Row) (Subscription, error) {
+	var (
+		sub  Subscription
+		kind string
+		size decimal.Decimal
+	)
+	err := row.Scan(&sub.ID, &sub.ChatID, &kind, &sub.Article, &size, &sub.SizeSystem,
+		&sub.Threshold, &sub.LastPrice, &sub.Currency, &sub.Language, &sub.CreatedAt)
+	if err != nil {
+		return Subscription{}, err
+	}
+	sub.Kind = Kind(kind)
+	sub.Size = float32(size.InexactFloat64())
+	return sub, nil
+}
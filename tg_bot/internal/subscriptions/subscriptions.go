@@ -0,0 +1,85 @@
+// Package subscriptions хранит подписки чатов на появление товара и снижение цены.
+package subscriptions
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Kind string
+
+const (
+	// KindStock - товар снова появился в каталоге, при необходимости в нужном размере
+	KindStock Kind = "stock"
+	// KindPrice - цена товара снизилась, при необходимости ниже порога
+	KindPrice Kind = "price"
+)
+
+// Subscription - подписка одного чата на один артикул.
+type Subscription struct {
+	ID      int64
+	ChatID  int64
+	Kind    Kind
+	Article string
+	// Size, SizeSystem - размер для KindStock, нулевой размер - любой
+	Size       float32
+	SizeSystem string
+	// Threshold - для KindPrice: уведомлять, только когда цена не выше порога
+	Threshold *decimal.Decimal
+	// LastPrice - для KindPrice: цена, от которой считается снижение.
+	// Запоминается при подписке, после уведомления и при росте цены
+	LastPrice decimal.Decimal
+	Currency  string
+	// Language - язык уведомлений, запоминается при подписке
+	Language  string
+	CreatedAt time.Time
+}
+
+// SameTarget - совпадают ли у подписок чат, вид, артикул и размер: у чата может
+// быть только одна такая подписка.
+func (s Subscription) SameTarget(other Subscription) bool {
+	return s.ChatID == other.ChatID && s.Kind == other.Kind && s.Article == other.Article &&
+		s.Size == other.Size && s.SizeSystem == other.SizeSystem
+}
+
+// AddResult - чем закончился Store.Add.
+type AddResult int
+
+const (
+	// Added - подписка сохранена
+	Added AddResult = iota + 1
+	// Exists - такая подписка у чата уже есть
+	Exists
+	// ThresholdChanged - подписка на цену уже была, у неё сменился порог
+	ThresholdChanged
+)
+
+// Store хранит подписки. Методы Claim* атомарны: из нескольких реплик,
+// увидевших одно изменение, уведомление отправит только одна.
+type Store interface {
+	// Add сохраняет подписку. Если такая подписка у чата уже есть, у KindPrice
+	// меняется порог, остальное остаётся прежним.
+	Add(ctx context.Context, sub Subscription) (Subscription, AddResult, error)
+	ListByChat(ctx context.Context, chatID int64) ([]Subscription, error)
+	ListByArticles(ctx context.Context, articles []string) ([]Subscription, error)
+	// Remove удаляет подписку чата; false - такой подписки нет.
+	Remove(ctx context.Context, chatID, id int64) (bool, error)
+	// RemoveChat удаляет все подписки чата, например когда бота заблокировали.
+	RemoveChat(ctx context.Context, chatID int64) error
+
+	// ClaimStock удаляет сработавшую подписку KindStock; false - её уже обработали.
+	ClaimStock(ctx context.Context, id int64) (bool, error)
+	// ClaimPriceDrop записывает price в LastPrice, если она ниже прежней; false - уже уведомили.
+	ClaimPriceDrop(ctx context.Context, id int64, price decimal.Decimal) (bool, error)
+	// RaisePrice поднимает LastPrice до price, если цена выросла, чтобы следующее снижение тоже заметить.
+	RaisePrice(ctx context.Context, id int64, price decimal.Decimal) error
+
+	// LockNotifier занимает рассылку уведомлений за этим процессом; false - её ведёт
+	// другая реплика. Вызов unlock освобождает рассылку.
+	LockNotifier(ctx context.Context) (unlock func(), ok bool, err error)
+	// Watermark - момент, до которого изменения inventory уже разобраны; нулевое значение - ещё ни разу.
+	Watermark(ctx context.Context) (time.Time, error)
+	SetWatermark(ctx context.Context, t time.Time) error
+}
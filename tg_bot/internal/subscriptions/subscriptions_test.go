@@ -0,0 +1,132 @@
+package subscriptions_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/postgres"
+	"github.com/kripst/krosovka/tg_bot/internal/subscriptions"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, subscriptions.NewMemoryStore())
+}
+
+// PostgreSQL проверяется, только если задан TG_TEST_POSTGRES_DSN.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TG_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TG_TEST_POSTGRES_DSN is not set")
+	}
+	pool, err := postgres.Connect(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	testStore(t, subscriptions.NewPostgresStore(pool))
+}
+
+// testStore - общие требования к любому Store.
+func testStore(t *testing.T, store subscriptions.Store) {
+	require := require.New(t)
+	ctx := context.Background()
+	chatID := time.Now().UnixNano()
+	t.Cleanup(func() { _ = store.RemoveChat(ctx, chatID) })
+	threshold := decimal.RequireFromString("9000")
+
+	// --- Добавление и повтор ---
+	stock, result, err := store.Add(ctx, subscriptions.Subscription{
+		ChatID: chatID, Kind: subscriptions.KindStock, Article: "ART-001", Size: 42.5, SizeSystem: "EU", Language: "ru",
+	})
+	require.NoError(err)
+	require.Equal(subscriptions.Added, result)
+	require.NotZero(stock.ID)
+	_, result, err = store.Add(ctx, subscriptions.Subscription{
+		ChatID: chatID, Kind: subscriptions.KindStock, Article: "ART-001", Size: 42.5, SizeSystem: "EU", Language: "ru",
+	})
+	require.NoError(err)
+	require.Equal(subscriptions.Exists, result)
+
+	higher := decimal.RequireFromString("9500")
+	priceSub := subscriptions.Subscription{
+		ChatID: chatID, Kind: subscriptions.KindPrice, Article: "ART-001",
+		Threshold: &higher, LastPrice: decimal.RequireFromString("10000"), Currency: "RUB", Language: "en",
+	}
+	price, result, err := store.Add(ctx, priceSub)
+	require.NoError(err)
+	require.Equal(subscriptions.Added, result)
+
+	// --- Повтор с другим порогом меняет порог, с тем же - ничего ---
+	priceSub.Threshold = &threshold
+	changed, result, err := store.Add(ctx, priceSub)
+	require.NoError(err)
+	require.Equal(subscriptions.ThresholdChanged, result)
+	require.Equal(price.ID, changed.ID)
+	_, result, err = store.Add(ctx, priceSub)
+	require.NoError(err)
+	require.Equal(subscriptions.Exists, result)
+
+	subs, err := store.ListByChat(ctx, chatID)
+	require.NoError(err)
+	require.Len(subs, 2)
+	require.Equal(float32(42.5), subs[0].Size)
+	require.Equal("EU", subs[0].SizeSystem)
+	require.True(threshold.Equal(*subs[1].Threshold))
+	require.Equal("en", subs[1].Language)
+
+	byArticle, err := store.ListByArticles(ctx, []string{"ART-001", "ART-404"})
+	require.NoError(err)
+	require.GreaterOrEqual(len(byArticle), 2)
+
+	// --- Снижение цены засчитывается один раз, рост поднимает базу ---
+	ok, err := store.ClaimPriceDrop(ctx, price.ID, decimal.RequireFromString("8500"))
+	require.NoError(err)
+	require.True(ok)
+	ok, err = store.ClaimPriceDrop(ctx, price.ID, decimal.RequireFromString("8500"))
+	require.NoError(err)
+	require.False(ok)
+	require.NoError(store.RaisePrice(ctx, price.ID, decimal.RequireFromString("9500")))
+	ok, err = store.ClaimPriceDrop(ctx, price.ID, decimal.RequireFromString("9000"))
+	require.NoError(err)
+	require.True(ok)
+
+	// --- Появление товара срабатывает один раз ---
+	ok, err = store.ClaimStock(ctx, stock.ID)
+	require.NoError(err)
+	require.True(ok)
+	ok, err = store.ClaimStock(ctx, stock.ID)
+	require.NoError(err)
+	require.False(ok)
+
+	// --- Удалить можно только свою подписку ---
+	ok, err = store.Remove(ctx, chatID+1, price.ID)
+	require.NoError(err)
+	require.False(ok)
+	ok, err = store.Remove(ctx, chatID, price.ID)
+	require.NoError(err)
+	require.True(ok)
+
+	// --- Рассылку ведёт один процесс ---
+	unlock, ok, err := store.LockNotifier(ctx)
+	require.NoError(err)
+	require.True(ok)
+	_, ok, err = store.LockNotifier(ctx)
+	require.NoError(err)
+	require.False(ok, "рассылка уже занята")
+	unlock()
+	unlock, ok, err = store.LockNotifier(ctx)
+	require.NoError(err)
+	require.True(ok)
+	unlock()
+
+	// --- Позиция в ленте изменений только растёт ---
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	require.NoError(store.SetWatermark(ctx, at))
+	require.NoError(store.SetWatermark(ctx, at.Add(-time.Hour)))
+	got, err := store.Watermark(ctx)
+	require.NoError(err)
+	require.True(at.Equal(got), "%s != %s", at, got)
+}
@@ -0,0 +1,226 @@
+// Package telegramtest - локальная заглушка Telegram Bot API для тестов бота.
+package telegramtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const Token = "test-token"
+
+// Call - один запрос к Bot API.
+type Call struct {
+	Method string
+	Params url.Values
+}
+
+// Server отвечает на методы Bot API, записывает вызовы и отдаёт
+// добавленные обновления через getUpdates.
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	calls         []Call
+	updates       []tgbotapi.Update
+	nextUpdateID  int
+	nextMessageID int
+	// blocked - чаты, заблокировавшие бота: запросы к ним получают 403
+	blocked map[string]bool
+	// changed закрывается и пересоздаётся при каждом новом вызове или обновлении
+	changed chan struct{}
+	done    chan struct{}
+}
+
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		nextUpdateID:  1,
+		nextMessageID: 100,
+		blocked:       make(map[string]bool),
+		changed:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(func() {
+		// Отпускаем висящие getUpdates, иначе Close ждёт их таймаута
+		close(s.done)
+		s.Close()
+	})
+	return s
+}
+
+// Endpoint - шаблон адреса для tgbotapi.NewBotAPIWithAPIEndpoint.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+// BotAPI - клиент, направленный на заглушку.
+func (s *Server) BotAPI(t testing.TB) *tgbotapi.BotAPI {
+	t.Helper()
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(Token, s.Endpoint())
+	if err != nil {
+		t.Fatalf("create bot api: %v", err)
+	}
+	return api
+}
+
+// PushUpdate добавляет обновление в очередь getUpdates и возвращает его update_id.
+func (s *Server) PushUpdate(update tgbotapi.Update) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if update.UpdateID == 0 {
+		update.UpdateID = s.nextUpdateID
+	}
+	s.nextUpdateID = max(s.nextUpdateID, update.UpdateID) + 1
+	s.updates = append(s.updates, update)
+	s.notifyLocked()
+	return update.UpdateID
+}
+
+// Block отвечает на запросы к чату chatID как Telegram, когда пользователь заблокировал бота.
+func (s *Server) Block(chatID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocked[strconv.FormatInt(chatID, 10)] = true
+}
+
+// Calls - вызовы метода в порядке поступления.
+func (s *Server) Calls(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Call
+	for _, c := range s.calls {
+		if c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// WaitCalls ждёт, пока метод вызовут хотя бы n раз.
+func (s *Server) WaitCalls(t testing.TB, method string, n int) []Call {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		if calls := s.Calls(method); len(calls) >= n {
+			return calls
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			t.Fatalf("%s was called %d times, want %d", method, len(s.Calls(method)), n)
+		}
+	}
+}
+
+func (s *Server) notifyLocked() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	// /bot<token>/<method>
+	path := strings.TrimPrefix(r.URL.Path, "/bot")
+	token, method, ok := strings.Cut(path, "/")
+	if !ok || token != Token {
+		writeResult(w, nil, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		_ = r.ParseMultipartForm(10 << 20)
+	} else {
+		_ = r.ParseForm()
+	}
+	params := r.Form
+
+	if method == "getUpdates" {
+		writeResult(w, s.waitUpdates(r, params), http.StatusOK, "")
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{Method: method, Params: params})
+	s.notifyLocked()
+	if s.blocked[params.Get("chat_id")] {
+		s.mu.Unlock()
+		writeResult(w, nil, http.StatusForbidden, "Forbidden: bot was blocked by the user")
+		return
+	}
+	var result any = true
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{ID: 1, IsBot: true, FirstName: "Krosovka", UserName: "krosovka_bot"}
+	case "sendMessage", "sendPhoto", "sendInvoice", "editMessageText", "editMessageCaption":
+		chatID, _ := strconv.ParseInt(params.Get("chat_id"), 10, 64)
+		messageID, _ := strconv.Atoi(params.Get("message_id"))
+		if messageID == 0 {
+			s.nextMessageID++
+			messageID = s.nextMessageID
+		}
+		result = tgbotapi.Message{
+			MessageID: messageID,
+			Date:      int(time.Now().Unix()),
+			Chat:      &tgbotapi.Chat{ID: chatID},
+			Text:      params.Get("text"),
+		}
+	}
+	s.mu.Unlock()
+
+	writeResult(w, result, http.StatusOK, "")
+}
+
+// waitUpdates отдаёт обновления начиная с offset, ожидая их не дольше timeout.
+func (s *Server) waitUpdates(r *http.Request, params url.Values) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(params.Get("offset"))
+	timeout, _ := strconv.Atoi(params.Get("timeout"))
+	deadline := time.After(time.Duration(timeout) * time.Second)
+
+	for {
+		s.mu.Lock()
+		var out []tgbotapi.Update
+		for _, u := range s.updates {
+			if u.UpdateID >= offset {
+				out = append(out, u)
+			}
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(out) > 0 || timeout == 0 {
+			return out
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			return nil
+		case <-r.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+func writeResult(w http.ResponseWriter, result any, code int, description string) {
+	raw, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(tgbotapi.APIResponse{
+		Ok:          code == http.StatusOK,
+		Result:      raw,
+		ErrorCode:   code,
+		Description: description,
+	})
+}
@@ -0,0 +1,74 @@
+package telegramtest
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Message - текстовое сообщение пользователя userID в личном чате.
+// Команды (/catalog) размечаются как bot_command, как это делает Telegram.
+func Message(userID int64, text string) tgbotapi.Update {
+	msg := &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		Text:      text,
+	}
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		msg.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+	return tgbotapi.Update{Message: msg}
+}
+
+// InlineQuery - inline-запрос "@bot <query>" со смещением offset.
+func InlineQuery(userID int64, query, offset string) tgbotapi.Update {
+	return tgbotapi.Update{InlineQuery: &tgbotapi.InlineQuery{
+		ID:     "iq-" + query + "-" + offset,
+		From:   &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Query:  query,
+		Offset: offset,
+	}}
+}
+
+// Callback - нажатие inline-кнопки с данными data под сообщением messageID.
+func Callback(userID int64, messageID int, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   "cb-" + data,
+		From: &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Message: &tgbotapi.Message{
+			MessageID: messageID,
+			Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		},
+		Data: data,
+	}}
+}
+
+// PreCheckoutQuery - подтверждение оплаты счёта с payload на сумму amount в
+// минимальных единицах валюты.
+func PreCheckoutQuery(userID int64, payload, currency string, amount int) tgbotapi.Update {
+	return tgbotapi.Update{PreCheckoutQuery: &tgbotapi.PreCheckoutQuery{
+		ID:             "pcq-" + payload,
+		From:           &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Currency:       currency,
+		TotalAmount:    amount,
+		InvoicePayload: payload,
+	}}
+}
+
+// SuccessfulPayment - сервисное сообщение об оплате счёта с payload.
+func SuccessfulPayment(userID int64, payload, currency string, amount int) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		From:      &tgbotapi.User{ID: userID, FirstName: "Test"},
+		Chat:      &tgbotapi.Chat{ID: userID, Type: "private"},
+		SuccessfulPayment: &tgbotapi.SuccessfulPayment{
+			Currency:                currency,
+			TotalAmount:             amount,
+			InvoicePayload:          payload,
+			TelegramPaymentChargeID: "tg-charge-" + payload,
+			ProviderPaymentChargeID: "provider-charge-" + payload,
+		},
+	}}
+}
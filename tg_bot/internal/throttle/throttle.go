@@ -0,0 +1,96 @@
+// Package throttle ограничивает частоту сообщений, которые бот отправляет сам,
+// без запроса пользователя: рассылки и уведомления.
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// idleTTL - через сколько лимитер неактивного чата вытесняется из памяти.
+const idleTTL = 10 * time.Minute
+
+// Throttle держит общий темп отправки и паузу между сообщениями в один чат.
+// Telegram допускает около 30 сообщений в секунду на бота и одно в секунду на чат.
+type Throttle struct {
+	global *rate.Limiter
+	chats  *keyed
+}
+
+// New - не больше perSecond сообщений в секунду всего и одно сообщение в чат раз в perChat.
+func New(perSecond int, perChat time.Duration) *Throttle {
+	return &Throttle{
+		global: rate.NewLimiter(rate.Limit(perSecond), 1),
+		chats:  newKeyed(rate.Every(perChat), 1),
+	}
+}
+
+// Wait ждёт, пока в чат chatID можно отправить сообщение.
+func (t *Throttle) Wait(ctx context.Context, chatID int64) error {
+	if err := t.chats.get(chatID).Wait(ctx); err != nil {
+		return err
+	}
+	return t.global.Wait(ctx)
+}
+
+// Quota - сколько уведомлений пользователь получает за период, чтобы
+// распродажа на весь каталог не превратилась в спам.
+type Quota struct {
+	chats *keyed
+}
+
+// NewQuota - не больше n уведомлений за per в один чат.
+func NewQuota(n int, per time.Duration) *Quota {
+	return &Quota{chats: newKeyed(rate.Every(per/time.Duration(max(n, 1))), max(n, 1))}
+}
+
+// Allow расходует одно уведомление чата; false - лимит исчерпан.
+func (q *Quota) Allow(chatID int64) bool {
+	return q.chats.get(chatID).Allow()
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// keyed - лимитеры по чатам с вытеснением неактивных.
+type keyed struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	buckets map[int64]*bucket
+	lastGC  time.Time
+}
+
+func newKeyed(limit rate.Limit, burst int) *keyed {
+	return &keyed{limit: limit, burst: burst, buckets: make(map[int64]*bucket), lastGC: time.Now()}
+}
+
+func (k *keyed) get(chatID int64) *rate.Limiter {
+	now := time.Now()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if now.Sub(k.lastGC) > idleTTL {
+		for id, b := range k.buckets {
+			if now.Sub(b.lastSeen) > idleTTL {
+				delete(k.buckets, id)
+			}
+		}
+		k.lastGC = now
+	}
+
+	b, ok := k.buckets[chatID]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.buckets[chatID] = b
+	}
+	b.lastSeen = now
+	return b.limiter
+}
@@ -0,0 +1,53 @@
+package throttle_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kripst/krosovka/tg_bot/internal/throttle"
+	"github.com/stretchr/testify/require"
+)
+
+// Сообщения в один чат идут с паузой, в разные чаты - без неё.
+func TestThrottle_PerChat(t *testing.T) {
+	require := require.New(t)
+	th := throttle.New(1000, 100*time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(th.Wait(ctx, 1))
+	require.NoError(th.Wait(ctx, 2))
+	require.Less(time.Since(start), 50*time.Millisecond)
+
+	require.NoError(th.Wait(ctx, 1))
+	require.GreaterOrEqual(time.Since(start), 90*time.Millisecond)
+}
+
+// Общий темп ограничивает рассылку по многим чатам.
+func TestThrottle_Global(t *testing.T) {
+	require := require.New(t)
+	th := throttle.New(20, time.Millisecond)
+	ctx := context.Background()
+
+	start := time.Now()
+	for chatID := range int64(5) {
+		require.NoError(th.Wait(ctx, chatID))
+	}
+	// Первое сообщение сразу, следующие четыре - по 50мс
+	require.GreaterOrEqual(time.Since(start), 190*time.Millisecond)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.Error(th.Wait(canceled, 100))
+}
+
+func TestQuota(t *testing.T) {
+	require := require.New(t)
+	q := throttle.NewQuota(2, time.Hour)
+
+	require.True(q.Allow(1))
+	require.True(q.Allow(1))
+	require.False(q.Allow(1))
+	require.True(q.Allow(2))
+}
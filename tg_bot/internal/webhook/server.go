@@ -0,0 +1,134 @@
+// Package webhook принимает обновления Telegram по HTTP вместо long polling.
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kripst/krosovka/tg_bot/config"
+	"go.uber.org/zap"
+)
+
+// SecretTokenHeader - заголовок, в котором Telegram передаёт secret_token из setWebhook.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxUpdateSize - предел тела запроса; обновления Telegram на порядки меньше
+const maxUpdateSize = 1 << 20
+
+// Dispatcher принимает разобранные обновления.
+type Dispatcher interface {
+	Dispatch(update tgbotapi.Update) bool
+}
+
+type Server struct {
+	cfg        config.WebhookConfig
+	api        *tgbotapi.BotAPI
+	dispatcher Dispatcher
+	log        *zap.Logger
+	path       string
+}
+
+func New(cfg config.WebhookConfig, api *tgbotapi.BotAPI, dispatcher Dispatcher, log *zap.Logger) (*Server, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook url: %w", err)
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	return &Server{cfg: cfg, api: api, dispatcher: dispatcher, log: log, path: path}, nil
+}
+
+// Register регистрирует вебхук в Telegram вместе с secret_token.
+// tgbotapi.WebhookConfig не знает про secret_token, поэтому запрос собирается вручную.
+func (s *Server) Register() error {
+	params := tgbotapi.Params{}
+	params["url"] = s.cfg.URL
+	params["secret_token"] = s.cfg.SecretToken
+	params.AddNonZero("max_connections", s.cfg.MaxConnections)
+
+	resp, err := s.api.MakeRequest("setWebhook", params)
+	if err != nil {
+		return fmt.Errorf("set webhook: %w", err)
+	}
+	if !resp.Ok {
+		return fmt.Errorf("set webhook: %s", resp.Description)
+	}
+	return nil
+}
+
+// ServeHTTP проверяет секрет и передаёт обновление диспетчеру, не дожидаясь обработки:
+// Telegram ждёт ответа недолго и при таймауте повторяет доставку.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != s.path {
+		http.NotFound(w, r)
+		return
+	}
+
+	secret := r.Header.Get(SecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(s.cfg.SecretToken)) != 1 {
+		s.log.Warn("webhook request with wrong secret token", zap.String("remote_addr", r.RemoteAddr))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+		s.log.Warn("bad webhook update", zap.Error(err))
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	if !s.dispatcher.Dispatch(update) {
+		s.log.Debug("update skipped", zap.Int("update_id", update.UpdateID))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Run слушает cfg.ListenAddr до отмены ctx, затем дожидается текущих запросов
+// не дольше shutdownTimeout. TLS завершается на балансировщике перед ботом.
+func (s *Server) Run(ctx context.Context, shutdownTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", s.cfg.ListenAddr, err)
+	}
+	return s.Serve(ctx, lis, shutdownTimeout)
+}
+
+func (s *Server) Serve(ctx context.Context, lis net.Listener, shutdownTimeout time.Duration) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(lis)
+	}()
+	s.log.Info("webhook server started", zap.String("addr", lis.Addr().String()), zap.String("path", s.path))
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("webhook server: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("webhook shutdown: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webhook server: %w", err)
+	}
+	return nil
+}
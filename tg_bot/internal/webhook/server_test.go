@@ -0,0 +1,145 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/kripst/krosovka/tg_bot/config"
+	"github.com/kripst/krosovka/tg_bot/internal/dispatch"
+	"github.com/kripst/krosovka/tg_bot/internal/telegramtest"
+	"github.com/kripst/krosovka/tg_bot/internal/webhook"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const secret = "s3cret_token"
+
+type recorder struct {
+	mu      sync.Mutex
+	updates []int
+}
+
+func (r *recorder) handle(ctx context.Context, u tgbotapi.Update) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, u.UpdateID)
+}
+
+func (r *recorder) ids() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.updates...)
+}
+
+func webhookConfig() config.WebhookConfig {
+	return config.WebhookConfig{URL: "https://bot.example.com/telegram", SecretToken: secret, MaxConnections: 40}
+}
+
+// startWebhook поднимает вебхук на случайном порту и возвращает его адрес.
+func startWebhook(t *testing.T, rec *recorder) (string, *dispatch.Dispatcher, context.CancelFunc, <-chan error) {
+	t.Helper()
+	api := telegramtest.NewServer(t).BotAPI(t)
+	d := dispatch.New(rec.handle, 4, zap.NewNop())
+	server, err := webhook.New(webhookConfig(), api, d, zap.NewNop())
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, lis, time.Second)
+	}()
+	t.Cleanup(cancel)
+
+	return "http://" + lis.Addr().String() + "/telegram", d, cancel, done
+}
+
+func post(t *testing.T, url, token string, update tgbotapi.Update) int {
+	t.Helper()
+	body, err := json.Marshal(update)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(body)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(webhook.SecretTokenHeader, token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// Обновления без верного секрета отклоняются, повторная доставка обрабатывается один раз.
+func TestWebhook_SecretAndDedupe(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	rec := &recorder{}
+	url, d, cancel, done := startWebhook(t, rec)
+	update := telegramtest.Message(5, "/catalog")
+	update.UpdateID = 100
+
+	// --- Act ---
+	noSecret := post(t, url, "", update)
+	wrongSecret := post(t, url, "wrong", update)
+	first := post(t, url, secret, update)
+	retry := post(t, url, secret, update)
+	cancel()
+
+	// --- Assert ---
+	require.Equal(http.StatusUnauthorized, noSecret)
+	require.Equal(http.StatusUnauthorized, wrongSecret)
+	require.Equal(http.StatusOK, first)
+	require.Equal(http.StatusOK, retry)
+
+	require.NoError(<-done)
+	require.NoError(d.Shutdown(context.Background()))
+	require.Equal([]int{100}, rec.ids())
+}
+
+func TestWebhook_BadRequests(t *testing.T) {
+	require := require.New(t)
+	url, _, _, _ := startWebhook(t, &recorder{})
+
+	resp, err := http.Get(url)
+	require.NoError(err)
+	resp.Body.Close()
+	require.Equal(http.StatusNotFound, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader("{not json"))
+	require.NoError(err)
+	req.Header.Set(webhook.SecretTokenHeader, secret)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(err)
+	resp.Body.Close()
+	require.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
+// setWebhook передаёт в Telegram адрес и secret_token.
+func TestWebhook_Register(t *testing.T) {
+	// --- Arrange ---
+	require := require.New(t)
+	telegram := telegramtest.NewServer(t)
+	d := dispatch.New((&recorder{}).handle, 1, zap.NewNop())
+	server, err := webhook.New(webhookConfig(), telegram.BotAPI(t), d, zap.NewNop())
+	require.NoError(err)
+
+	// --- Act ---
+	err = server.Register()
+
+	// --- Assert ---
+	require.NoError(err)
+	calls := telegram.Calls("setWebhook")
+	require.Len(calls, 1)
+	require.Equal("https://bot.example.com/telegram", calls[0].Params.Get("url"))
+	require.Equal(secret, calls[0].Params.Get("secret_token"))
+	require.Equal("40", calls[0].Params.Get("max_connections"))
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS fsm_sessions;
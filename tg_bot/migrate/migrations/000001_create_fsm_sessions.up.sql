@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS fsm_sessions
+(
+    chat_id BIGINT PRIMARY KEY,
+    flow VARCHAR(64) NOT NULL,
+    state VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    version BIGINT NOT NULL CHECK (version > 0),
+    expires_at TIMESTAMP WITH TIME ZONE,                             -- NULL - without timeout
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fsm_sessions_expires_at ON fsm_sessions (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notifier_state;
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS subscriptions
+(
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    kind VARCHAR(8) NOT NULL CHECK (kind IN ('stock', 'price')),
+    article VARCHAR(50) NOT NULL,
+    size NUMERIC(4, 1),                                              -- stock: NULL - any size
+    size_system VARCHAR(4),
+    threshold NUMERIC(14, 4),                                        -- price: NULL - any drop
+    last_price NUMERIC(14, 4),                                       -- price: baseline for the next drop
+    currency CHAR(3),
+    language VARCHAR(8) NOT NULL DEFAULT 'ru',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscriptions_unique
+    ON subscriptions (chat_id, kind, article, COALESCE(size, 0), COALESCE(size_system, ''));
+CREATE INDEX IF NOT EXISTS idx_subscriptions_article ON subscriptions (article);
+
+-- Position of the inventory change feed already processed by the notifier
+CREATE TABLE IF NOT EXISTS notifier_state
+(
+    name VARCHAR(32) PRIMARY KEY,
+    watermark TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS cart_items
+(
+    chat_id BIGINT NOT NULL,
+    sneaker_id INTEGER NOT NULL,
+    article VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    size NUMERIC(4, 1) NOT NULL,
+    size_system VARCHAR(4) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    price NUMERIC(14, 4) NOT NULL,                                   -- price shown when the item was added
+    currency CHAR(3) NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chat_id, sneaker_id)
+);
@@ -0,0 +1,57 @@
+// Package migrate применяет схему PostgreSQL бота: диалоги и подписки.
+package migrate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	gomigrate "github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Миграции вшиваются в бинарник, чтобы не зависеть от рабочей директории
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+const migrationsDir = "migrations"
+
+// migrationsTable - своя таблица версий, чтобы бот мог жить в одной БД с inventory_service
+const migrationsTable = "tg_bot_schema_migrations"
+
+func RunMigrations(connString string) error {
+	src, err := iofs.New(migrationsFS, migrationsDir)
+	if err != nil {
+		return fmt.Errorf("failed to open migrations source: %w", err)
+	}
+
+	m, err := gomigrate.NewWithSourceInstance("iofs", src, databaseURL(connString))
+	if err != nil {
+		return fmt.Errorf("failed to init migrate: %w", err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, gomigrate.ErrNoChange) {
+		return fmt.Errorf("ошибка при выполнении миграций: %w", err)
+	}
+	return nil
+}
+
+// databaseURL переводит DSN postgres:// в схему драйвера pgx5:// со своей таблицей версий
+func databaseURL(connString string) string {
+	for _, scheme := range []string{"postgres://", "postgresql://"} {
+		if strings.HasPrefix(connString, scheme) {
+			connString = "pgx5://" + strings.TrimPrefix(connString, scheme)
+			break
+		}
+	}
+
+	sep := "?"
+	if strings.Contains(connString, "?") {
+		sep = "&"
+	}
+	return connString + sep + "x-migrations-table=" + migrationsTable
+}